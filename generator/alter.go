@@ -0,0 +1,495 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// sqlWord splits the leading word of s, an identifier, keyword or quoted
+// identifier, from the rest of s. Quoted identifiers are returned unquoted.
+func sqlWord(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", ""
+	}
+	if q := s[0]; q == '`' || q == '"' {
+		if i := strings.IndexByte(s[1:], q); i >= 0 {
+			return s[1 : i+1], s[i+2:]
+		}
+		return s[1:], ""
+	}
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '(' || r == ','
+	})
+	if i < 0 {
+		return s, ""
+	}
+	if i == 0 {
+		return s[:1], s[1:]
+	}
+	return s[:i], s[i:]
+}
+
+// sqlKeyword consumes the given keywords from the start of s, reporting
+// whether they were all there.
+func sqlKeyword(s string, keywords ...string) (string, bool) {
+	rest := s
+	for _, k := range keywords {
+		var w string
+		w, rest = sqlWord(rest)
+		if !strings.EqualFold(w, k) {
+			return s, false
+		}
+	}
+	return rest, true
+}
+
+// tableIdent strips the schema of a possibly qualified table name.
+func tableIdent(name string) string {
+	name = strings.Replace(name, "`", "", -1)
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// tableNameWord consumes a possibly schema qualified table name from the
+// start of s, returning the unqualified name.
+func tableNameWord(s string) (string, string) {
+	name, rest := sqlWord(s)
+	for strings.HasPrefix(strings.TrimSpace(rest), ".") {
+		name, rest = sqlWord(strings.TrimSpace(rest)[1:])
+	}
+	return tableIdent(name), rest
+}
+
+// splitTopLevel splits s on the commas outside of parentheses and quotes.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseColumnDef parses a single column definition, e.g. "age int NOT NULL".
+func parseColumnDef(def string) (*sqlparser.ColumnDefinition, error) {
+	if handParsed() {
+		tokens, err := pgTokens(def)
+		if err != nil {
+			return nil, err
+		}
+		col, err := parsePgColumn(tokens)
+		return col.def, err
+	}
+	def, boolName := rewriteBoolColumn(def)
+	// the unique key of a SERIAL column is not added along
+	def, autoID := rewriteAutoIDColumn(def)
+	def, generatedName := stripGeneratedColumn(def)
+	def = rewriteTimestampCalls(def)
+	stmt, err := sqlparser.Parse("CREATE TABLE t (" + def + ")")
+	if err == nil {
+		if ddl, ok := stmt.(*sqlparser.DDL); ok && ddl.TableSpec != nil && len(ddl.TableSpec.Columns) == 1 {
+			c := ddl.TableSpec.Columns[0]
+			if boolName != "" {
+				boolColumns[c] = true
+			}
+			if generatedName != "" {
+				generatedColumns[c] = true
+			}
+			if autoID.autoRandom != "" {
+				markAutoRandom(c, autoID.autoRandom)
+			}
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot parse column definition %q", strings.TrimSpace(def))
+}
+
+// parseIndexDef parses a single index definition, e.g. "UNIQUE KEY uk (a)".
+func parseIndexDef(def string) (*sqlparser.IndexDefinition, error) {
+	if handParsed() {
+		tokens, err := pgTokens(def)
+		if err != nil {
+			return nil, err
+		}
+		c, err := parsePgConstraint("", tokens)
+		if err == nil && c.index == nil {
+			err = fmt.Errorf("cannot parse index definition %q", strings.TrimSpace(def))
+		}
+		return c.index, err
+	}
+	rewritten, rw := rewriteIndexDef(def, 0)
+	stmt, err := sqlparser.Parse("CREATE TABLE t (dalgen_placeholder int, " + rewritten + ")")
+	if err == nil {
+		if ddl, ok := stmt.(*sqlparser.DDL); ok && ddl.TableSpec != nil && len(ddl.TableSpec.Indexes) == 1 {
+			markIndexes(ddl, []indexRewrite{rw})
+			return ddl.TableSpec.Indexes[0], nil
+		}
+	}
+	return nil, fmt.Errorf("cannot parse index definition %q", strings.TrimSpace(def))
+}
+
+func columnIndex(spec *sqlparser.TableSpec, name string) int {
+	for i, c := range spec.Columns {
+		if c.Name.EqualString(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// placeColumn inserts c into the columns of spec at the FIRST or AFTER
+// position of rest, or at the end.
+func placeColumn(spec *sqlparser.TableSpec, c *sqlparser.ColumnDefinition, rest string) error {
+	at := len(spec.Columns)
+	if _, ok := sqlKeyword(rest, "first"); ok {
+		at = 0
+	} else if r, ok := sqlKeyword(rest, "after"); ok {
+		after, _ := sqlWord(r)
+		i := columnIndex(spec, after)
+		if i < 0 {
+			return fmt.Errorf("unknown column %s", after)
+		}
+		at = i + 1
+	}
+	spec.Columns = append(spec.Columns, nil)
+	copy(spec.Columns[at+1:], spec.Columns[at:])
+	spec.Columns[at] = c
+	return nil
+}
+
+// splitColumnPosition splits a trailing FIRST or AFTER clause from a column
+// definition.
+func splitColumnPosition(def string) (string, string) {
+	lower := strings.ToLower(def)
+	for _, kw := range []string{" after ", " first"} {
+		if i := strings.LastIndex(lower, kw); i >= 0 && !strings.ContainsAny(def[i:], "'\"()") {
+			return def[:i], def[i:]
+		}
+	}
+	return def, ""
+}
+
+// renameIndexedColumn renames, or drops when to is empty, a column in the
+// indexes of spec. Indexes left without columns are dropped.
+func renameIndexedColumn(spec *sqlparser.TableSpec, from, to string) {
+	indexes := spec.Indexes[:0]
+	for _, idx := range spec.Indexes {
+		cols := idx.Columns[:0]
+		for _, ic := range idx.Columns {
+			if ic.Column.EqualString(from) {
+				if to == "" {
+					continue
+				}
+				ic.Column = sqlparser.NewColIdent(to)
+			}
+			cols = append(cols, ic)
+		}
+		idx.Columns = cols
+		if len(cols) > 0 {
+			indexes = append(indexes, idx)
+		}
+	}
+	spec.Indexes = indexes
+}
+
+// applyAlter applies one ALTER TABLE clause, e.g. "ADD COLUMN age int", to
+// the table. It returns the new table name for RENAME TO, "" otherwise.
+func applyAlter(ddl *sqlparser.DDL, clause string) (string, error) {
+	spec := ddl.TableSpec
+	word, rest := sqlWord(clause)
+	switch strings.ToLower(word) {
+	case "add":
+		if fk, ok, err := parseForeignKey(rest); ok {
+			if err != nil {
+				return "", err
+			}
+			tableForeignKeys[ddl] = append(tableForeignKeys[ddl], fk)
+			return "", nil
+		}
+		if r, ok := sqlKeyword(rest, "constraint"); ok {
+			// ADD CONSTRAINT [symbol] PRIMARY KEY|UNIQUE|FOREIGN KEY ...
+			rest = r
+			if w, r := sqlWord(r); !strings.EqualFold(w, "primary") && !strings.EqualFold(w, "unique") && !strings.EqualFold(w, "foreign") {
+				rest = r
+			}
+		}
+		w, _ := sqlWord(rest)
+		switch strings.ToLower(w) {
+		case "check":
+			return "", nil
+		case "primary", "unique", "index", "key", "fulltext", "spatial":
+			idx, err := parseIndexDef(rest)
+			if err != nil {
+				return "", err
+			}
+			spec.Indexes = append(spec.Indexes, idx)
+			return "", nil
+		}
+		if r, ok := sqlKeyword(rest, "column"); ok {
+			rest = r
+		}
+		rest, _ = sqlKeyword(rest, "if", "not", "exists")
+		def, pos := splitColumnPosition(rest)
+		c, err := parseColumnDef(def)
+		if err != nil {
+			return "", err
+		}
+		if columnIndex(spec, c.Name.String()) >= 0 {
+			return "", fmt.Errorf("duplicate column %s", c.Name.String())
+		}
+		return "", placeColumn(spec, c, pos)
+	case "drop":
+		w, r := sqlWord(rest)
+		switch strings.ToLower(w) {
+		case "primary":
+			for i, idx := range spec.Indexes {
+				if idx.Info.Primary {
+					spec.Indexes = append(spec.Indexes[:i], spec.Indexes[i+1:]...)
+					return "", nil
+				}
+			}
+			return "", fmt.Errorf("no primary key to drop")
+		case "index", "key":
+			name, _ := sqlWord(r)
+			for i, idx := range spec.Indexes {
+				if idx.Info.Name.EqualString(name) {
+					spec.Indexes = append(spec.Indexes[:i], spec.Indexes[i+1:]...)
+					return "", nil
+				}
+			}
+			return "", fmt.Errorf("unknown index %s", name)
+		case "foreign":
+			r, _ = sqlKeyword(r, "key")
+			name, _ := sqlWord(r)
+			fks := tableForeignKeys[ddl]
+			for i, fk := range fks {
+				if fk.Name == name {
+					tableForeignKeys[ddl] = append(fks[:i], fks[i+1:]...)
+					return "", nil
+				}
+			}
+			return "", fmt.Errorf("unknown foreign key %s", name)
+		case "check", "constraint":
+			return "", nil
+		case "column":
+			w, r = sqlWord(r)
+		}
+		// DROP [COLUMN] IF EXISTS x
+		ifExists := false
+		if strings.EqualFold(w, "if") {
+			if r, ok := sqlKeyword(r, "exists"); ok {
+				w, _ = sqlWord(r)
+				ifExists = true
+			}
+		}
+		i := columnIndex(spec, w)
+		if i < 0 {
+			if ifExists {
+				return "", nil
+			}
+			return "", fmt.Errorf("unknown column %s", w)
+		}
+		spec.Columns = append(spec.Columns[:i], spec.Columns[i+1:]...)
+		renameIndexedColumn(spec, w, "")
+		return "", nil
+	case "modify", "change":
+		if r, ok := sqlKeyword(rest, "column"); ok {
+			rest = r
+		}
+		old := ""
+		if strings.EqualFold(word, "change") {
+			old, rest = sqlWord(rest)
+		}
+		def, pos := splitColumnPosition(rest)
+		c, err := parseColumnDef(def)
+		if err != nil {
+			return "", err
+		}
+		if old == "" {
+			old = c.Name.String()
+		}
+		i := columnIndex(spec, old)
+		if i < 0 {
+			return "", fmt.Errorf("unknown column %s", old)
+		}
+		if pos == "" {
+			spec.Columns[i] = c
+		} else {
+			spec.Columns = append(spec.Columns[:i], spec.Columns[i+1:]...)
+			if err := placeColumn(spec, c, pos); err != nil {
+				return "", err
+			}
+		}
+		if !c.Name.EqualString(old) {
+			renameIndexedColumn(spec, old, c.Name.String())
+		}
+		return "", nil
+	case "rename":
+		if r, ok := sqlKeyword(rest, "column"); ok {
+			from, r := sqlWord(r)
+			r, _ = sqlKeyword(r, "to")
+			to, _ := sqlWord(r)
+			i := columnIndex(spec, from)
+			if i < 0 {
+				return "", fmt.Errorf("unknown column %s", from)
+			}
+			spec.Columns[i].Name = sqlparser.NewColIdent(to)
+			renameIndexedColumn(spec, from, to)
+			return "", nil
+		}
+		if r, ok := sqlKeyword(rest, "index"); ok {
+			rest = r
+		} else if r, ok := sqlKeyword(rest, "key"); ok {
+			rest = r
+		} else {
+			if r, ok := sqlKeyword(rest, "to"); ok {
+				rest = r
+			} else if r, ok := sqlKeyword(rest, "as"); ok {
+				rest = r
+			}
+			return tableIdent(strings.TrimSpace(rest)), nil
+		}
+		from, r := sqlWord(rest)
+		r, _ = sqlKeyword(r, "to")
+		to, _ := sqlWord(r)
+		for _, idx := range spec.Indexes {
+			if idx.Info.Name.EqualString(from) {
+				idx.Info.Name = sqlparser.NewColIdent(to)
+				return "", nil
+			}
+		}
+		return "", fmt.Errorf("unknown index %s", from)
+	case "alter":
+		if r, ok := sqlKeyword(rest, "column"); ok {
+			rest = r
+		}
+		name, r := sqlWord(rest)
+		i := columnIndex(spec, name)
+		if i < 0 {
+			return "", fmt.Errorf("unknown column %s", name)
+		}
+		c := spec.Columns[i]
+		if r, ok := sqlKeyword(r, "set", "data", "type"); ok {
+			rest = r
+		} else if r, ok := sqlKeyword(r, "type"); ok {
+			rest = r
+		} else {
+			if _, ok := sqlKeyword(r, "set", "not", "null"); ok {
+				c.Type.NotNull = true
+			} else if _, ok := sqlKeyword(r, "drop", "not", "null"); ok {
+				c.Type.NotNull = false
+			} else if r, ok := sqlKeyword(r, "set", "default"); ok && strings.HasPrefix(strings.ToLower(strings.TrimSpace(r)), "nextval") {
+				// how pg_dump attaches the sequence of a serial column
+				c.Type.Autoincrement = true
+			} else if _, ok := sqlKeyword(r, "add", "generated"); ok && strings.Contains(strings.ToLower(r), " identity") {
+				c.Type.Autoincrement = true
+				c.Type.NotNull = true
+			}
+			// other defaults do not change the generated model
+			return "", nil
+		}
+		// ALTER COLUMN c TYPE t [USING expr]
+		lower := strings.ToLower(rest)
+		if j := strings.Index(lower, " using "); j >= 0 {
+			rest = rest[:j]
+		}
+		typed, err := parseColumnDef("`" + name + "` " + rest)
+		if err != nil {
+			return "", err
+		}
+		typed.Type.NotNull = c.Type.NotNull
+		typed.Type.Default = c.Type.Default
+		typed.Type.Comment = c.Type.Comment
+		c.Type = typed.Type
+		return "", nil
+	}
+	// table options and the like do not change the generated model
+	return "", nil
+}
+
+// isAlterTable reports whether stmt is an ALTER [ONLINE] [IGNORE] TABLE
+// statement.
+func isAlterTable(stmt string) bool {
+	rest, ok := sqlKeyword(stmt, "alter")
+	if !ok {
+		return false
+	}
+	for _, kw := range []string{"online", "ignore"} {
+		rest, _ = sqlKeyword(rest, kw)
+	}
+	_, ok = sqlKeyword(rest, "table")
+	return ok
+}
+
+// applyAlterTable applies an ALTER TABLE statement onto the CREATE TABLE
+// statements parsed so far. Clauses that cannot be applied are reported as
+// warnings, or as an error under -strict.
+func applyAlterTable(ddls []*sqlparser.DDL, stmt string) error {
+	rest, _ := sqlKeyword(stmt, "alter")
+	for _, kw := range []string{"online", "ignore"} {
+		rest, _ = sqlKeyword(rest, kw)
+	}
+	rest, _ = sqlKeyword(rest, "table")
+	rest, _ = sqlKeyword(rest, "if", "exists")
+	rest, _ = sqlKeyword(rest, "only")
+	name, rest := tableNameWord(rest)
+	var ddl *sqlparser.DDL
+	for _, d := range ddls {
+		if d.NewName.Name.String() == name {
+			ddl = d
+		}
+	}
+	if ddl == nil {
+		return alterConflict(fmt.Errorf("alter table %s: no such table", name))
+	}
+	for _, clause := range splitTopLevel(rest) {
+		if strings.TrimSpace(clause) == "" {
+			continue
+		}
+		renamed, err := applyAlter(ddl, clause)
+		if err != nil {
+			if err := alterConflict(fmt.Errorf("alter table %s: %v", name, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		if renamed != "" {
+			if err := renameTable(ddls, ddl, renamed); err != nil {
+				return fmt.Errorf("alter table %s: %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func alterConflict(err error) error {
+	if strict {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	return nil
+}
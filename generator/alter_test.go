@@ -0,0 +1,37 @@
+package generator
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestAlterFixture(t *testing.T) {
+	schema, err := ioutil.ReadFile("testdata/alter.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []string{"mysql", "postgres"} {
+		t.Run(d, func(t *testing.T) {
+			sql := string(schema)
+			if d == "postgres" {
+				sql = strings.NewReplacer("`", `"`, " AUTO_INCREMENT", "", " AFTER \"name\"", "", "MODIFY COLUMN \"name\"", "ALTER COLUMN \"name\" TYPE", " NOT NULL;", ";").Replace(sql)
+			}
+			got := generate(t, sql, "users", "-dialect", d, "-strict")
+			start := strings.Index(got, "type Users struct {")
+			end := strings.Index(got[start:], "}")
+			var fields []string
+			for _, line := range strings.Split(got[start:start+end], "\n")[1:] {
+				if f := strings.Fields(line); len(f) > 0 {
+					fields = append(fields, f[0])
+				}
+			}
+			if want := "ID Name Email"; strings.Join(fields, " ") != want {
+				t.Errorf("got fields %s, want %s:\n%s", fields, want, got)
+			}
+			if !strings.Contains(got, "size:128") {
+				t.Errorf("name was not modified to varchar(128):\n%s", got)
+			}
+		})
+	}
+}
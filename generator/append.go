@@ -0,0 +1,246 @@
+package generator
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// declNames returns the top-level names a declaration defines. Methods are
+// named after their receiver type, e.g. Users.TableName.
+func declNames(d ast.Decl) []string {
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil || len(d.Recv.List) == 0 {
+			return []string{d.Name.Name}
+		}
+		return []string{receiverType(d) + "." + d.Name.Name}
+	case *ast.GenDecl:
+		var names []string
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, n := range s.Names {
+					names = append(names, n.Name)
+				}
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func receiverType(d *ast.FuncDecl) string {
+	t := d.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+func isImportDecl(d ast.Decl) bool {
+	g, ok := d.(*ast.GenDecl)
+	return ok && g.Tok == token.IMPORT
+}
+
+// declSource returns the source of a declaration including its doc comment.
+func declSource(fset *token.FileSet, src []byte, d ast.Decl) string {
+	start := d.Pos()
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		if d.Doc != nil {
+			start = d.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if d.Doc != nil {
+			start = d.Doc.Pos()
+		}
+	}
+	return string(src[fset.Position(start).Offset:fset.Position(d.End()).Offset])
+}
+
+// usedPackages returns the names qualified identifiers refer to in decls,
+// e.g. time for time.Time.
+func usedPackages(decls []ast.Decl) map[string]bool {
+	used := make(map[string]bool)
+	for _, d := range decls {
+		ast.Inspect(d, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if id, ok := sel.X.(*ast.Ident); ok {
+					used[id.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	return used
+}
+
+// importName returns the name an import is referred to by: its alias, or
+// the package name the go tool assumes from the path, e.g. redis for
+// github.com/go-redis/redis/v8 and yaml for gopkg.in/yaml.v3.
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	p, _ := strconv.Unquote(spec.Path.Value)
+	name := path.Base(p)
+	if major := strings.TrimPrefix(name, "v"); major != name && path.Dir(p) != "." {
+		if _, err := strconv.Atoi(major); err == nil {
+			name = path.Base(path.Dir(p))
+		}
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// importLine is an import of a file, with its alias if any.
+type importLine struct {
+	name, path string
+}
+
+func (l importLine) String() string {
+	if l.name == "" {
+		return strconv.Quote(l.path)
+	}
+	return l.name + " " + strconv.Quote(l.path)
+}
+
+// renderImportSpecs renders the import declaration of specs as
+// renderImports does, keeping their aliases. An import given twice is
+// rendered once.
+func renderImportSpecs(specs []*ast.ImportSpec) string {
+	seen := make(map[importLine]bool)
+	var std, other []importLine
+	for _, spec := range specs {
+		l := importLine{}
+		l.path, _ = strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			l.name = spec.Name.Name
+		}
+		if seen[l] {
+			continue
+		}
+		seen[l] = true
+		if standardImport(l.path) {
+			std = append(std, l)
+		} else {
+			other = append(other, l)
+		}
+	}
+	for _, lines := range [][]importLine{std, other} {
+		sort.Slice(lines, func(i, j int) bool { return lines[i].path < lines[j].path })
+	}
+	switch len(std) + len(other) {
+	case 0:
+		return ""
+	case 1:
+		return "import " + append(std, other...)[0].String() + "\n"
+	}
+	var b strings.Builder
+	b.WriteString("import (\n")
+	for _, l := range std {
+		b.WriteString("\t" + l.String() + "\n")
+	}
+	if len(std) > 0 && len(other) > 0 {
+		b.WriteString("\n")
+	}
+	for _, l := range other {
+		b.WriteString("\t" + l.String() + "\n")
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// appendSingleFile merges the generated single file content into the
+// existing file fp for -append: declarations of content replace the
+// existing ones with the same names, along with the methods of replaced
+// types, and every other declaration is kept.
+func appendSingleFile(fp string, content string) (string, error) {
+	old, err := ioutil.ReadFile(fp)
+	if os.IsNotExist(err) {
+		return content, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	fset := token.NewFileSet()
+	oldFile, err := parser.ParseFile(fset, fp, old, parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	newFile, err := parser.ParseFile(fset, "", content, parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+
+	replaced := make(map[string]bool)
+	for _, d := range newFile.Decls {
+		for _, name := range declNames(d) {
+			replaced[name] = true
+		}
+	}
+	var kept []ast.Decl
+	for _, d := range oldFile.Decls {
+		if isImportDecl(d) {
+			continue
+		}
+		keep := true
+		for _, name := range declNames(d) {
+			// methods go along with their type
+			if i := strings.IndexByte(name, '.'); i >= 0 && replaced[name[:i]] {
+				keep = false
+			}
+			if replaced[name] {
+				keep = false
+			}
+		}
+		if keep {
+			kept = append(kept, d)
+		}
+	}
+
+	imports := newFile.Imports
+	used := usedPackages(kept)
+	for _, spec := range oldFile.Imports {
+		// blank and dot imports cannot be told unused
+		if name := importName(spec); name == "_" || name == "." || used[name] {
+			imports = append(imports, spec)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("package " + newFile.Name.Name + "\n\n")
+	b.WriteString(renderImportSpecs(imports))
+	for _, d := range kept {
+		b.WriteString("\n")
+		b.WriteString(declSource(fset, old, d))
+		b.WriteString("\n")
+	}
+	for _, d := range newFile.Decls {
+		if isImportDecl(d) {
+			continue
+		}
+		b.WriteString("\n")
+		b.WriteString(declSource(fset, []byte(content), d))
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,36 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const appendSchema = `CREATE TABLE posts (
+  id bigint NOT NULL AUTO_INCREMENT,
+  title varchar(255) NOT NULL,
+  body text NOT NULL COMMENT '正文',
+  PRIMARY KEY (id)
+);
+CREATE TABLE comments (
+  id bigint NOT NULL AUTO_INCREMENT,
+  post_id bigint NOT NULL,
+  PRIMARY KEY (id)
+) COMMENT='用户评论表\n每条评论属于一篇文章';`
+
+// TestAppendGolden appends the posts and comments tables to
+// testdata/append/models.go, whose hand-written code uses an aliased, a
+// versioned and a gopkg.in import and carries multi-line and CJK comments,
+// and compares the result with testdata/append/models.go.golden, which go
+// test -update rewrites. The result must build.
+func TestAppendGolden(t *testing.T) {
+	existing := readFile(t, testdataPath(t, "append/models.go"))
+	golden := testdataPath(t, "append/models.go.golden")
+	chdir(t)
+	writeFile(t, "model/models.go", existing)
+	writeFile(t, "schema.sql", appendSchema)
+	if err := run("-single-file", "models.go", "-append", "-no-column-maps", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, golden, gofmt(t, readFile(t, filepath.Join("model", "models.go"))))
+	goTest(t, "./model")
+}
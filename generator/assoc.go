@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// association is a gorm association field of a model, or a comment in its
+// place when the referenced model is not generated into the same package.
+type association struct {
+	Field   string
+	Type    string
+	JSON    string
+	Tag     string
+	Comment string
+}
+
+func (a association) String() string {
+	if a.Comment != "" {
+		return "// " + a.Comment
+	}
+	return fmt.Sprintf("%s %s `gorm:\"%s\" json:\"%s,omitempty\"`", a.Field, a.Type, a.Tag, jsonName(a.JSON))
+}
+
+// tableAssociations holds the -associations fields of every table.
+var tableAssociations = make(map[*sqlparser.DDL][]association)
+
+func fieldNames(ddl *sqlparser.DDL, columns []string) string {
+	fields := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if col := findColumn(ddl, c); col != nil {
+			fields = append(fields, fieldName(col))
+			continue
+		}
+		fields = append(fields, exportedIdent(ToCamelFirstUpper(c)))
+	}
+	return strings.Join(fields, ",")
+}
+
+// applyAssociations derives from the foreign keys a belongs-to field on the
+// child model and a has-many field on the parent model of every foreign key
+// whose tables are generated into the same package.
+func applyAssociations(ddls []*sqlparser.DDL) {
+	byName := make(map[string]*sqlparser.DDL)
+	used := make(map[*sqlparser.DDL]map[string]bool)
+	for _, ddl := range ddls {
+		byName[ddl.NewName.Name.String()] = ddl
+		names := make(map[string]bool)
+		for _, m := range modelMethods {
+			names[m] = true
+		}
+		for _, c := range ddl.TableSpec.Columns {
+			names[fieldName(c)] = true
+		}
+		used[ddl] = names
+	}
+	free := func(ddl *sqlparser.DDL, name string) string {
+		for used[ddl][name] {
+			name += "Ref"
+		}
+		used[ddl][name] = true
+		return name
+	}
+
+	for _, child := range ddls {
+		childName := child.NewName.Name.String()
+		for _, fk := range tableForeignKeys[child] {
+			ref := fk.RefTable + "(" + strings.Join(fk.RefColumns, ", ") + ")"
+			if fk.RefSchema != "" {
+				ref = fk.RefSchema + "." + ref
+			}
+			cols := strings.Join(fk.Columns, ", ")
+			parent := byName[fk.RefTable]
+			schema := child.NewName.Qualifier.String()
+			crossSchema := fk.RefSchema != "" && fk.RefSchema != schema && fk.RefSchema != databaseName
+			switch {
+			case crossSchema || tableGroup(childName) != tableGroup(fk.RefTable):
+				tableAssociations[child] = append(tableAssociations[child], association{
+					Comment: cols + " references " + ref + ", which is generated into another package",
+				})
+				continue
+			case parent == nil:
+				tableAssociations[child] = append(tableAssociations[child], association{
+					Comment: cols + " references " + ref + ", which is not generated",
+				})
+				continue
+			}
+
+			tag := "foreignKey:" + fieldNames(child, fk.Columns) + ";references:" + fieldNames(parent, fk.RefColumns)
+			parentType := modelName(fk.RefTable)
+			childType := modelName(childName)
+
+			belongsJSON := fk.RefTable
+			if len(fk.Columns) == 1 && strings.HasSuffix(strings.ToLower(fk.Columns[0]), "_id") {
+				belongsJSON = fk.Columns[0][:len(fk.Columns[0])-len("_id")]
+			}
+			belongs := free(child, exportedIdent(ToCamelFirstUpper(belongsJSON)))
+			tableAssociations[child] = append(tableAssociations[child], association{
+				Field: belongs,
+				Type:  "*" + parentType,
+				JSON:  belongsJSON,
+				Tag:   tag,
+			})
+
+			// the has-many field keeps the plural of the table name
+			hasMany, hasManyJSON := exportedIdent(ToCamelFirstUpper(trimTablePrefix(childName))), childName
+			if parent == child {
+				// a self reference; the pointer and slice fields keep the
+				// type finite
+				hasMany, hasManyJSON = "Children", "children"
+			}
+			if used[parent][hasMany] {
+				hasMany, hasManyJSON = hasMany+"By"+belongs, hasManyJSON+"_by_"+belongsJSON
+			}
+			tableAssociations[parent] = append(tableAssociations[parent], association{
+				Field: free(parent, hasMany),
+				Type:  "[]" + childType,
+				JSON:  hasManyJSON,
+				Tag:   tag,
+			})
+		}
+	}
+}
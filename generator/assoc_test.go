@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const assocSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  name varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE TABLE orders (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_id bigint NOT NULL,
+  state varchar(16) NOT NULL,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE TABLE categories (
+  id bigint NOT NULL AUTO_INCREMENT,
+  parent_id bigint,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories (id)
+);`
+
+const assocCloneTest = `package model
+
+import "testing"
+
+func TestCloneAssociations(t *testing.T) {
+	u := &Users{ID: 1, Name: "ann", Orders: []Orders{{ID: 10, UserID: 1, State: "new"}}}
+	o := &Orders{ID: 10, UserID: 1, State: "new", User: &Users{ID: 1, Name: "ann"}}
+
+	uc, oc := u.Clone(), o.Clone()
+	if !uc.Equal(*u) || !oc.Equal(*o) {
+		t.Fatal("a clone is not equal to its original")
+	}
+	uc.Orders[0].State = "paid"
+	oc.User.Name = "bob"
+	if u.Orders[0].State != "new" || o.User.Name != "ann" {
+		t.Error("the clone shares its associations with the original")
+	}
+	if uc.Equal(*u) || oc.Equal(*o) {
+		t.Error("Equal ignores the associations")
+	}
+
+	// the self reference is cloned down the tree
+	c := &Categories{ID: 1, Children: []Categories{{ID: 2, Children: []Categories{{ID: 3}}}}}
+	cc := c.Clone()
+	cc.Children[0].Children[0].ID = 4
+	if c.Children[0].Children[0].ID != 3 {
+		t.Error("the clone shares the grandchildren with the original")
+	}
+
+	if (Users{Orders: []Orders{{}}}).IsZero() || (Orders{User: &Users{}}).IsZero() {
+		t.Error("IsZero ignores the associations")
+	}
+	if !(Users{}).IsZero() {
+		t.Error("the zero Users is not zero")
+	}
+}
+`
+
+func TestAssociationsClone(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", assocSchema)
+	if err := run("-associations", "-gen-clone", "-gen-compare", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	clone := gofmt(t, readFile(t, "model/orders_clone.go"))
+	if !strings.Contains(clone, "c.User = m.User.Clone()") {
+		t.Errorf("the belongs-to association is not cloned:\n%s", clone)
+	}
+	writeFile(t, "model/clone_test.go", assocCloneTest)
+	goTest(t, "./model")
+}
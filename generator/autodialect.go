@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// dialectSignal is a trait of the DDL of one dialect -dialect auto looks
+// for.
+type dialectSignal struct {
+	dialect string
+	reason  string
+	re      *regexp.Regexp
+}
+
+var dialectSignals = []dialectSignal{
+	{"mysql", "backtick quoted identifiers", regexp.MustCompile("`[^`\n]+`")},
+	{"mysql", "ENGINE table option", regexp.MustCompile(`(?i)\)\s*engine\s*=`)},
+	{"mysql", "AUTO_INCREMENT or AUTO_RANDOM", regexp.MustCompile(`(?i)\bauto_(increment|random)\b`)},
+	{"mysql", "UNSIGNED integers", regexp.MustCompile(`(?i)\bint\w*(\s*\(\s*\d+\s*\))?\s+unsigned\b`)},
+	{"mysql", "CHARSET table option", regexp.MustCompile(`(?i)\b(default\s+)?charset\s*=`)},
+	{"mysql", "/*! conditional comments", regexp.MustCompile(`/\*!\d*`)},
+	{"postgres", "double-quoted identifiers", regexp.MustCompile(`(?i)(\b(table|view|on|references|index)\s+|[(,]\s*)"[^"\n]+"`)},
+	{"postgres", ":: casts", regexp.MustCompile(`::\s*"?[a-zA-Z_]`)},
+	{"postgres", "SERIAL types", regexp.MustCompile(`(?i)\b(small|big)?serial[248]?\b(\s+primary\b|\s*,|\s*\)|\s+not\b)`)},
+	{"postgres", "CREATE TYPE, SEQUENCE or EXTENSION", regexp.MustCompile(`(?i)\bcreate\s+(type|sequence|extension|schema)\b`)},
+	{"postgres", "COMMENT ON statements", regexp.MustCompile(`(?i)\bcomment\s+on\s+(table|column)\b`)},
+	{"postgres", "Postgres types", regexp.MustCompile(`(?i)\b(jsonb|bytea|timestamptz|uuid|inet|cidr|macaddr|interval)\b|\bwith(out)?\s+time\s+zone\b|\bcharacter\s+varying\b`)},
+	{"postgres", "IDENTITY columns", regexp.MustCompile(`(?i)\bgenerated\s+(always|by\s+default)\s+as\s+identity\b`)},
+	{"postgres", "public schema tables", regexp.MustCompile(`(?i)\btable\s+(if\s+not\s+exists\s+)?(public|"public")\.`)},
+	{"postgres", "pg_dump settings", regexp.MustCompile(`(?i)\bset\s+search_path\b|\bpg_catalog\.`)},
+	{"sqlite", "AUTOINCREMENT", regexp.MustCompile(`(?i)\bautoincrement\b`)},
+	{"sqlite", "WITHOUT ROWID tables", regexp.MustCompile(`(?i)\bwithout\s+rowid\b`)},
+	{"sqlite", "PRAGMA statements", regexp.MustCompile(`(?im)^\s*pragma\b`)},
+}
+
+// sniffDialect guesses the dialect of an input file for -dialect auto from
+// the traits of its DDL, outside of its strings and comments; the dialect
+// with the most of them wins. When none is found, or dialects tie, the
+// file is read as MySQL, with a warning. -verbose reports every choice
+// along with the traits it was made on.
+func sniffDialect(file string, content []byte) string {
+	text := sniffableText(string(content))
+	reasons := make(map[string][]string)
+	for _, s := range dialectSignals {
+		if s.re.MatchString(text) {
+			reasons[s.dialect] = append(reasons[s.dialect], s.reason)
+		}
+	}
+	best, tie := "", false
+	for _, d := range []string{"mysql", "postgres", "sqlite"} {
+		switch {
+		case len(reasons[d]) == 0:
+		case best == "" || len(reasons[d]) > len(reasons[best]):
+			best, tie = d, false
+		case len(reasons[d]) == len(reasons[best]):
+			tie = true
+		}
+	}
+	if best == "" || tie {
+		var found []string
+		for _, d := range []string{"mysql", "postgres", "sqlite"} {
+			if len(reasons[d]) > 0 {
+				found = append(found, d+" ("+strings.Join(reasons[d], ", ")+")")
+			}
+		}
+		if len(found) == 0 {
+			fmt.Fprintf(os.Stderr, "warning: -dialect auto: %s has no trait of a dialect, reading it as mysql\n", file)
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: -dialect auto: %s looks like %s, reading it as mysql\n", file, strings.Join(found, " as much as "))
+		}
+		return "mysql"
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "-dialect auto: %s is %s: %s\n", file, best, strings.Join(reasons[best], ", "))
+	}
+	return best
+}
+
+// sniffableText blanks the single-quoted strings, which may well hold
+// anything, and the -- and /* */ comments of content, but for the MySQL
+// /*! ones.
+func sniffableText(content string) string {
+	var b strings.Builder
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\'':
+			j := i + 1
+			for ; j < len(content); j++ {
+				if content[j] == '\\' {
+					j++
+				} else if content[j] == '\'' {
+					if j+1 < len(content) && content[j+1] == '\'' {
+						j++
+						continue
+					}
+					break
+				}
+			}
+			b.WriteString("''")
+			i = j
+		case strings.HasPrefix(content[i:], "--"):
+			j := strings.IndexByte(content[i:], '\n')
+			if j < 0 {
+				return b.String()
+			}
+			i += j - 1
+		case strings.HasPrefix(content[i:], "/*") && !strings.HasPrefix(content[i:], "/*!"):
+			j := strings.Index(content[i+2:], "*/")
+			if j < 0 {
+				return b.String()
+			}
+			b.WriteByte(' ')
+			i += j + 3
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package generator
+
+import "testing"
+
+func TestSniffDialect(t *testing.T) {
+	tested := make(map[string]bool)
+	for _, tt := range []struct {
+		want, reason, ddl string
+	}{
+		{"mysql", "backtick quoted identifiers", "CREATE TABLE `users` (`id` bigint NOT NULL);"},
+		{"mysql", "ENGINE table option", "CREATE TABLE users (id bigint NOT NULL) ENGINE=InnoDB;"},
+		{"mysql", "AUTO_INCREMENT or AUTO_RANDOM", "CREATE TABLE users (id bigint NOT NULL AUTO_INCREMENT);"},
+		{"mysql", "UNSIGNED integers", "CREATE TABLE users (id int(10) unsigned NOT NULL);"},
+		{"mysql", "CHARSET table option", "CREATE TABLE users (id bigint NOT NULL) DEFAULT CHARSET=utf8mb4;"},
+		{"mysql", "/*! conditional comments", "/*!40101 SET NAMES utf8 */;\nCREATE TABLE users (id bigint NOT NULL);"},
+		{"postgres", "double-quoted identifiers", `CREATE TABLE "users" ("id" bigint NOT NULL);`},
+		{"postgres", ":: casts", "CREATE TABLE users (role text DEFAULT 'member'::text);"},
+		{"postgres", "SERIAL types", "CREATE TABLE users (id bigserial PRIMARY KEY);"},
+		{"postgres", "CREATE TYPE, SEQUENCE or EXTENSION", "CREATE TYPE mood AS ENUM ('sad', 'happy');"},
+		{"postgres", "COMMENT ON statements", "CREATE TABLE users (id bigint);\nCOMMENT ON TABLE users IS 'people';"},
+		{"postgres", "Postgres types", "CREATE TABLE users (id bigint, created_at timestamp with time zone);"},
+		{"postgres", "IDENTITY columns", "CREATE TABLE users (id bigint GENERATED ALWAYS AS IDENTITY);"},
+		{"postgres", "public schema tables", "CREATE TABLE public.users (id bigint);"},
+		{"postgres", "pg_dump settings", "SET search_path = public;\nCREATE TABLE users (id bigint);"},
+		{"sqlite", "AUTOINCREMENT", "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT);"},
+		{"sqlite", "WITHOUT ROWID tables", "CREATE TABLE users (id INTEGER PRIMARY KEY) WITHOUT ROWID;"},
+		{"sqlite", "PRAGMA statements", "PRAGMA foreign_keys=OFF;\nCREATE TABLE users (id INTEGER PRIMARY KEY);"},
+	} {
+		tested[tt.reason] = true
+		matched := false
+		for _, s := range dialectSignals {
+			if s.reason == tt.reason {
+				matched = s.dialect == tt.want && s.re.MatchString(sniffableText(tt.ddl))
+			}
+		}
+		if !matched {
+			t.Errorf("%s: %q does not match the %s signal", tt.reason, tt.ddl, tt.want)
+		}
+		if got := sniffDialect("schema.sql", []byte(tt.ddl)); got != tt.want {
+			t.Errorf("%s: got %s for %q, want %s", tt.reason, got, tt.ddl, tt.want)
+		}
+	}
+	for _, s := range dialectSignals {
+		if !tested[s.reason] {
+			t.Errorf("no snippet for the %s signal %s", s.dialect, s.reason)
+		}
+	}
+}
+
+func TestSniffDialectIgnored(t *testing.T) {
+	for _, tt := range []struct {
+		name, ddl string
+	}{
+		// traits in strings and comments do not count
+		{"string", "CREATE TABLE users (note text DEFAULT 'ENGINE=InnoDB');"},
+		{"line comment", "-- PRAGMA foreign_keys=OFF;\nCREATE TABLE users (id bigint);"},
+		{"block comment", "/* CREATE TABLE public.users */\nCREATE TABLE users (id bigint);"},
+		// a tie is read as MySQL
+		{"tie", "CREATE TABLE users (id bigint AUTO_INCREMENT, created_at timestamptz);"},
+	} {
+		if got := sniffDialect("schema.sql", []byte(tt.ddl)); got != "mysql" {
+			t.Errorf("%s: got %s for %q, want mysql", tt.name, got, tt.ddl)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// autoRandomColumns holds the TiDB AUTO_RANDOM columns and their clause,
+// e.g. AUTO_RANDOM(5). The sql parser knows neither it nor MySQL's SERIAL,
+// which are rewritten away: both are AUTO_INCREMENT columns to gorm, which
+// reads the generated key back from LAST_INSERT_ID either way.
+var autoRandomColumns = make(map[*sqlparser.ColumnDefinition]string)
+
+var (
+	// autoRandom matches an AUTO_RANDOM clause, with or without its shard
+	// and range bits.
+	autoRandom = regexp.MustCompile(`(?i)^auto_random\b\s*\(?`)
+	// serialDefaultValue matches the SERIAL DEFAULT VALUE attribute of an
+	// integer column, NOT NULL AUTO_INCREMENT UNIQUE.
+	serialDefaultValue = regexp.MustCompile(`(?i)^serial\s+default\s+value\b`)
+	primaryKeyClause   = regexp.MustCompile(`(?i)^primary\s+key\b`)
+)
+
+// autoIDColumn is a column rewritten by rewriteAutoIDColumn.
+type autoIDColumn struct {
+	name string
+	// autoRandom is the AUTO_RANDOM clause, "" for a SERIAL column.
+	autoRandom string
+	// unique is set for a SERIAL column that is not the primary key,
+	// unique as well.
+	unique bool
+}
+
+// rewriteAutoIDColumn rewrites the AUTO_RANDOM clause, the SERIAL type and
+// the SERIAL DEFAULT VALUE attribute of a column definition into what the
+// sql parser understands, returning the column, with an empty name when def
+// has none of them.
+func rewriteAutoIDColumn(def string) (string, autoIDColumn) {
+	name, rest := sqlWord(def)
+	if start, open := findClause(def, autoRandom); start >= 0 {
+		end := open
+		if def[open] == '(' {
+			if end = closingParen(def, open); end < 0 {
+				return def, autoIDColumn{}
+			}
+		}
+		clause := strings.Join(strings.Fields(def[start:end+1]), "")
+		clause = "AUTO_RANDOM" + clause[len("auto_random"):]
+		return def[:start] + def[end+1:], autoIDColumn{name: name, autoRandom: clause}
+	}
+	unique := func(def string) bool {
+		start, _ := findClause(def, primaryKeyClause)
+		return start < 0
+	}
+	if typ, after := sqlWord(rest); strings.EqualFold(typ, "serial") {
+		def = def[:len(def)-len(rest)] + " bigint unsigned NOT NULL AUTO_INCREMENT" + after
+		return def, autoIDColumn{name: name, unique: unique(after)}
+	}
+	if start, _ := findClause(def, serialDefaultValue); start >= 0 {
+		m := serialDefaultValue.FindString(def[start:])
+		def = def[:start] + "NOT NULL AUTO_INCREMENT" + def[start+len(m):]
+		return def, autoIDColumn{name: name, unique: unique(def)}
+	}
+	return def, autoIDColumn{}
+}
+
+// rewriteAutoIDColumns rewrites the AUTO_RANDOM and SERIAL columns of a
+// CREATE TABLE statement, adding the unique keys SERIAL implies.
+func rewriteAutoIDColumns(stmt string) (string, []autoIDColumn) {
+	open, end, ok := createTableBody(stmt)
+	if !ok {
+		return stmt, nil
+	}
+	defs := splitTopLevel(stmt[open+1 : end])
+	var columns []autoIDColumn
+	for i, def := range defs {
+		if d, col := rewriteAutoIDColumn(def); col.name != "" {
+			defs[i] = d
+			columns = append(columns, col)
+		}
+	}
+	if len(columns) == 0 {
+		return stmt, nil
+	}
+	for _, col := range columns {
+		if col.unique {
+			defs = append(defs, "UNIQUE KEY "+quoteIdent(col.name)+" ("+quoteIdent(col.name)+")")
+		}
+	}
+	return stmt[:open+1] + strings.Join(defs, ",") + stmt[end:], columns
+}
+
+// markAutoIDColumns flags the AUTO_RANDOM columns of ddl as AUTO_INCREMENT
+// and records them in autoRandomColumns.
+func markAutoIDColumns(ddl *sqlparser.DDL, columns []autoIDColumn) {
+	for _, col := range columns {
+		if c := findColumn(ddl, col.name); c != nil && col.autoRandom != "" {
+			markAutoRandom(c, col.autoRandom)
+		}
+	}
+}
+
+func markAutoRandom(c *sqlparser.ColumnDefinition, clause string) {
+	c.Type.Autoincrement = true
+	autoRandomColumns[c] = clause
+}
+
+// autoRandomDBType is the gorm type of an AUTO_RANDOM column, e.g. bigint
+// AUTO_RANDOM(5), for AutoMigrate to create it as such rather than
+// AUTO_INCREMENT; "" for the other columns.
+func autoRandomDBType(c *sqlparser.ColumnDefinition) string {
+	clause, ok := autoRandomColumns[c]
+	if !ok {
+		return ""
+	}
+	t := c.Type.Type
+	if c.Type.Unsigned {
+		t += " unsigned"
+	}
+	return t + " " + clause
+}
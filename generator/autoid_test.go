@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const autoIDSchema = "CREATE TABLE `events` (\n" +
+	"  `id` bigint NOT NULL AUTO_RANDOM(5),\n" +
+	"  `name` varchar(64) NOT NULL,\n" +
+	"  PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */\n" +
+	");\n" +
+	"CREATE TABLE `logs` (\n" +
+	"  `id` bigint unsigned NOT NULL /*T![auto_rand] AUTO_RANDOM(6, 54) */,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");\n" +
+	"CREATE TABLE `tickets` (\n" +
+	"  `id` SERIAL PRIMARY KEY,\n" +
+	"  `seq` int SERIAL DEFAULT VALUE,\n" +
+	"  `title` text NOT NULL\n" +
+	");"
+
+func TestAutoRandom(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", autoIDSchema)
+	if err := run("-strict", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	for table, want := range map[string]string{
+		"events":  "ID   int64  `gorm:\"Column:id;type:bigint AUTO_RANDOM(5);primaryKey;autoIncrement\" json:\"id\"`",
+		"logs":    "ID int64 `gorm:\"Column:id;type:bigint unsigned AUTO_RANDOM(6,54);primaryKey;autoIncrement\" json:\"id\"`",
+		"tickets": "ID    int64  `gorm:\"Column:id;primaryKey;autoIncrement\" json:\"id\"`",
+	} {
+		if m := gofmt(t, readFile(t, modelPath(table))); !strings.Contains(m, want) {
+			t.Errorf("no %s in:\n%s", want, m)
+		}
+	}
+	// SERIAL DEFAULT VALUE is NOT NULL AUTO_INCREMENT UNIQUE
+	if m := gofmt(t, readFile(t, modelPath("tickets"))); !strings.Contains(m, "Seq   int    `gorm:\"Column:seq;autoIncrement;unique\" json:\"seq\"`") {
+		t.Errorf("seq is not a unique auto-increment column:\n%s", m)
+	}
+	goTest(t, "./model")
+}
+
+func TestRewriteAutoIDColumn(t *testing.T) {
+	for _, tt := range []struct {
+		def, want, autoRandom string
+	}{
+		{"`id` bigint NOT NULL AUTO_RANDOM", "`id` bigint NOT NULL ", "AUTO_RANDOM"},
+		{"`id` bigint auto_random ( 5 , 54 ) NOT NULL", "`id` bigint  NOT NULL", "AUTO_RANDOM(5,54)"},
+		// a column named after it is left alone
+		{"`auto_random` int NOT NULL", "`auto_random` int NOT NULL", ""},
+	} {
+		got, col := rewriteAutoIDColumn(tt.def)
+		if got != tt.want || col.autoRandom != tt.autoRandom {
+			t.Errorf("%s: got %q, %q, want %q, %q", tt.def, got, col.autoRandom, tt.want, tt.autoRandom)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+var (
+	// timestampCall matches the start of a CURRENT_TIMESTAMP(6) or NOW()
+	// call, which the sql parser only knows as a bare CURRENT_TIMESTAMP.
+	timestampCall = regexp.MustCompile(`(?i)^(current_timestamp|localtimestamp|localtime|now)\s*\(`)
+	// currentTimestamp matches the defaults naming the current time, in
+	// the MySQL and Postgres spellings.
+	currentTimestamp = regexp.MustCompile(`(?i)^(current_timestamp|localtimestamp|localtime|now)(\s*\(\s*\d*\s*\))?$`)
+)
+
+// rewriteTimestampCalls rewrites the CURRENT_TIMESTAMP(6), NOW() and
+// LOCALTIMESTAMP() calls of a statement or column definition to a bare
+// CURRENT_TIMESTAMP; the precision is that of the column type anyway.
+func rewriteTimestampCalls(def string) string {
+	for {
+		start, open := findClause(def, timestampCall)
+		if start < 0 {
+			return def
+		}
+		end := closingParen(def, open)
+		if end < 0 {
+			return def
+		}
+		def = def[:start] + "current_timestamp" + def[end+1:]
+	}
+}
+
+func isCurrentTimestamp(v *sqlparser.SQLVal) bool {
+	return v != nil && v.Type != sqlparser.StrVal && currentTimestamp.MatchString(strings.TrimSpace(string(v.Val)))
+}
+
+// autoTime reports whether the gorm autoCreateTime or autoUpdateTime tag
+// applies to a time column: autoUpdateTime for ON UPDATE CURRENT_TIMESTAMP,
+// which gorm also sets on create, autoCreateTime for a DEFAULT
+// CURRENT_TIMESTAMP alone. Both are false under -no-auto-time.
+func autoTime(c *sqlparser.ColumnDefinition) (create, update bool) {
+	if noAutoTime || generatedColumns[c] || strings.TrimPrefix(GoType(c), "*") != "time.Time" {
+		return false, false
+	}
+	update = isCurrentTimestamp(c.Type.OnUpdate)
+	create = !update && isCurrentTimestamp(c.Type.Default)
+	return create, update
+}
@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const autoTimeSchema = "CREATE TABLE `events` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `opened` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+	"  `touched` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+	"  `seen` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),\n" +
+	"  `changed` datetime(6) NULL DEFAULT NULL ON UPDATE CURRENT_TIMESTAMP(6),\n" +
+	"  `due` datetime NOT NULL DEFAULT '2000-01-01 00:00:00',\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");"
+
+const autoTimeTest = `package model
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestAutoTime(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Events{}); err != nil {
+		t.Fatal(err)
+	}
+	e := Events{}
+	if err := db.Create(&e).Error; err != nil {
+		t.Fatal(err)
+	}
+	if e.Opened.IsZero() || e.Touched.IsZero() || e.Seen.IsZero() || e.Changed.IsZero() {
+		t.Errorf("times not set on create: %+v", e)
+	}
+	if !e.Due.IsZero() {
+		t.Errorf("due set on create: %v", e.Due)
+	}
+	opened, touched := e.Opened, e.Touched
+	time.Sleep(10 * time.Millisecond)
+	if err := db.Save(&e).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !e.Opened.Equal(opened) {
+		t.Errorf("opened moved from %v to %v", opened, e.Opened)
+	}
+	if !e.Touched.After(touched) {
+		t.Errorf("touched not updated: %v", e.Touched)
+	}
+}
+`
+
+func TestAutoTimeTags(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", autoTimeSchema)
+	if err := run("schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	events := gofmt(t, readFile(t, modelPath("events")))
+	for _, want := range []string{
+		// whatever the column names
+		"Opened  time.Time `gorm:\"Column:opened;autoCreateTime\" json:\"opened\"`",
+		"Touched time.Time `gorm:\"Column:touched;autoUpdateTime\" json:\"touched\"`",
+		// the precision variants
+		"Seen    time.Time `gorm:\"Column:seen;autoCreateTime\" json:\"seen\"`",
+		"Changed time.Time `gorm:\"Column:changed;autoUpdateTime\" json:\"changed\"`",
+		"Due     time.Time `gorm:\"Column:due\" json:\"due\"`",
+	} {
+		if !strings.Contains(events, want) {
+			t.Errorf("no %s in:\n%s", want, events)
+		}
+	}
+	writeFile(t, "model/autotime_test.go", autoTimeTest)
+	goTest(t, "./model")
+}
+
+func TestNoAutoTime(t *testing.T) {
+	events := generate(t, autoTimeSchema, "events", "-no-auto-time")
+	if strings.Contains(events, "autoCreateTime") || strings.Contains(events, "autoUpdateTime") {
+		t.Errorf("auto time tags with -no-auto-time:\n%s", events)
+	}
+}
+
+func TestAutoTimePostgres(t *testing.T) {
+	const schema = `CREATE TABLE public.events (
+    id bigint NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    logged_at timestamp(3) without time zone DEFAULT CURRENT_TIMESTAMP(3) NOT NULL
+);`
+	events := generate(t, schema, "events", "-dialect", "postgres")
+	for _, want := range []string{"Column:created_at;autoCreateTime", "Column:logged_at;autoCreateTime"} {
+		if !strings.Contains(events, want) {
+			t.Errorf("no %s in:\n%s", want, events)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// boolColumns holds the columns declared BOOL or BOOLEAN. MySQL stores them
+// as tinyint(1), which is also how they are handed to the sql parser, which
+// does not know the aliases.
+var boolColumns = make(map[*sqlparser.ColumnDefinition]bool)
+
+// boolDefaultTrue and boolDefaultFalse match the TRUE and FALSE defaults the
+// sql parser does not know either.
+var (
+	boolDefaultTrue  = regexp.MustCompile(`(?i)(\bdefault\s+)true\b`)
+	boolDefaultFalse = regexp.MustCompile(`(?i)(\bdefault\s+)false\b`)
+)
+
+// rewriteBoolColumn rewrites a BOOL or BOOLEAN column definition to
+// tinyint(1), returning its column name, or "" when def is not one.
+func rewriteBoolColumn(def string) (string, string) {
+	name, rest := sqlWord(def)
+	typ, after := sqlWord(rest)
+	if !strings.EqualFold(typ, "bool") && !strings.EqualFold(typ, "boolean") {
+		return def, ""
+	}
+	after = boolDefaultTrue.ReplaceAllString(after, "${1}1")
+	after = boolDefaultFalse.ReplaceAllString(after, "${1}0")
+	return def[:len(def)-len(rest)] + " tinyint(1)" + after, name
+}
+
+// rewriteBoolColumns rewrites the BOOL and BOOLEAN columns of a CREATE TABLE
+// statement to tinyint(1) and returns their names.
+func rewriteBoolColumns(stmt string) (string, []string) {
+	open, end, ok := createTableBody(stmt)
+	if !ok {
+		return stmt, nil
+	}
+	defs := splitTopLevel(stmt[open+1 : end])
+	var names []string
+	for i, def := range defs {
+		if d, name := rewriteBoolColumn(def); name != "" {
+			defs[i] = d
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return stmt, nil
+	}
+	return stmt[:open+1] + strings.Join(defs, ",") + stmt[end:], names
+}
+
+// markBoolColumns records the named columns of ddl in boolColumns.
+func markBoolColumns(ddl *sqlparser.DDL, names []string) {
+	for _, name := range names {
+		if c := findColumn(ddl, name); c != nil {
+			boolColumns[c] = true
+		}
+	}
+}
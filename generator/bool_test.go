@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const boolSchema = `CREATE TABLE flags (
+  id bigint NOT NULL AUTO_INCREMENT,
+  active bool NOT NULL DEFAULT TRUE,
+  deleted BOOLEAN NOT NULL DEFAULT false,
+  verified boolean,
+  tier tinyint(1) NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+func TestBoolColumns(t *testing.T) {
+	flags := generate(t, boolSchema, "flags", "-tags", "gorm,xorm")
+	for _, want := range []string{
+		"Active   bool  `gorm:\"Column:active\" xorm:\"'active' notnull default(1)\" json:\"active\"`",
+		"Deleted  bool  `gorm:\"Column:deleted\" xorm:\"'deleted' notnull default(0)\" json:\"deleted\"`",
+		"Verified bool  `gorm:\"Column:verified\" xorm:\"'verified'\" json:\"verified\"`",
+		// only the aliases are bool, a tinyint(1) stays an integer
+		"Tier     int   `gorm:\"Column:tier\" xorm:\"'tier' notnull\" json:\"tier\"`",
+	} {
+		if !strings.Contains(flags, want) {
+			t.Errorf("no %s in:\n%s", want, flags)
+		}
+	}
+
+	flags = generate(t, boolSchema, "flags", "-nullable-pointers")
+	if !strings.Contains(flags, "Verified *bool ") {
+		t.Errorf("verified is not a *bool with -nullable-pointers:\n%s", flags)
+	}
+}
+
+func TestBoolColumnsPostgres(t *testing.T) {
+	const schema = `CREATE TABLE public.flags (
+    id bigint NOT NULL,
+    active boolean DEFAULT true NOT NULL,
+    verified bool
+);`
+	flags := generate(t, schema, "flags", "-dialect", "postgres")
+	for _, want := range []string{"Active   bool ", "Verified bool "} {
+		if !strings.Contains(flags, want) {
+			t.Errorf("no %q in:\n%s", want, flags)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package generator
+
+import (
+	"fmt"
+	"go/build/constraint"
+	"strings"
+)
+
+// buildHeader holds the build constraint lines written above the package
+// clause of every generated file, see parseBuildTags.
+var buildHeader string
+
+// generatedHeader marks every generated file as such for go vet, linters
+// and code review tools. It follows the build constraint, which must be
+// followed by a blank line to apply.
+const generatedHeader = "// Code generated by dalgen. DO NOT EDIT.\n\n"
+
+// parseBuildTags renders the -build-tags expression, e.g. "mysql" or
+// "mysql && !race", as a //go:build line followed by the equivalent
+// // +build lines older Go versions read, and a blank line.
+func parseBuildTags(tags string) (string, error) {
+	if strings.TrimSpace(tags) == "" {
+		return "", nil
+	}
+	expr, err := constraint.Parse("//go:build " + tags)
+	if err != nil {
+		return "", fmt.Errorf("-build-tags %q: %v", tags, err)
+	}
+	lines := []string{"//go:build " + expr.String()}
+	plus, err := constraint.PlusBuildLines(expr)
+	if err != nil {
+		return "", fmt.Errorf("-build-tags %q: %v", tags, err)
+	}
+	lines = append(lines, plus...)
+	return strings.Join(lines, "\n") + "\n\n", nil
+}
@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+)
+
+func TestParseBuildTags(t *testing.T) {
+	for _, tt := range []struct{ tags, want string }{
+		{"", ""},
+		{"mysql", "//go:build mysql\n// +build mysql\n\n"},
+		{"mysql && !race", "//go:build mysql && !race\n// +build mysql,!race\n\n"},
+		{"mysql || tidb", "//go:build mysql || tidb\n// +build mysql tidb\n\n"},
+	} {
+		got, err := parseBuildTags(tt.tags)
+		if err != nil {
+			t.Errorf("%q: %v", tt.tags, err)
+		} else if got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+	if _, err := parseBuildTags("mysql &&"); err == nil {
+		t.Error("parsed mysql &&")
+	}
+}
+
+func TestBuildTagsHeader(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", usersSchema)
+	if err := run("-build-tags", "mysql && !race", "-dal", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	const want = "//go:build mysql && !race\n// +build mysql,!race\n\n// Code generated by dalgen. DO NOT EDIT.\n\npackage model\n"
+	for _, name := range []string{modelPath("users"), "model/users_dal.go"} {
+		got := readFile(t, name)
+		if !strings.HasPrefix(got, want) {
+			t.Errorf("%s does not start with the build constraint and the header:\n%s", name, got)
+		}
+		formatted, err := format.Source([]byte(got))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.HasPrefix(string(formatted), want) {
+			t.Errorf("gofmt moved the header of %s:\n%s", name, formatted)
+		}
+	}
+
+	// without -build-tags the header comes first
+	users := generate(t, usersSchema, "users")
+	if !strings.HasPrefix(users, "// Code generated by dalgen. DO NOT EDIT.\n\npackage model\n") {
+		t.Errorf("users.go does not start with the header:\n%s", users)
+	}
+}
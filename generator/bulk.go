@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const bulkTemplate = `
+package {{.Package}}
+
+import "gorm.io/gorm"
+
+// {{.TableName}}InsertBatchSize is the number of rows Insert{{.TableName}}
+// inserts per INSERT statement.
+const {{.TableName}}InsertBatchSize = {{.BatchSize}}
+
+// Insert{{.TableName}} inserts rows into {{.TableNameStr}} with
+// CreateInBatches, {{.TableName}}InsertBatchSize rows per statement, filling
+// in their primary keys. No rows is a no-op.
+func Insert{{.TableName}}(db *gorm.DB, rows []{{.TableName}}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(rows, {{.TableName}}InsertBatchSize).Error
+}
+`
+
+// checkBatchSize validates -batch-size.
+func checkBatchSize() error {
+	if batchSize <= 0 {
+		return fmt.Errorf("-batch-size must be positive, got %d", batchSize)
+	}
+	return nil
+}
+
+func genBulk(pkg string, ddl *sqlparser.DDL) string {
+	tableNameStr := ddl.NewName.Name.String()
+	params := struct {
+		Package      string
+		TableName    string
+		TableNameStr string
+		BatchSize    int
+	}{
+		Package:      pkg,
+		TableName:    modelName(tableNameStr),
+		TableNameStr: tableNameStr,
+		BatchSize:    batchSize,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("bulk").Parse(bulkTemplate)).Execute(&buf, params)
+	return buf.String()
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const bulkSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+const bulkTest = `package model
+
+import (
+	"fmt"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestInsertUsers(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Users{}); err != nil {
+		t.Fatal(err)
+	}
+	inserts := 0
+	db.Callback().Create().After("gorm:create").Register("count", func(*gorm.DB) { inserts++ })
+
+	if err := InsertUsers(db, nil); err != nil || inserts != 0 {
+		t.Fatalf("no rows: %v, %d inserts", err, inserts)
+	}
+	rows := make([]Users, 5)
+	for i := range rows {
+		rows[i].Email = fmt.Sprintf("%d@example.com", i)
+	}
+	if err := InsertUsers(db, rows); err != nil {
+		t.Fatal(err)
+	}
+	if inserts != 3 {
+		t.Errorf("%d inserts of 5 rows, 2 per statement", inserts)
+	}
+	for i, r := range rows {
+		if r.ID != int64(i+1) {
+			t.Errorf("row %d has id %d", i, r.ID)
+		}
+	}
+	var n int64
+	if err := db.Model(&Users{}).Count(&n).Error; err != nil || n != 5 {
+		t.Errorf("%d rows, %v", n, err)
+	}
+}
+`
+
+func TestGenBulk(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", bulkSchema)
+	if err := run("-gen-bulk", "-batch-size", "2", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	bulk := gofmt(t, readFile(t, "model/users_bulk.go"))
+	for _, want := range []string{
+		"const UsersInsertBatchSize = 2\n",
+		"func InsertUsers(db *gorm.DB, rows []Users) error {",
+		"return db.CreateInBatches(rows, UsersInsertBatchSize).Error",
+	} {
+		if !strings.Contains(bulk, want) {
+			t.Errorf("no %s in:\n%s", want, bulk)
+		}
+	}
+	writeFile(t, "model/bulk_test.go", bulkTest)
+	goTest(t, "./model")
+}
+
+func TestGenBulkDefaults(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", bulkSchema)
+	if err := run("-gen-bulk", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if bulk := readFile(t, "model/users_bulk.go"); !strings.Contains(bulk, "const UsersInsertBatchSize = 100\n") {
+		t.Errorf("not the default batch size:\n%s", bulk)
+	}
+
+	chdir(t)
+	writeFile(t, "schema.sql", bulkSchema)
+	if err := run("schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("model/users_bulk.go"); err == nil {
+		t.Error("users_bulk.go written without -gen-bulk")
+	}
+
+	chdir(t)
+	writeFile(t, "schema.sql", bulkSchema)
+	err := run("-gen-bulk", "-batch-size", "0", "schema.sql")
+	if want := "-batch-size must be positive, got 0"; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %s", err, want)
+	}
+}
@@ -0,0 +1,161 @@
+package generator
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const cacheTemplate = `
+package {{.Package}}
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+{{- range std .Imports}}{{if ne . "time"}}
+	"{{.}}"
+{{- end}}{{end}}
+{{range thirdParty .Imports}}
+	"{{.}}"
+{{- end}}
+	"github.com/go-redis/redis/v8"
+)
+
+// {{.TableName}}CacheKeyPrefix starts the cache keys of {{.TableNameStr}}, followed by
+// ":pk" and the primary key, or by the name and the columns of a unique key.
+const {{.TableName}}CacheKeyPrefix = "dalgen:{{.Database}}:{{.TableNameStr}}"
+
+type Cached{{.DAO}} struct {
+	*{{.DAO}}
+	client redis.UniversalClient
+	ttl    time.Duration
+}
+
+var _ {{.DAO}}Iface = (*Cached{{.DAO}})(nil)
+
+func NewCached{{.DAO}}(dao *{{.DAO}}, client redis.UniversalClient, ttl time.Duration) *Cached{{.DAO}} {
+	return &Cached{{.DAO}}{ {{- .DAO}}: dao, client: client, ttl: ttl}
+}
+
+// load returns the model cached at key, or fetches and caches it. The cache
+// is best effort: a redis error is logged and the model fetched.
+func (d *Cached{{.DAO}}) load(ctx context.Context, key string, fetch func() (*{{.TableName}}, error)) (*{{.TableName}}, error) {
+	data, err := d.client.Get(ctx, key).Bytes()
+	switch {
+	case err == nil:
+		var m {{.TableName}}
+		if err := json.Unmarshal(data, &m); err == nil {
+			return &m, nil
+		}
+	case err != redis.Nil:
+		log.Printf("Cached{{.DAO}}: get %s: %v", key, err)
+	}
+	m, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(m); err == nil {
+		if err := d.client.Set(ctx, key, data, d.ttl).Err(); err != nil {
+			log.Printf("Cached{{.DAO}}: set %s: %v", key, err)
+		}
+	}
+	return m, nil
+}
+{{- if .PK}}
+
+func (d *Cached{{.DAO}}) keys(m *{{.TableName}}) []string {
+	return []string{
+		{{.TableName}}CacheKeyPrefix + ":pk" + fmt.Sprint({{range .PK}}":", m.{{.Field}}, {{end}}),
+		{{- range .Uniques}}
+		{{$.TableName}}CacheKeyPrefix + ":{{.Name}}" + fmt.Sprint({{range .Columns}}":", m.{{.Field}}, {{end}}),
+		{{- end}}
+	}
+}
+
+func (d *Cached{{.DAO}}) invalidate(ctx context.Context, ms ...*{{.TableName}}) error {
+	var keys []string
+	for _, m := range ms {
+		if m != nil {
+			keys = append(keys, d.keys(m)...)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return d.client.Del(ctx, keys...).Err()
+}
+
+func (d *Cached{{.DAO}}) GetByID(ctx context.Context{{range .PK}}, {{.Param}} {{.Type}}{{end}}) (*{{.TableName}}, error) {
+	key := {{.TableName}}CacheKeyPrefix + ":pk" + fmt.Sprint({{range .PK}}":", {{.Param}}, {{end}})
+	return d.load(ctx, key, func() (*{{.TableName}}, error) {
+		return d.{{.DAO}}.GetByID(ctx{{range .PK}}, {{.Param}}{{end}})
+	})
+}
+{{- end}}
+{{- range .Uniques}}
+
+func (d *Cached{{$.DAO}}) {{.Method}}(ctx context.Context{{range .Columns}}, {{.Param}} {{.Type}}{{end}}) (*{{$.TableName}}, error) {
+	key := {{$.TableName}}CacheKeyPrefix + ":{{.Name}}" + fmt.Sprint({{range .Columns}}":", {{.Param}}, {{end}})
+	return d.load(ctx, key, func() (*{{$.TableName}}, error) {
+		return d.{{$.DAO}}.{{.Method}}(ctx{{range .Columns}}, {{.Param}}{{end}})
+	})
+}
+{{- end}}
+{{- if .PK}}
+
+func (d *Cached{{.DAO}}) Update(ctx context.Context, m *{{.TableName}}) error {
+	old, _ := d.{{.DAO}}.GetByID(ctx{{range .PK}}, m.{{.Field}}{{end}})
+	if err := d.{{.DAO}}.Update(ctx, m); err != nil {
+		return err
+	}
+	return d.invalidate(ctx, old, m)
+}
+
+func (d *Cached{{.DAO}}) Upsert(ctx context.Context, m *{{.TableName}}) error {
+	old, _ := d.{{.DAO}}.GetByID(ctx{{range .PK}}, m.{{.Field}}{{end}})
+	if err := d.{{.DAO}}.Upsert(ctx, m); err != nil {
+		return err
+	}
+	return d.invalidate(ctx, old, m)
+}
+
+{{- range .Bulk}}
+
+func (d *Cached{{$.DAO}}) Update{{.Field}}ByIDs(ctx context.Context, ids []{{(index $.PK 0).Type}}, {{.Param}} {{.Type}}) (int64, error) {
+	old, err := d.{{$.DAO}}.getByIDs(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	n, err := d.{{$.DAO}}.Update{{.Field}}ByIDs(ctx, ids, {{.Param}})
+	if err != nil {
+		return n, err
+	}
+	return n, d.invalidate(ctx, old...)
+}
+{{- end}}
+
+func (d *Cached{{.DAO}}) Delete(ctx context.Context{{range .PK}}, {{.Param}} {{.Type}}{{end}}) error {
+	old, _ := d.{{.DAO}}.GetByID(ctx{{range .PK}}, {{.Param}}{{end}})
+	if err := d.{{.DAO}}.Delete(ctx{{range .PK}}, {{.Param}}{{end}}); err != nil {
+		return err
+	}
+	if old == nil {
+		old = &{{.TableName}}{}
+{{- range .PK}}
+		old.{{.Field}} = {{.Param}}
+{{- end}}
+	}
+	return d.invalidate(ctx, old)
+}
+{{- end}}
+`
+
+func genCache(pkg string, ddl *sqlparser.DDL) string {
+	var buf bytes.Buffer
+	_ = template.Must(template.New("cache").Funcs(importFuncs).Parse(cacheTemplate)).Execute(&buf, newDALParams(pkg, ddl))
+	return buf.String()
+}
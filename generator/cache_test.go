@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const cacheSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  name varchar(64) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_email (email)
+);`
+
+const cacheTest = `package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setup(t *testing.T) (*CachedUsersDAO, *gorm.DB, *miniredis.Miniredis) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Users{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&Users{Email: "ann@example.com", Name: "Ann"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return NewCachedUsersDAO(NewUsersDAO(db), client, time.Minute), db, mr
+}
+
+const (
+	pkKey    = "dalgen:model:users:pk:1"
+	emailKey = "dalgen:model:users:uk_email:ann@example.com"
+)
+
+func TestCacheMissAndHit(t *testing.T) {
+	dao, db, mr := setup(t)
+	ctx := context.Background()
+
+	// a miss loads from the database and caches
+	u, err := dao.GetByID(ctx, 1)
+	if err != nil || u.Name != "Ann" {
+		t.Fatalf("got %+v, %v", u, err)
+	}
+	if !mr.Exists(pkKey) {
+		t.Fatalf("%s not cached, keys %v", pkKey, mr.Keys())
+	}
+	if ttl := mr.TTL(pkKey); ttl != time.Minute {
+		t.Errorf("ttl = %v, want 1m", ttl)
+	}
+	if _, err := dao.GetByEmail(ctx, "ann@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if !mr.Exists(emailKey) {
+		t.Fatalf("%s not cached, keys %v", emailKey, mr.Keys())
+	}
+
+	// a hit does not read the database
+	db.Exec("UPDATE users SET name = 'Changed' WHERE id = 1")
+	if u, _ := dao.GetByID(ctx, 1); u.Name != "Ann" {
+		t.Errorf("got %q from the cache, want Ann", u.Name)
+	}
+	if u, _ := dao.GetByEmail(ctx, "ann@example.com"); u.Name != "Ann" {
+		t.Errorf("got %q from the cache, want Ann", u.Name)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	dao, _, mr := setup(t)
+	ctx := context.Background()
+
+	u, _ := dao.GetByID(ctx, 1)
+	dao.GetByEmail(ctx, "ann@example.com")
+	u.Name = "Anne"
+	if err := dao.Update(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists(pkKey) || mr.Exists(emailKey) {
+		t.Errorf("keys left after Update: %v", mr.Keys())
+	}
+	if u, _ := dao.GetByID(ctx, 1); u.Name != "Anne" {
+		t.Errorf("got %q after Update, want Anne", u.Name)
+	}
+
+	dao.GetByEmail(ctx, "ann@example.com")
+	if err := dao.Delete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists(pkKey) || mr.Exists(emailKey) {
+		t.Errorf("keys left after Delete: %v", mr.Keys())
+	}
+	if _, err := dao.GetByID(ctx, 1); err != gorm.ErrRecordNotFound {
+		t.Errorf("got %v after Delete, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func TestCacheRedisDown(t *testing.T) {
+	dao, _, mr := setup(t)
+	mr.Close()
+	// the DAO answers when redis cannot
+	u, err := dao.GetByID(context.Background(), 1)
+	if err != nil || u.Name != "Ann" {
+		t.Errorf("got %+v, %v, want Ann from the database", u, err)
+	}
+}
+`
+
+func TestCache(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", cacheSchema)
+	if err := run("-gen-cache", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	cache := readFile(t, "model/users_cache.go")
+	if !strings.Contains(cache, `UsersCacheKeyPrefix + ":pk"`) {
+		t.Errorf("no :pk segment in the primary key cache key:\n%s", cache)
+	}
+	writeFile(t, "model/cache_test.go", cacheTest)
+	goTest(t, "./model")
+}
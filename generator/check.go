@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// checkedFiles holds the files -check regenerated, and staleFiles and
+// missingFiles those differing from or missing on disk.
+var (
+	checkedFiles = make(map[string]bool)
+	staleFiles   []string
+	missingFiles []string
+)
+
+// generatedSuffixes are the suffixes genTableFiles adds to the table file
+// names, which mark the files of dropped tables as orphaned.
+var generatedSuffixes = []string{
+	"_fuzz_test", "_compare", "_dto", "_patch", "_updatemap", "_clone", "_getters", "_fieldsvalues",
+	"_events", "_scopes", "_where", "_dal", "_fixtures", "_bulk", "_cache", "_otel", "_http",
+}
+
+// checkFile compares the content dalgen would write to fp with the file on
+// disk.
+func checkFile(fp string, content []byte) {
+	checkedFiles[fp] = true
+	onDisk, err := ioutil.ReadFile(fp)
+	switch {
+	case err != nil:
+		missingFiles = append(missingFiles, fp)
+	case !bytes.Equal(onDisk, content):
+		staleFiles = append(staleFiles, fp)
+	}
+}
+
+// orphanedFiles returns the files of the directories -check regenerated
+// into that look generated but were not: those named like the files of a
+// table, the helper files and the models.
+func orphanedFiles() []string {
+	dirs := make(map[string]bool)
+	for fp := range checkedFiles {
+		if strings.HasSuffix(fp, ".go") {
+			dirs[path.Dir(fp)] = true
+		}
+	}
+	var orphans []string
+	for dir := range dirs {
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fi := range infos {
+			fp := path.Join(dir, fi.Name())
+			if fi.IsDir() || checkedFiles[fp] || !strings.HasSuffix(fi.Name(), ".go") {
+				continue
+			}
+			if looksGenerated(fp) {
+				orphans = append(orphans, fp)
+			}
+		}
+	}
+	return orphans
+}
+
+func looksGenerated(fp string) bool {
+	name := strings.TrimSuffix(path.Base(fp), ".go")
+	name = strings.TrimSuffix(name, strings.TrimSuffix(outExt, ".go"))
+	if strings.HasPrefix(name, "dalgen_") || name == "version" || typesFile != "" && name == typesFileName() {
+		return true
+	}
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), fp, nil, 0)
+	if err != nil {
+		return false
+	}
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil && fn.Name.Name == "TableName" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReport fails with the out of date, missing and orphaned files, in
+// path order, when any.
+func checkReport() error {
+	pwd, _ := os.Getwd()
+	var lines []string
+	list := func(what string, files []string) {
+		sort.Strings(files)
+		for _, fp := range files {
+			lines = append(lines, "  "+what+": "+strings.TrimPrefix(fp, pwd+"/"))
+		}
+	}
+	list("out of date", staleFiles)
+	list("missing", missingFiles)
+	list("orphaned", orphanedFiles())
+	if len(lines) == 0 {
+		return nil
+	}
+	return fmt.Errorf("-check: the generated code does not match the schema, rerun dalgen:\n%s", strings.Join(lines, "\n"))
+}
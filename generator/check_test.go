@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", usersSchema)
+	if err := run("schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("-check", "schema.sql"); err != nil {
+		t.Errorf("up to date: %v", err)
+	}
+
+	writeFile(t, "schema.sql", strings.Replace(usersSchema, "email varchar(255)", "email varchar(64)", 1))
+	err := run("-check", "schema.sql")
+	if err == nil || !strings.Contains(err.Error(), "out of date: model/users.go") {
+		t.Errorf("stale: got %v, want users.go out of date", err)
+	}
+
+	writeFile(t, "schema.sql", usersSchema)
+	if err := os.Remove(modelPath("users")); err != nil {
+		t.Fatal(err)
+	}
+	err = run("-check", "schema.sql")
+	if err == nil || !strings.Contains(err.Error(), "missing: model/users.go") {
+		t.Errorf("missing: got %v, want users.go missing", err)
+	}
+	if _, err := os.Stat(modelPath("users")); err == nil {
+		t.Error("-check wrote users.go")
+	}
+}
+
+func TestCheckOrphaned(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", usersSchema+"\nCREATE TABLE posts (id bigint NOT NULL, PRIMARY KEY (id));")
+	if err := run("-dal", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, "schema.sql", usersSchema)
+	err := run("-dal", "-check", "schema.sql")
+	if err == nil {
+		t.Fatal("got no error, want posts.go and posts_dal.go orphaned")
+	}
+	for _, want := range []string{"orphaned: model/posts.go", "orphaned: model/posts_dal.go"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error lacks %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "users") {
+		t.Errorf("users reported:\n%v", err)
+	}
+}
@@ -0,0 +1,332 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// checkConstraint is a CHECK constraint, Name being "" when unnamed.
+type checkConstraint struct {
+	Name string
+	Expr string
+}
+
+var (
+	// columnChecks holds the CHECK constraints declared inline, rendered as
+	// gorm check tags.
+	columnChecks = make(map[*sqlparser.ColumnDefinition]checkConstraint)
+	// tableChecks holds the table-level CHECK constraints, listed in the
+	// doc comment of the model.
+	tableChecks = make(map[*sqlparser.DDL][]checkConstraint)
+)
+
+var (
+	// checkClause matches the start of a [CONSTRAINT name] CHECK (expr)
+	// clause, which the sql parser does not know.
+	checkClause = regexp.MustCompile("(?i)^(constraint\\s+(`[^`]*`|\"[^\"]*\"|\\w+)\\s+)?check\\s*\\(")
+	// checkEnforced matches the MySQL [NOT] ENFORCED after the expression.
+	checkEnforced = regexp.MustCompile(`(?i)^\s*(not\s+)?enforced\b`)
+)
+
+// checkExpr normalizes the expression of a CHECK constraint: whitespace is
+// collapsed, the backticks of identifiers and redundant outer parentheses
+// are dropped, so CHECK ((`lo` <= `hi`)) gives lo <= hi.
+func checkExpr(expr string) string {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && i+1 < len(expr) {
+				b.WriteByte(c)
+				i++
+				c = expr[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '`':
+			continue
+		}
+		b.WriteByte(c)
+	}
+	expr = strings.Join(strings.Fields(b.String()), " ")
+	for strings.HasPrefix(expr, "(") && closingParen(expr, 0) == len(expr)-1 {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	return expr
+}
+
+// stripCheck removes the CHECK clause from a column or table constraint
+// definition. ok is false when def has none; enforced is false for the
+// MySQL NOT ENFORCED checks.
+func stripCheck(def string) (rest string, check checkConstraint, enforced, ok bool) {
+	start, open := findClause(def, checkClause)
+	if start < 0 {
+		return def, check, false, false
+	}
+	end := closingParen(def, open)
+	if end < 0 {
+		return def, check, false, false
+	}
+	if m := checkClause.FindStringSubmatch(def[start:]); m[2] != "" {
+		check.Name = strings.Trim(m[2], "`\"")
+	}
+	check.Expr = checkExpr(def[open+1 : end])
+	rest, enforced = def[end+1:], true
+	if m := checkEnforced.FindStringSubmatchIndex(rest); m != nil {
+		enforced = m[2] < 0
+		rest = rest[m[1]:]
+	}
+	return def[:start] + rest, check, enforced, true
+}
+
+// stripChecks removes the CHECK constraints of a CREATE TABLE statement,
+// returning those of its columns by column name and the table-level ones.
+func stripChecks(stmt string) (string, map[string]checkConstraint, []checkConstraint) {
+	open, end, ok := createTableBody(stmt)
+	if !ok {
+		return stmt, nil, nil
+	}
+	var defs []string
+	columns := make(map[string]checkConstraint)
+	var table []checkConstraint
+	stripped := false
+	for _, def := range splitTopLevel(stmt[open+1 : end]) {
+		rest, check, enforced, ok := stripCheck(def)
+		if !ok {
+			defs = append(defs, def)
+			continue
+		}
+		stripped = true
+		if strings.TrimSpace(rest) == "" {
+			// a table constraint
+			if enforced {
+				table = append(table, check)
+			}
+			continue
+		}
+		if enforced {
+			name, _ := sqlWord(def)
+			columns[name] = check
+		}
+		defs = append(defs, rest)
+	}
+	if !stripped {
+		return stmt, nil, nil
+	}
+	return stmt[:open+1] + strings.Join(defs, ",") + stmt[end:], columns, table
+}
+
+// markChecks records the CHECK constraints stripped from the statement of
+// ddl.
+func markChecks(ddl *sqlparser.DDL, columns map[string]checkConstraint, table []checkConstraint) {
+	for name, check := range columns {
+		if c := findColumn(ddl, name); c != nil {
+			columnChecks[c] = check
+		}
+	}
+	if len(table) > 0 {
+		tableChecks[ddl] = append(tableChecks[ddl], table...)
+	}
+}
+
+// checkTag renders the gorm check tag value of a column, "" if it has no
+// CHECK constraint.
+func checkTag(c *sqlparser.ColumnDefinition) string {
+	check, ok := columnChecks[c]
+	if !ok {
+		return ""
+	}
+	tag := check.Expr
+	if check.Name != "" {
+		tag = check.Name + "," + tag
+	}
+	// gorm splits its tag at unescaped semicolons
+	return strings.Replace(tag, ";", `\;`, -1)
+}
+
+// checksDoc lists the table-level CHECK constraints of ddl for the doc
+// comment of its model, "" if it has none.
+func checksDoc(ddl *sqlparser.DDL) string {
+	checks := tableChecks[ddl]
+	if len(checks) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("//\n// Checks:\n//")
+	for _, check := range checks {
+		b.WriteString("\n//   - ")
+		if check.Name != "" {
+			b.WriteString(check.Name + ": ")
+		}
+		b.WriteString(sanitizeComment(check.Expr))
+	}
+	return b.String()
+}
+
+// checkRule is the validation a column's CHECK constraints allow to derive:
+// bounds from comparisons with numbers and allowed values from IN lists.
+type checkRule struct {
+	Min, Max                   string
+	ExclusiveMin, ExclusiveMax bool
+	OneOf                      []string
+}
+
+// flippedOperators turns 0 < n into n > 0.
+var flippedOperators = map[string]string{"<": ">", "<=": ">=", ">": "<", ">=": "<=", "=": "="}
+
+// checkRules derives the validation rules of the columns of ddl from its
+// column and table CHECK constraints. Comparisons of a column with a
+// number, BETWEEN and IN lists joined by AND are understood; the
+// constraints holding anything else are skipped with a warning.
+func checkRules(ddl *sqlparser.DDL) map[*sqlparser.ColumnDefinition]*checkRule {
+	rules := make(map[*sqlparser.ColumnDefinition]*checkRule)
+	checks := append([]checkConstraint(nil), tableChecks[ddl]...)
+	for _, c := range ddl.TableSpec.Columns {
+		if check, ok := columnChecks[c]; ok {
+			checks = append(checks, check)
+		}
+	}
+	for _, check := range checks {
+		derived := make(map[*sqlparser.ColumnDefinition]*checkRule)
+		stmt, err := sqlparser.Parse("select 1 from t where " + check.Expr)
+		if err == nil {
+			err = deriveCheckRules(ddl, stmt.(*sqlparser.Select).Where.Expr, derived)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: table %s: no validation derived from CHECK (%s): %v\n",
+				ddl.NewName.Name.String(), check.Expr, err)
+			continue
+		}
+		for c, d := range derived {
+			rule := rules[c]
+			if rule == nil {
+				rule = &checkRule{}
+				rules[c] = rule
+			}
+			if d.Min != "" {
+				rule.Min, rule.ExclusiveMin = d.Min, d.ExclusiveMin
+			}
+			if d.Max != "" {
+				rule.Max, rule.ExclusiveMax = d.Max, d.ExclusiveMax
+			}
+			if d.OneOf != nil {
+				rule.OneOf = d.OneOf
+			}
+		}
+	}
+	return rules
+}
+
+// deriveCheckRules adds the rules expr implies to rules.
+func deriveCheckRules(ddl *sqlparser.DDL, expr sqlparser.Expr, rules map[*sqlparser.ColumnDefinition]*checkRule) error {
+	rule := func(col *sqlparser.ColName) (*checkRule, error) {
+		c := findColumn(ddl, col.Name.String())
+		if c == nil {
+			return nil, fmt.Errorf("unknown column %s", col.Name.String())
+		}
+		if rules[c] == nil {
+			rules[c] = &checkRule{}
+		}
+		return rules[c], nil
+	}
+	switch e := expr.(type) {
+	case *sqlparser.ParenExpr:
+		return deriveCheckRules(ddl, e.Expr, rules)
+	case *sqlparser.AndExpr:
+		if err := deriveCheckRules(ddl, e.Left, rules); err != nil {
+			return err
+		}
+		return deriveCheckRules(ddl, e.Right, rules)
+	case *sqlparser.RangeCond:
+		col, ok := e.Left.(*sqlparser.ColName)
+		from, fromOK := checkNumber(e.From)
+		to, toOK := checkNumber(e.To)
+		if !ok || !fromOK || !toOK || e.Operator != sqlparser.BetweenStr {
+			break
+		}
+		r, err := rule(col)
+		if err != nil {
+			return err
+		}
+		r.Min, r.Max = from, to
+		return nil
+	case *sqlparser.ComparisonExpr:
+		if e.Operator == sqlparser.InStr {
+			col, ok := e.Left.(*sqlparser.ColName)
+			list, listOK := e.Right.(sqlparser.ValTuple)
+			if !ok || !listOK {
+				break
+			}
+			values := make([]string, 0, len(list))
+			for _, v := range list {
+				val, ok := v.(*sqlparser.SQLVal)
+				if !ok {
+					return fmt.Errorf("%s is not a literal", sqlparser.String(v))
+				}
+				values = append(values, string(val.Val))
+			}
+			r, err := rule(col)
+			if err != nil {
+				return err
+			}
+			r.OneOf = values
+			return nil
+		}
+		op, left, right := e.Operator, e.Left, e.Right
+		if _, ok := left.(*sqlparser.ColName); !ok {
+			op, left, right = flippedOperators[op], right, left
+		}
+		col, ok := left.(*sqlparser.ColName)
+		if !ok {
+			break
+		}
+		if op == "=" {
+			if val, ok := right.(*sqlparser.SQLVal); ok {
+				r, err := rule(col)
+				if err != nil {
+					return err
+				}
+				r.OneOf = []string{string(val.Val)}
+				return nil
+			}
+			break
+		}
+		n, ok := checkNumber(right)
+		if _, known := flippedOperators[op]; !ok || !known {
+			break
+		}
+		r, err := rule(col)
+		if err != nil {
+			return err
+		}
+		switch op {
+		case ">", ">=":
+			r.Min, r.ExclusiveMin = n, op == ">"
+		case "<", "<=":
+			r.Max, r.ExclusiveMax = n, op == "<"
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot interpret %s", sqlparser.String(expr))
+}
+
+// checkNumber returns the number expr is a literal of.
+func checkNumber(expr sqlparser.Expr) (string, bool) {
+	if u, ok := expr.(*sqlparser.UnaryExpr); ok && u.Operator == sqlparser.UMinusStr {
+		n, ok := checkNumber(u.Expr)
+		return "-" + n, ok
+	}
+	val, ok := expr.(*sqlparser.SQLVal)
+	if !ok || val.Type != sqlparser.IntVal && val.Type != sqlparser.FloatVal {
+		return "", false
+	}
+	return string(val.Val), true
+}
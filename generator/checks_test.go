@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const checksSchema = "CREATE TABLE `items` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `qty` int NOT NULL CHECK (`qty` >= 0),\n" +
+	"  `price` int NOT NULL CONSTRAINT `price_positive` CHECK ((`price` > 0)),\n" +
+	"  `lo` int NOT NULL,\n" +
+	"  `hi` int NOT NULL,\n" +
+	"  `note` varchar(16) NOT NULL CHECK (note <> '') NOT ENFORCED,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  CONSTRAINT `lo_le_hi` CHECK ((`lo` <= `hi`)),\n" +
+	"  CHECK (`hi` < 1000)\n" +
+	");"
+
+const checksTest = `package model
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCheckTags(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Items{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&Items{Qty: 0, Price: 1, Lo: 1, Hi: 2}).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []Items{{Qty: -1, Price: 1}, {Qty: 1, Price: 0}} {
+		if err := db.Create(&m).Error; err == nil {
+			t.Errorf("%+v inserted despite the column checks", m)
+		}
+	}
+	if !db.Migrator().HasConstraint(&Items{}, "price_positive") {
+		t.Error("the named check price_positive was not created")
+	}
+}
+`
+
+func TestColumnChecks(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", checksSchema)
+	if err := run("schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	items := gofmt(t, readFile(t, modelPath("items")))
+	for _, want := range []string{
+		"Qty   int    `gorm:\"Column:qty;check:qty >= 0\" json:\"qty\"`",
+		"Price int    `gorm:\"Column:price;check:price_positive,price > 0\" json:\"price\"`",
+		// NOT ENFORCED checks are not created
+		"Note  string `gorm:\"Column:note;size:16\" json:\"note\"`",
+		"// Checks:\n//\n//   - lo_le_hi: lo <= hi\n//   - hi < 1000\ntype Items struct {",
+	} {
+		if !strings.Contains(items, want) {
+			t.Errorf("no %s in:\n%s", want, items)
+		}
+	}
+	writeFile(t, "model/checks_test.go", checksTest)
+	goTest(t, "./model")
+}
+
+func TestPostgresChecks(t *testing.T) {
+	const schema = `CREATE TABLE public.items (
+    id bigint NOT NULL,
+    qty integer NOT NULL,
+    lo integer NOT NULL,
+    hi integer NOT NULL,
+    CONSTRAINT items_qty_check CHECK ((qty >= 0)),
+    CONSTRAINT lo_le_hi CHECK ((lo <= hi))
+);
+ALTER TABLE ONLY public.items ADD CONSTRAINT items_pkey PRIMARY KEY (id);`
+	// pg_dump writes every check as a table constraint
+	items := generate(t, schema, "items", "-dialect", "postgres")
+	if want := "//   - items_qty_check: qty >= 0\n//   - lo_le_hi: lo <= hi\n"; !strings.Contains(items, want) {
+		t.Errorf("no %s in:\n%s", want, items)
+	}
+}
+
+const validationSchema = "CREATE TABLE `people` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `age` int NOT NULL CHECK (`age` >= 0 AND `age` <= 150),\n" +
+	"  `score` double NOT NULL CHECK (score BETWEEN 0.5 AND 10),\n" +
+	"  `status` varchar(8) NOT NULL CHECK (`status` IN ('a','b')),\n" +
+	"  `tier` int NOT NULL,\n" +
+	"  `name` varchar(64) NOT NULL CHECK (char_length(`name`) > 2),\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  CONSTRAINT `tier_pos` CHECK (0 < `tier`)\n" +
+	");"
+
+func TestCheckValidation(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", validationSchema)
+	_, stderr, err := capture(t, func() error { return run("-openapi-out", "api.yaml", "schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "warning: table people: no validation derived from CHECK (char_length(name) > 2): cannot interpret char_length(name) > 2\n"; stderr != want {
+		t.Errorf("got warnings\n%s\nwant\n%s", stderr, want)
+	}
+	api := readFile(t, "api.yaml")
+	for _, want := range []string{
+		// a range
+		"        age:\n          type: integer\n          format: int64\n          minimum: 0\n          maximum: 150\n",
+		"        score:\n          type: number\n          format: double\n          minimum: 0.5\n          maximum: 10\n",
+		// an IN list
+		"        status:\n          type: string\n          enum:\n            - \"a\"\n            - \"b\"\n",
+		// a table check, flipped
+		"        tier:\n          type: integer\n          format: int64\n          minimum: 0\n          exclusiveMinimum: true\n",
+		// nothing from the uninterpretable check
+		"        name:\n          type: string\n",
+	} {
+		if !strings.Contains(api, want) {
+			t.Errorf("no\n%s\nin:\n%s", want, api)
+		}
+	}
+	// the raw constraints stay in the model
+	people := gofmt(t, readFile(t, modelPath("people")))
+	for _, want := range []string{"//   - tier_pos: 0 < tier\n", "check:char_length(name) > 2"} {
+		if !strings.Contains(people, want) {
+			t.Errorf("no %s in:\n%s", want, people)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const cloneTemplate = `
+package {{.Package}}
+
+{{.Imports}}
+// Clone returns a deep copy of m{{if .Associations}}, cloning its associations{{end}}.
+func (m *{{.TableName}}) Clone() *{{.TableName}} {
+	if m == nil {
+		return nil
+	}
+	c := *m
+{{- range .Fields}}
+{{- if eq .Copy "slice"}}
+	if m.{{.Field}} != nil {
+		c.{{.Field}} = make({{.Type}}, len(m.{{.Field}}))
+		copy(c.{{.Field}}, m.{{.Field}})
+	}
+{{- else if eq .Copy "pointer"}}
+	if m.{{.Field}} != nil {
+		v := *m.{{.Field}}
+		c.{{.Field}} = &v
+	}
+{{- else if eq .Copy "belongs"}}
+	c.{{.Field}} = m.{{.Field}}.Clone()
+{{- else if eq .Copy "hasmany"}}
+	if m.{{.Field}} != nil {
+		c.{{.Field}} = make({{.Type}}, len(m.{{.Field}}))
+		for i := range m.{{.Field}} {
+			c.{{.Field}}[i] = *m.{{.Field}}[i].Clone()
+		}
+	}
+{{- end}}
+{{- end}}
+	return &c
+}
+`
+
+type cloneField struct {
+	Field string
+	Type  string
+	// Copy is "slice" or "pointer" for the fields the struct copy would
+	// share with the original, "belongs" or "hasmany" for the
+	// -associations fields, whose models are cloned in turn.
+	Copy string
+}
+
+func genClone(pkg string, ddl *sqlparser.DDL) string {
+	var fields []cloneField
+	var types []string
+	for _, c := range ddl.TableSpec.Columns {
+		col := newDALColumn(c)
+		switch {
+		case strings.HasPrefix(col.Type, "*"):
+			fields = append(fields, cloneField{Field: col.Field, Type: col.Type, Copy: "pointer"})
+		case strings.HasPrefix(col.Kind, "[]") || col.Kind == "json.RawMessage" || col.Kind == "IP" || col.Kind == "MAC":
+			fields = append(fields, cloneField{Field: col.Field, Type: col.Type, Copy: "slice"})
+			types = append(types, col.Type)
+		}
+	}
+	associations := false
+	for _, a := range tableAssociations[ddl] {
+		if a.Field == "" {
+			continue
+		}
+		associations = true
+		if strings.HasPrefix(a.Type, "*") {
+			fields = append(fields, cloneField{Field: a.Field, Type: a.Type, Copy: "belongs"})
+		} else {
+			fields = append(fields, cloneField{Field: a.Field, Type: a.Type, Copy: "hasmany"})
+		}
+	}
+
+	params := struct {
+		Package      string
+		TableName    string
+		Imports      string
+		Fields       []cloneField
+		Associations bool
+	}{
+		Package:      pkg,
+		TableName:    modelName(ddl.NewName.Name.String()),
+		Imports:      renderImports(importsOf(types...)),
+		Fields:       fields,
+		Associations: associations,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("clone").Parse(cloneTemplate)).Execute(&buf, params)
+	return buf.String()
+}
@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const cloneSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  nickname varchar(64),
+  age int,
+  avatar blob,
+  payload json,
+  born_at datetime,
+  created_at datetime NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+const cloneTest = `package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	nickname, age := "ann", 30
+	born, created := time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	orig := &Users{
+		ID:        1,
+		Email:     "a@example.com",
+		Nickname:  &nickname,
+		Age:       &age,
+		Avatar:    []byte{1, 2, 3},
+		Payload:   json.RawMessage(` + "`" + `{"a":1}` + "`" + `),
+		BornAt:    &born,
+		CreatedAt: created,
+	}
+	want := &Users{
+		ID:        1,
+		Email:     "a@example.com",
+		Nickname:  &nickname,
+		Age:       &age,
+		Avatar:    []byte{1, 2, 3},
+		Payload:   json.RawMessage(` + "`" + `{"a":1}` + "`" + `),
+		BornAt:    &born,
+		CreatedAt: created,
+	}
+	c := orig.Clone()
+	if !reflect.DeepEqual(c, orig) {
+		t.Fatalf("clone %+v differs from %+v", c, orig)
+	}
+
+	c.ID = 2
+	c.Email = "b@example.com"
+	*c.Nickname = "bob"
+	*c.Age = 40
+	c.Avatar[0] = 9
+	c.Payload[2] = 'b'
+	*c.BornAt = born.AddDate(1, 0, 0)
+	c.CreatedAt = created.AddDate(1, 0, 0)
+	// want shares the pointers too, so check what they point to
+	if nickname != "ann" || age != 30 || born.Year() != 1990 {
+		t.Errorf("the clone shares the pointers of the original")
+	}
+	if !reflect.DeepEqual(orig, want) {
+		t.Errorf("the original changed with the clone: %+v", orig)
+	}
+}
+
+func TestCloneNil(t *testing.T) {
+	var m *Users
+	if m.Clone() != nil {
+		t.Error("nil cloned to non-nil")
+	}
+	c := (&Users{}).Clone()
+	if c.Nickname != nil || c.Avatar != nil || c.Payload != nil {
+		t.Errorf("nil fields cloned to %+v", c)
+	}
+}
+`
+
+func TestClone(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", cloneSchema)
+	if err := run("-gen-clone", "-nullable-pointers", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	clone := gofmt(t, readFile(t, "model/users_clone.go"))
+	// the value fields are copied with the struct
+	for _, unwanted := range []string{"c.ID", "c.Email", "c.CreatedAt"} {
+		if strings.Contains(clone, unwanted) {
+			t.Errorf("users_clone.go copies %s again:\n%s", unwanted, clone)
+		}
+	}
+	writeFile(t, "model/clone_test.go", cloneTest)
+	goTest(t, "./model")
+}
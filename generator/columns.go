@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"bytes"
+	"sort"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const columnMapsTemplate = `
+// Column names of {{.TableNameStr}}.
+const (
+{{- range .Columns}}
+	{{.Const}} = "{{.Name}}"
+{{- end}}
+)
+
+// {{.TableName}}FieldToColumn maps the Go field names of {{.TableName}} to their columns.
+var {{.TableName}}FieldToColumn = map[string]string{
+{{- range .ByField}}
+	"{{.Field}}": {{.Const}},
+{{- end}}
+}
+
+// {{.TableName}}ColumnToField maps the columns of {{.TableNameStr}} to their Go field names.
+var {{.TableName}}ColumnToField = map[string]string{
+{{- range .ByName}}
+	{{.Const}}: "{{.Field}}",
+{{- end}}
+}
+
+// {{.TableName}}ColumnSet holds the columns of {{.TableNameStr}}, e.g. to
+// validate user supplied sort or filter columns.
+var {{.TableName}}ColumnSet = map[string]struct{}{
+{{- range .ByName}}
+	{{.Const}}: {},
+{{- end}}
+}
+`
+
+type columnConst struct {
+	Name  string
+	Field string
+	Const string
+}
+
+// genColumnMaps renders the column name constants of a table and the maps
+// between its field names and columns, with sorted entries so that the
+// output is stable.
+func genColumnMaps(ddl *sqlparser.DDL) string {
+	tableNameStr := ddl.NewName.Name.String()
+	tableName := modelName(tableNameStr)
+
+	used := map[string]bool{
+		tableName + "ColumnSet":     true,
+		tableName + "ColumnToField": true,
+	}
+	cols := make([]columnConst, 0, len(ddl.TableSpec.Columns))
+	for _, c := range ddl.TableSpec.Columns {
+		col := newDALColumn(c)
+		name := tableName + "Column" + col.Field
+		for used[name] {
+			name += "Column"
+		}
+		used[name] = true
+		cols = append(cols, columnConst{Name: col.Name, Field: col.Field, Const: name})
+	}
+	byField := append([]columnConst(nil), cols...)
+	sort.Slice(byField, func(i, j int) bool { return byField[i].Field < byField[j].Field })
+	byName := append([]columnConst(nil), cols...)
+	sort.Slice(byName, func(i, j int) bool { return byName[i].Name < byName[j].Name })
+
+	params := struct {
+		TableName    string
+		TableNameStr string
+		Columns      []columnConst
+		ByField      []columnConst
+		ByName       []columnConst
+	}{
+		TableName:    tableName,
+		TableNameStr: tableNameStr,
+		Columns:      cols,
+		ByField:      byField,
+		ByName:       byName,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("columns").Parse(columnMapsTemplate)).Execute(&buf, params)
+	return buf.String()
+}
@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const columnsSchema = "CREATE TABLE `users` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `email` varchar(255) NOT NULL,\n" +
+	"  `set` varchar(16) NOT NULL,\n" +
+	"  `created_at` datetime NOT NULL,\n" +
+	"  `avatar` blob,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");"
+
+const columnsTest = `package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// column returns the column of the gorm tag of a field.
+func column(f reflect.StructField) string {
+	for _, part := range strings.Split(f.Tag.Get("gorm"), ";") {
+		if strings.HasPrefix(part, "Column:") {
+			return strings.TrimPrefix(part, "Column:")
+		}
+	}
+	return ""
+}
+
+func TestColumnMaps(t *testing.T) {
+	typ := reflect.TypeOf(Users{})
+	if len(UsersFieldToColumn) != typ.NumField() {
+		t.Errorf("UsersFieldToColumn has %d entries for %d fields", len(UsersFieldToColumn), typ.NumField())
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		want := column(f)
+		if want == "" {
+			t.Fatalf("%s has no column", f.Name)
+		}
+		if got, ok := UsersFieldToColumn[f.Name]; !ok || got != want {
+			t.Errorf("UsersFieldToColumn[%s] = %q, want %q", f.Name, got, want)
+		}
+		if got, ok := UsersColumnToField[want]; !ok || got != f.Name {
+			t.Errorf("UsersColumnToField[%s] = %q, want %q", want, got, f.Name)
+		}
+		if _, ok := UsersColumnSet[want]; !ok {
+			t.Errorf("%s not in UsersColumnSet", want)
+		}
+	}
+	if len(UsersColumnToField) != typ.NumField() || len(UsersColumnSet) != typ.NumField() {
+		t.Errorf("%d and %d columns for %d fields", len(UsersColumnToField), len(UsersColumnSet), typ.NumField())
+	}
+	if UsersColumnSetColumn != "set" || UsersColumnCreatedAt != "created_at" {
+		t.Errorf("got %s and %s", UsersColumnSetColumn, UsersColumnCreatedAt)
+	}
+}
+`
+
+func TestColumnMaps(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", columnsSchema)
+	if err := run("schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	users := gofmt(t, readFile(t, modelPath("users")))
+	// the entries are sorted by field name and by column
+	for _, want := range []string{
+		"var UsersFieldToColumn = map[string]string{\n" +
+			"\t\"Avatar\":    UsersColumnAvatar,\n" +
+			"\t\"CreatedAt\": UsersColumnCreatedAt,\n" +
+			"\t\"Email\":     UsersColumnEmail,\n" +
+			"\t\"ID\":        UsersColumnID,\n" +
+			"\t\"Set\":       UsersColumnSetColumn,\n" +
+			"}",
+		"var UsersColumnSet = map[string]struct{}{\n" +
+			"\tUsersColumnAvatar:    {},\n" +
+			"\tUsersColumnCreatedAt: {},\n" +
+			"\tUsersColumnEmail:     {},\n" +
+			"\tUsersColumnID:        {},\n" +
+			"\tUsersColumnSetColumn: {},\n" +
+			"}",
+	} {
+		if !strings.Contains(users, want) {
+			t.Errorf("users.go lacks\n%s\nin:\n%s", want, users)
+		}
+	}
+	writeFile(t, "model/columns_test.go", columnsTest)
+	goTest(t, "./model")
+}
+
+func TestNoColumnMaps(t *testing.T) {
+	users := generate(t, columnsSchema, "users", "-no-column-maps")
+	for _, unwanted := range []string{"UsersColumnID", "UsersFieldToColumn", "UsersColumnSet"} {
+		if strings.Contains(users, unwanted) {
+			t.Errorf("%s generated with -no-column-maps:\n%s", unwanted, users)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// commentPrefix matches the -trim-comment-prefix tag of column comments,
+// nil without the flag.
+var commentPrefix *regexp.Regexp
+
+// parseCommentPrefix compiles the -trim-comment-prefix expression, anchored
+// at the start of the comment and eating the spaces after the tag.
+func parseCommentPrefix(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	if _, err := regexp.Compile(expr); err != nil {
+		return nil, fmt.Errorf("-trim-comment-prefix %q: %v", expr, err)
+	}
+	return regexp.MustCompile(`^\s*(?:` + expr + `)\s*`), nil
+}
+
+// columnComment returns the comment of a column as written into the
+// generated code, without its -trim-comment-prefix tag. The annotations
+// of the comment are read from getComment, tag included.
+func columnComment(c *sqlparser.ColumnDefinition) string {
+	comment := getComment(c)
+	if commentPrefix == nil {
+		return comment
+	}
+	if loc := commentPrefix.FindStringIndex(comment); loc != nil && loc[1] > 0 {
+		return strings.TrimSpace(comment[loc[1]:])
+	}
+	return comment
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const piiSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL COMMENT '[PII] user email',
+  phone varchar(32) COMMENT '  [pii]   phone number',
+  note varchar(32) COMMENT 'a [PII] note',
+  tag varchar(32) COMMENT '[PII]',
+  PRIMARY KEY (id)
+);`
+
+func TestTrimCommentPrefix(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", piiSchema)
+	if err := run("-trim-comment-prefix", `(?i)\[PII\]`, "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	users := gofmt(t, readFile(t, modelPath("users")))
+	for _, want := range []string{
+		"` // user email\n",
+		"`  // phone number\n",
+		// only a leading tag is stripped
+		"`    // a [PII] note\n",
+		"json:\"tag\"`\n",
+	} {
+		if !strings.Contains(users, want) {
+			t.Errorf("no %q in:\n%s", want, users)
+		}
+	}
+
+	// the intermediate representation keeps the comments as they are
+	ir := string(emitIR(t, "schema.sql"))
+	for _, want := range []string{`"comment": "[PII] user email"`, `"comment": "  [pii]   phone number"`} {
+		if !strings.Contains(ir, want) {
+			t.Errorf("no %s in:\n%s", want, ir)
+		}
+	}
+}
+
+func TestTrimCommentPrefixOff(t *testing.T) {
+	users := generate(t, piiSchema, "users")
+	if !strings.Contains(users, "// [PII] user email\n") {
+		t.Errorf("comment trimmed without -trim-comment-prefix:\n%s", users)
+	}
+}
+
+func TestTrimCommentPrefixBadExpr(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", piiSchema)
+	err := run("-trim-comment-prefix", "[PII", "schema.sql")
+	if want := "-trim-comment-prefix \"[PII\": error parsing regexp"; err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("got %v, want %s", err, want)
+	}
+}
@@ -0,0 +1,161 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const compareTemplate = `
+package {{.Package}}
+
+{{.Imports}}
+{{- if .Associations}}
+// Equal reports whether m and o have equal columns and associations.
+{{- end}}
+func (m {{.TableName}}) Equal(o {{.TableName}}) bool {
+{{- range .Fields}}
+	if {{differ .}} {
+		return false
+	}
+{{- end}}
+{{- range .BelongsTo}}
+	if (m.{{.}} == nil) != (o.{{.}} == nil) || m.{{.}} != nil && !m.{{.}}.Equal(*o.{{.}}) {
+		return false
+	}
+{{- end}}
+{{- range .HasMany}}
+	if len(m.{{.}}) != len(o.{{.}}) {
+		return false
+	}
+	for i := range m.{{.}} {
+		if !m.{{.}}[i].Equal(o.{{.}}[i]) {
+			return false
+		}
+	}
+{{- end}}
+	return true
+}
+
+func (m {{.TableName}}) IsZero() bool {
+{{- range .Fields}}
+	if {{nonzero .}} {
+		return false
+	}
+{{- end}}
+{{- range .BelongsTo}}
+	if m.{{.}} != nil {
+		return false
+	}
+{{- end}}
+{{- range .HasMany}}
+	if len(m.{{.}}) != 0 {
+		return false
+	}
+{{- end}}
+	return true
+}
+`
+
+var compareFuncs = template.FuncMap{
+	"differ": func(c dalColumn) string {
+		a, b := "m."+c.Field, "o."+c.Field
+		if strings.HasPrefix(c.Kind, "*") {
+			// parenthesized for the method call of time.Time
+			return "(" + a + " == nil) != (" + b + " == nil) || " + a + " != nil && " +
+				differExpr(c.Kind[1:], "(*"+a+")", "(*"+b+")")
+		}
+		return differExpr(c.Kind, a, b)
+	},
+	"nonzero": func(c dalColumn) string {
+		f := "m." + c.Field
+		switch {
+		case strings.HasPrefix(c.Kind, "*"):
+			return f + " != nil"
+		case c.Kind == "time.Time":
+			return "!" + f + ".IsZero()"
+		case strings.HasPrefix(c.Kind, "[]"):
+			return "len(" + f + ") != 0"
+		case c.Kind == "string":
+			return f + ` != ""`
+		case c.Kind == "bool":
+			return f
+		case numericType(c.Kind):
+			return f + " != 0"
+		default:
+			return "!reflect.ValueOf(" + f + ").IsZero()"
+		}
+	},
+}
+
+func numericType(typ string) bool {
+	switch typ {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return true
+	}
+	return false
+}
+
+func differExpr(typ, a, b string) string {
+	switch {
+	case typ == "time.Time":
+		return "!" + a + ".Equal(" + b + ")"
+	case typ == "[]byte":
+		return "!bytes.Equal(" + a + ", " + b + ")"
+	case typ == "string" || typ == "bool" || numericType(typ):
+		return a + " != " + b
+	default:
+		return "!reflect.DeepEqual(" + a + ", " + b + ")"
+	}
+}
+
+func genCompare(pkg string, ddl *sqlparser.DDL) string {
+	var fields []dalColumn
+	var imports []string
+	for _, c := range ddl.TableSpec.Columns {
+		f := newDALColumn(c)
+		kind := strings.TrimPrefix(f.Kind, "*")
+		switch {
+		case kind == "[]byte":
+			imports = append(imports, "bytes")
+		case kind != "time.Time" && kind != "string" && kind != "bool" && !numericType(kind):
+			imports = append(imports, "reflect")
+		}
+		fields = append(fields, f)
+	}
+	// the -associations fields compare their models in turn
+	var belongsTo, hasMany []string
+	for _, a := range tableAssociations[ddl] {
+		switch {
+		case a.Field == "":
+		case strings.HasPrefix(a.Type, "*"):
+			belongsTo = append(belongsTo, a.Field)
+		default:
+			hasMany = append(hasMany, a.Field)
+		}
+	}
+
+	params := struct {
+		Package      string
+		TableName    string
+		Imports      string
+		Fields       []dalColumn
+		BelongsTo    []string
+		HasMany      []string
+		Associations bool
+	}{
+		Package:      pkg,
+		TableName:    modelName(ddl.NewName.Name.String()),
+		Imports:      renderImports(uniqueSorted(imports)),
+		Fields:       fields,
+		BelongsTo:    belongsTo,
+		HasMany:      hasMany,
+		Associations: len(belongsTo)+len(hasMany) > 0,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("compare").Funcs(compareFuncs).Parse(compareTemplate)).Execute(&buf, params)
+	return buf.String()
+}
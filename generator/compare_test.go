@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const compareTest = `package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEqual(t *testing.T) {
+	utc := time.Date(1990, 1, 2, 3, 4, 5, 0, time.UTC)
+	// the same instant elsewhere, with a monotonic reading
+	local := utc.In(time.FixedZone("CET", 3600))
+	now := time.Now()
+	for _, tt := range []struct {
+		name string
+		a, b Users
+		want bool
+	}{
+		{"zero", Users{}, Users{}, true},
+		{"same", Users{ID: 1, Email: "a", Age: 3}, Users{ID: 1, Email: "a", Age: 3}, true},
+		{"string", Users{Email: "a"}, Users{Email: "b"}, false},
+		{"int", Users{Age: 1}, Users{Age: 2}, false},
+		{"time zone", Users{BornAt: utc}, Users{BornAt: local}, true},
+		{"monotonic clock", Users{BornAt: now}, Users{BornAt: now.Round(0)}, true},
+		{"time", Users{BornAt: utc}, Users{BornAt: utc.Add(time.Second)}, false},
+		{"nil and empty blob", Users{Avatar: nil}, Users{Avatar: []byte{}}, true},
+		{"blob", Users{Avatar: []byte{1}}, Users{Avatar: []byte{2}}, false},
+	} {
+		if got := tt.a.Equal(tt.b); got != tt.want {
+			t.Errorf("%s: Equal = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := tt.b.Equal(tt.a); got != tt.want {
+			t.Errorf("%s: reversed Equal = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsZero(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		m    Users
+		want bool
+	}{
+		{"zero", Users{}, true},
+		{"empty blob", Users{Avatar: []byte{}}, true},
+		{"int", Users{Age: 1}, false},
+		{"string", Users{Email: "a"}, false},
+		{"time", Users{BornAt: time.Unix(0, 0)}, false},
+		{"blob", Users{Avatar: []byte{0}}, false},
+	} {
+		if got := tt.m.IsZero(); got != tt.want {
+			t.Errorf("%s: IsZero = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+`
+
+const comparePointersTest = `package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEqualPointers(t *testing.T) {
+	a, b, c := "ann", "ann", "bob"
+	utc := time.Date(1990, 1, 2, 3, 4, 5, 0, time.UTC)
+	local := utc.In(time.FixedZone("CET", 3600))
+	later := utc.Add(time.Second)
+	for _, tt := range []struct {
+		name string
+		a, b Users
+		want bool
+	}{
+		{"both nil", Users{}, Users{}, true},
+		{"nil and set", Users{}, Users{Nickname: &a}, false},
+		{"equal values", Users{Nickname: &a}, Users{Nickname: &b}, true},
+		{"values", Users{Nickname: &a}, Users{Nickname: &c}, false},
+		{"time zone", Users{BornAt: &utc}, Users{BornAt: &local}, true},
+		{"time", Users{BornAt: &utc}, Users{BornAt: &later}, false},
+		{"nil and set time", Users{BornAt: &utc}, Users{}, false},
+	} {
+		if got := tt.a.Equal(tt.b); got != tt.want {
+			t.Errorf("%s: Equal = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := tt.b.Equal(tt.a); got != tt.want {
+			t.Errorf("%s: reversed Equal = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	empty := ""
+	if (Users{Nickname: &empty}).IsZero() {
+		t.Error("a pointer to an empty string is zero")
+	}
+	if !(Users{}).IsZero() {
+		t.Error("the zero value is not zero")
+	}
+}
+`
+
+func TestCompare(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", nullableSchema)
+	if err := run("-gen-compare", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	compare := gofmt(t, readFile(t, "model/users_compare.go"))
+	for _, want := range []string{"!m.BornAt.Equal(o.BornAt)", "!bytes.Equal(m.Avatar, o.Avatar)"} {
+		if !strings.Contains(compare, want) {
+			t.Errorf("no %q in:\n%s", want, compare)
+		}
+	}
+	writeFile(t, "model/compare_test.go", compareTest)
+	goTest(t, "./model")
+
+	chdir(t)
+	writeFile(t, "schema.sql", nullableSchema)
+	if err := run("-gen-compare", "-nullable-pointers", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, "model/compare_test.go", comparePointersTest)
+	goTest(t, "./model")
+}
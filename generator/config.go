@@ -0,0 +1,333 @@
+package generator
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+	"gopkg.in/yaml.v3"
+)
+
+// The -config file sets the flags by name, the comma separated ones as
+// lists, and overrides the columns of the tables:
+//
+//	output: internal/dal
+//	dal: true
+//	exclude_tables: [schema_migrations, goose_db_version]
+//	schema:
+//	  - schema/users.sql
+//	  - schema/orders.sql
+//	tables:
+//	  users:
+//	    columns:
+//	      usr_nm:
+//	        rename: UserName
+//
+// A table may also set its package, be skipped, turn its DAO on or off and
+// exclude columns from JSON:
+//
+//	tables:
+//	  users:
+//	    package: identity
+//	  audit_log:
+//	    skip: true
+//	  payments:
+//	    dal: false
+//	    json_exclude: [card_number]
+//
+// A dash of a flag name may be written as an underscore. The flags given on
+// the command line win over the file, and the -type-map file wins over its
+// column overrides. The settings of a table win over the global ones.
+
+var (
+	configFile string
+	// configSchema are the schema files of the config file, read when the
+	// command line names none.
+	configSchema []string
+	// configColumns are the column overrides of the config file by
+	// table.column, and configColumnLines where they are.
+	configColumns     = make(map[string]TypeOverride)
+	configColumnLines = make(map[string]string)
+)
+
+// configDoc is the config file. The flags are the keys it does not
+// know, checked against the flag set; the tables only take the keys of
+// configTableDoc, and their columns those of a TypeOverride.
+type configDoc struct {
+	Schema yaml.Node                 `yaml:"schema"`
+	Tables map[string]configTableDoc `yaml:"tables"`
+	Flags  map[string]yaml.Node      `yaml:",inline"`
+}
+
+type configTableDoc struct {
+	Package     *string                 `yaml:"package"`
+	Skip        bool                    `yaml:"skip"`
+	DAL         *bool                   `yaml:"dal"`
+	JSONExclude []string                `yaml:"json_exclude"`
+	Columns     map[string]TypeOverride `yaml:"columns"`
+}
+
+// loadConfig reads the -config file, setting the flags the command line
+// did not.
+func loadConfig(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var doc configDoc
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil && err != io.EOF {
+		return configDecodeError(file, err)
+	}
+	// the nodes give the keys in file order and their lines
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for _, kv := range yamlMapping(&root) {
+		key := kv[0].Value
+		at := fmt.Sprintf("%s:%d", file, kv[0].Line)
+		switch key {
+		case "tables":
+			if err := loadConfigTables(file, doc.Tables, kv[1]); err != nil {
+				return err
+			}
+			continue
+		case "schema":
+			if configSchema, err = configList(at, key, &doc.Schema); err != nil {
+				return err
+			}
+			continue
+		}
+		name := strings.ReplaceAll(key, "_", "-")
+		if flag.Lookup(name) == nil || name == "config" {
+			return fmt.Errorf("%s: unknown key %s", at, key)
+		}
+		n := doc.Flags[key]
+		values, err := configList(at, key, &n)
+		if err != nil {
+			return err
+		}
+		if set[name] {
+			continue
+		}
+		if err := flag.Set(name, strings.Join(values, ",")); err != nil {
+			return fmt.Errorf("%s: %s: %v", at, key, err)
+		}
+	}
+	return nil
+}
+
+// configDecodeError names the line of the first error of the config file,
+// reporting the keys yaml does not find in a table or a column as unknown
+// keys, as for the flags.
+func configDecodeError(file string, err error) error {
+	te, ok := err.(*yaml.TypeError)
+	if !ok || len(te.Errors) == 0 {
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	var line int
+	var key string
+	if n, _ := fmt.Sscanf(te.Errors[0], "line %d: field %s not found", &line, &key); n == 2 {
+		return fmt.Errorf("%s:%d: unknown key %s", file, line, key)
+	}
+	if msg := te.Errors[0]; strings.HasPrefix(msg, "line ") {
+		return fmt.Errorf("%s:%s", file, msg[len("line "):])
+	}
+	return fmt.Errorf("%s: %s", file, te.Errors[0])
+}
+
+// yamlMapping returns the key and value nodes of a mapping node, or of the
+// mapping of a document node, in file order.
+func yamlMapping(n *yaml.Node) [][2]*yaml.Node {
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	var kvs [][2]*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		kvs = append(kvs, [2]*yaml.Node{n.Content[i], n.Content[i+1]})
+	}
+	return kvs
+}
+
+// configList returns the values of a scalar or list node.
+func configList(at, key string, n *yaml.Node) ([]string, error) {
+	switch n.Kind {
+	case 0:
+		return nil, nil
+	case yaml.ScalarNode:
+		return []string{n.Value}, nil
+	case yaml.SequenceNode:
+		var values []string
+		for _, item := range n.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("%s: the items of %s must be values", at, key)
+			}
+			values = append(values, item.Value)
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("%s: %s takes a value or a list, not a mapping", at, key)
+}
+
+// configTable holds the settings of a table of the config file, a nil one
+// leaving the global setting alone.
+type configTable struct {
+	at          string
+	Package     *string
+	Skip        bool
+	DAL         *bool
+	JSONExclude []string
+}
+
+var (
+	// configTables are the tables of the config file, in configTableNames
+	// order.
+	configTables     = make(map[string]*configTable)
+	configTableNames []string
+)
+
+// loadConfigTables records the tables of the config file, the node of
+// tables giving their order and lines.
+func loadConfigTables(file string, tables map[string]configTableDoc, node *yaml.Node) error {
+	for _, kv := range yamlMapping(node) {
+		table := kv[0].Value
+		t := tables[table]
+		at := fmt.Sprintf("%s:%d", file, kv[0].Line)
+		if t.Package != nil && !token.IsIdentifier(*t.Package) {
+			return fmt.Errorf("%s: package %q of table %s is not a Go identifier", at, *t.Package, table)
+		}
+		for _, field := range yamlMapping(kv[1]) {
+			if field[0].Value != "columns" {
+				continue
+			}
+			for _, c := range yamlMapping(field[1]) {
+				at := fmt.Sprintf("%s:%d", file, c[0].Line)
+				if err := loadConfigColumn(at, table, c[0].Value, t.Columns[c[0].Value]); err != nil {
+					return err
+				}
+			}
+		}
+		configTables[table] = &configTable{at: at, Package: t.Package, Skip: t.Skip, DAL: t.DAL, JSONExclude: t.JSONExclude}
+		configTableNames = append(configTableNames, table)
+	}
+	return nil
+}
+
+// loadConfigColumn records the column override of table.column, written
+// at at.
+func loadConfigColumn(at, table, column string, o TypeOverride) error {
+	if o.Rename != "" && (!token.IsIdentifier(o.Rename) || !token.IsExported(o.Rename)) {
+		return fmt.Errorf("%s: column %s.%s: rename %q is not an exported Go identifier", at, table, column, o.Rename)
+	}
+	configColumns[table+"."+column] = o
+	configColumnLines[table+"."+column] = at
+	return nil
+}
+
+// mergeConfigColumns adds the column overrides of the config file to the
+// type map, leaving those of the -type-map file alone.
+func mergeConfigColumns() {
+	if len(configColumns) == 0 {
+		return
+	}
+	if typeMap.Columns == nil {
+		typeMap.Columns = make(map[string]TypeOverride)
+	}
+	for key, o := range configColumns {
+		if _, ok := typeMap.Columns[key]; ok {
+			delete(configColumnLines, key)
+		} else {
+			typeMap.Columns[key] = o
+		}
+	}
+}
+
+// typeMapSource names where the column override of key comes from, for
+// the errors of applyTypeMap: the line of the config file or the -type-map
+// file.
+func typeMapSource(key string) string {
+	if at, ok := configColumnLines[key]; ok {
+		return at
+	}
+	return "type map " + typeMapFile
+}
+
+// tableSettings are the effective settings of a table, the global ones
+// overridden by those of its config table.
+type tableSettings struct {
+	Package     string
+	DAL         bool
+	JSONExclude []string
+}
+
+func effectiveSettings(table, pkg string) tableSettings {
+	s := tableSettings{Package: groupPackage(tableGroup(table), pkg), DAL: genDAO()}
+	for _, e := range strings.Split(jsonExclude, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			if i := strings.LastIndex(e, "."); i < 0 || e[:i] == table {
+				s.JSONExclude = append(s.JSONExclude, e[i+1:])
+			}
+		}
+	}
+	if t := configTables[table]; t != nil {
+		if t.DAL != nil {
+			s.DAL = *t.DAL
+		}
+		s.JSONExclude = append(s.JSONExclude, t.JSONExclude...)
+	}
+	return s
+}
+
+// tableDAO reports whether the DAO of a table is generated, by the flags or
+// the dal setting of its config table.
+func tableDAO(table string) bool {
+	if t := configTables[table]; t != nil && t.DAL != nil {
+		return *t.DAL
+	}
+	return genDAO()
+}
+
+// configSkipped reports whether the config file skips a table.
+func configSkipped(table string) bool {
+	t := configTables[table]
+	return t != nil && t.Skip
+}
+
+// warnConfigTables warns about the tables of the config file the schema
+// does not have.
+func warnConfigTables(ddls []*sqlparser.DDL) {
+	tables := make(map[string]bool)
+	for _, ddl := range ddls {
+		tables[ddl.NewName.Name.String()] = true
+	}
+	for _, table := range configTableNames {
+		if !tables[table] {
+			fmt.Fprintf(os.Stderr, "warning: %s: table %s is not in the schema\n", configTables[table].at, table)
+		}
+	}
+}
+
+// printSettings writes the effective settings of every table, for
+// -verbose.
+func printSettings(ddls []*sqlparser.DDL, pkg string) {
+	for _, ddl := range ddls {
+		table := ddl.NewName.Name.String()
+		s := effectiveSettings(table, pkg)
+		fmt.Fprintf(os.Stderr, "table %s: package %s, dal %t, json_exclude [%s]\n", table, s.Package, s.DAL, strings.Join(s.JSONExclude, ", "))
+	}
+}
@@ -0,0 +1,336 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const configTestSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  name varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE TABLE posts (
+  id bigint NOT NULL AUTO_INCREMENT,
+  title varchar(255) NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+// testdataPath returns the absolute path of a testdata file, for the tests
+// running in a directory of their own.
+func testdataPath(t *testing.T, name string) string {
+	t.Helper()
+	path, err := filepath.Abs(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	config := testdataPath(t, "config/dalgen.yaml")
+	types := testdataPath(t, "config/types.json")
+	chdir(t)
+	writeFile(t, "schema.sql", configTestSchema)
+	if err := run("-config", config, "-type-map", types, "-database", "cli"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the command line wins over the config file
+	if databaseName != "cli" {
+		t.Errorf("database = %q, want cli", databaseName)
+	}
+	users := readFile(t, modelPath("users"))
+	// the flags the command line does not give come from the config file
+	if !strings.Contains(users, `return "app_users"`) {
+		t.Errorf("table_prefix of the config file not applied:\n%s", users)
+	}
+	// the -type-map file wins over the column overrides of the config file
+	if !strings.Contains(users, "sql.NullString") || strings.Contains(users, "[]byte") {
+		t.Errorf("users.email is not the sql.NullString of the type map:\n%s", users)
+	}
+	if !strings.Contains(users, "FullName") {
+		t.Errorf("users.name not renamed FullName:\n%s", users)
+	}
+
+	// the settings of a table win over the global ones
+	if _, err := os.Stat(filepath.Join("cli", "users_dal.go")); err == nil {
+		t.Error("users_dal.go generated, the users table sets dal: false")
+	}
+	if _, err := os.Stat(filepath.Join("cli", "posts_dal.go")); err != nil {
+		t.Errorf("posts_dal.go not generated with the global dal: true: %v", err)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	for _, tt := range []struct {
+		file string
+		want string
+	}{
+		{"unknown_key.yaml", "unknown_key.yaml:2: unknown key colour"},
+		{"unknown_table_key.yaml", "unknown_table_key.yaml:3: unknown key colour"},
+		{"unknown_column_key.yaml", "unknown_column_key.yaml:5: unknown key colour"},
+		{"missing_column.yaml", "missing_column.yaml:4: table users has no column phone"},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			config := testdataPath(t, "config/"+tt.file)
+			chdir(t)
+			writeFile(t, "schema.sql", configTestSchema)
+			err := run("-config", config, "schema.sql")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+const legacySchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  usr_nm varchar(64) NOT NULL,
+  del_flg tinyint NOT NULL DEFAULT 0,
+  raw_payload longblob,
+  PRIMARY KEY (id)
+);`
+
+const legacyConfig = `tables:
+  users:
+    columns:
+      usr_nm:
+        rename: UserName
+      del_flg:
+        rename: Deleted
+      raw_payload:
+        skip: true
+`
+
+const legacyTest = `package model
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestLegacyColumns(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("CREATE TABLE users (id integer PRIMARY KEY AUTOINCREMENT, usr_nm text NOT NULL, del_flg integer NOT NULL DEFAULT 0, raw_payload blob)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&Users{UserName: "ann", Deleted: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var got Users
+	if err := db.Where("usr_nm = ?", "ann").First(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.UserName != "ann" || got.Deleted != 1 {
+		t.Errorf("got %+v", got)
+	}
+}
+`
+
+func TestConfigColumns(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", legacySchema)
+	writeFile(t, "dalgen.yaml", legacyConfig)
+	if err := run("-config", "dalgen.yaml", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	users := gofmt(t, readFile(t, modelPath("users")))
+	// the tags keep the real column
+	for _, want := range []string{
+		"UserName string `gorm:\"Column:usr_nm;size:64\" json:\"usr_nm\"`",
+		"Deleted  int    `gorm:\"Column:del_flg\" json:\"del_flg\"`",
+	} {
+		if !strings.Contains(users, want) {
+			t.Errorf("no %s in:\n%s", want, users)
+		}
+	}
+	if strings.Contains(users, "raw_payload") || strings.Contains(users, "RawPayload") {
+		t.Errorf("raw_payload not skipped:\n%s", users)
+	}
+	writeFile(t, "model/legacy_test.go", legacyTest)
+	goTest(t, "./model")
+}
+
+func TestConfigColumnErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		column string
+		want   string
+	}{
+		{"missing", "usr_name:\n        rename: UserName", "dalgen.yaml:4: table users has no column usr_name"},
+		{"space", "usr_nm:\n        rename: User Name", `dalgen.yaml:4: column users.usr_nm: rename "User Name" is not an exported Go identifier`},
+		{"unexported", "usr_nm:\n        rename: userName", `dalgen.yaml:4: column users.usr_nm: rename "userName" is not an exported Go identifier`},
+		{"collision", "usr_nm:\n        rename: DelFlg", "table users: rename DelFlg of column usr_nm collides with the field of column del_flg"},
+		{"method", "usr_nm:\n        rename: TableName", "table users: rename TableName of column usr_nm collides with the TableName method"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t)
+			writeFile(t, "schema.sql", legacySchema)
+			writeFile(t, "dalgen.yaml", "tables:\n  users:\n    columns:\n      "+tt.column+"\n")
+			err := run("-config", "dalgen.yaml", "schema.sql")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+			if _, err := os.Stat(modelPath("users")); err == nil {
+				t.Error("users.go written despite the error")
+			}
+		})
+	}
+}
+
+const configTablesSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  note text,
+  PRIMARY KEY (id)
+);
+CREATE TABLE audit_log (
+  id bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (id)
+);
+CREATE TABLE orders (
+  id bigint NOT NULL AUTO_INCREMENT,
+  note text,
+  PRIMARY KEY (id)
+);
+CREATE TABLE payments (
+  id bigint NOT NULL AUTO_INCREMENT,
+  note text,
+  card_number varchar(19) NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+const configTablesYAML = `dal: true
+json_exclude: note
+tables:
+  users:
+    package: identity
+  audit_log:
+    skip: true
+  orders:
+    dal: false
+  payments:
+    json_exclude: [card_number]
+  ghosts:
+    dal: false
+`
+
+func TestConfigTables(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", configTablesSchema)
+	writeFile(t, "dalgen.yaml", configTablesYAML)
+	_, stderr, err := capture(t, func() error { return run("-config", "dalgen.yaml", "-verbose", "schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for fp, want := range map[string]bool{
+		"model/identity/users.go":     true,
+		"model/identity/users_dal.go": true,
+		"model/users.go":              false,
+		"model/audit_log.go":          false,
+		"model/audit_log_dal.go":      false,
+		"model/orders.go":             true,
+		"model/orders_dal.go":         false,
+		"model/payments.go":           true,
+		"model/payments_dal.go":       true,
+	} {
+		if _, err := os.Stat(fp); (err == nil) != want {
+			t.Errorf("%s generated: %v, want %v", fp, err == nil, want)
+		}
+	}
+	if users := readFile(t, "model/identity/users.go"); !strings.Contains(users, "package identity\n") {
+		t.Errorf("users.go is not in package identity:\n%s", users)
+	}
+	payments := gofmt(t, readFile(t, modelPath("payments")))
+	for _, want := range []string{
+		"Note       string `gorm:\"Column:note\" json:\"-\"`",
+		"CardNumber string `gorm:\"Column:card_number;size:19\" json:\"-\"`",
+	} {
+		if !strings.Contains(payments, want) {
+			t.Errorf("no %s in:\n%s", want, payments)
+		}
+	}
+
+	// the effective settings of every generated table, and a warning for
+	// the table the schema does not have
+	for _, want := range []string{
+		"warning: dalgen.yaml:12: table ghosts is not in the schema\n",
+		"table users: package identity, dal true, json_exclude [note]\n",
+		"table orders: package model, dal false, json_exclude [note]\n",
+		"table payments: package model, dal true, json_exclude [note, card_number]\n",
+	} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("no %q in:\n%s", want, stderr)
+		}
+	}
+	if strings.Contains(stderr, "table audit_log:") {
+		t.Errorf("the skipped audit_log printed:\n%s", stderr)
+	}
+	goTest(t, "./model/...")
+}
+
+// TestConfigTableLayering checks that the settings of a table are layered
+// over the global ones, whether from the config file or the command line.
+func TestConfigTableLayering(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		config string
+		args   []string
+		want   []string
+	}{
+		{
+			"table over config",
+			"dal: false\njson_exclude: note\ntables:\n  payments:\n    dal: true\n    json_exclude: [card_number]\n",
+			nil,
+			[]string{
+				"table users: package model, dal false, json_exclude [note]\n",
+				"table payments: package model, dal true, json_exclude [note, card_number]\n",
+			},
+		},
+		{
+			"table over command line",
+			"dal: false\njson_exclude: note\ntables:\n  users:\n    dal: false\n    package: identity\n",
+			[]string{"-dal", "-json-exclude", "payments.card_number", "-group-by-prefix", "users"},
+			[]string{
+				"table users: package identity, dal false, json_exclude []\n",
+				"table payments: package model, dal true, json_exclude [card_number]\n",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t)
+			writeFile(t, "schema.sql", configTablesSchema)
+			writeFile(t, "dalgen.yaml", tt.config)
+			args := append([]string{"-config", "dalgen.yaml", "-verbose", "-exclude-tables", "audit_log,orders"}, tt.args...)
+			_, stderr, err := capture(t, func() error { return run(append(args, "schema.sql")...) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(stderr, want) {
+					t.Errorf("no %q in:\n%s", want, stderr)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigTableErrors(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", configTablesSchema)
+	writeFile(t, "dalgen.yaml", "tables:\n  users:\n    package: identity-v2\n")
+	if err := run("-config", "dalgen.yaml", "schema.sql"); err == nil || err.Error() != `dalgen.yaml:2: package "identity-v2" of table users is not a Go identifier` {
+		t.Errorf("got %v", err)
+	}
+	if _, err := os.Stat("model"); !os.IsNotExist(err) {
+		t.Errorf("model written: %v", err)
+	}
+}
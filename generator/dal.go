@@ -0,0 +1,530 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"strings"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const dalTemplate = `
+package {{.Package}}
+
+import (
+	"context"
+{{- range std .Imports}}
+	"{{.}}"
+{{- end}}
+{{range thirdParty .Imports}}
+	"{{.}}"
+{{- end}}
+	"gorm.io/gorm"
+{{- if or (not .ReadOnly) .PK .Uniques}}
+	"gorm.io/gorm/clause"
+{{- end}}
+)
+
+// {{.TableName}}RepositoryIface covers every method of {{.DAO}}, so services
+// can depend on it and tests can mock it.
+type {{.TableName}}RepositoryIface interface {
+{{- range .Methods}}
+	{{.Name}}({{.Params}}) {{.Results}}
+{{- end}}
+}
+
+var _ {{.TableName}}RepositoryIface = (*{{.DAO}})(nil)
+
+// {{.DAO}}Iface is {{.TableName}}RepositoryIface under the name the cached
+// and traced decorators implement.
+type {{.DAO}}Iface = {{.TableName}}RepositoryIface
+
+type {{.DAO}} struct {
+	db *gorm.DB
+}
+
+func New{{.DAO}}(db *gorm.DB) *{{.DAO}} {
+	return &{{.DAO}}{db: db}
+}
+{{- if .Shard}}
+
+// conn routes to the {{.TableNameStr}} shard of shardKey.
+func (d *{{.DAO}}) conn(ctx context.Context, shardKey int64) *gorm.DB {
+	return d.db.WithContext(ctx).Table({{.TableName}}{}.ShardedTableName(shardKey))
+}
+{{- else}}
+
+func (d *{{.DAO}}) conn(ctx context.Context) *gorm.DB {
+	return d.db.WithContext(ctx)
+}
+{{- end}}
+
+{{- if .PK}}
+
+func (d *{{.DAO}}) GetByID(ctx context.Context{{.ShardParam}}{{range .PK}}, {{.Param}} {{.Type}}{{end}}) (*{{.TableName}}, error) {
+	var m {{.TableName}}
+	err := d.conn(ctx{{$.ShardArg}}).Where({{where .PK ""}}).First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+{{- end}}
+{{- range .Uniques}}
+
+func (d *{{$.DAO}}) {{.Method}}(ctx context.Context{{$.ShardParam}}{{range .Columns}}, {{.Param}} {{.Type}}{{end}}) (*{{$.TableName}}, error) {
+	var m {{$.TableName}}
+	err := d.conn(ctx{{$.ShardArg}}).Where({{where .Columns ""}}).First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+{{- end}}
+
+func (d *{{.DAO}}) List(ctx context.Context{{.ShardParam}}, limit, offset int, scopes ...func(*gorm.DB) *gorm.DB) ([]*{{.TableName}}, error) {
+	var ms []*{{.TableName}}
+	err := d.conn(ctx{{$.ShardArg}}).Scopes(scopes...).Limit(limit).Offset(offset).Find(&ms).Error
+	return ms, err
+}
+
+{{- if not .ReadOnly}}
+
+func (d *{{.DAO}}) Create(ctx context.Context, m *{{.TableName}}) error {
+	return d.conn(ctx{{.ShardArgM}}).Create(m).Error
+}
+
+func (d *{{.DAO}}) Upsert(ctx context.Context, m *{{.TableName}}) error {
+	return d.conn(ctx{{.ShardArgM}}).Clauses(clause.OnConflict{UpdateAll: true}).Create(m).Error
+}
+{{- end}}
+{{- if .PK}}
+
+{{- if .Version}}
+
+// Update is a compare-and-swap on {{.Version.Name}}: it only succeeds if the
+// row still has m.{{.Version.Field}}, which is then incremented. It returns an
+// *ErrStaleVersion if the row was changed or deleted in the meantime.
+{{- if .Shard}}
+// The {{.Shard.Key.Name}} shard key is not updated: moving a row to another
+// shard takes a Delete and a Create.
+{{- end}}
+func (d *{{.DAO}}) Update(ctx context.Context, m *{{.TableName}}) error {
+	version := m.{{.Version.Field}}
+	m.{{.Version.Field}} = version + 1
+	res := d.conn(ctx{{.ShardArgM}}).Model(m).Where({{where .PK "m."}}, clause.Eq{Column: clause.Column{Name: {{printf "%q" .Version.Name}}}, Value: version}).Select("*"){{.OmitShard}}.Updates(m)
+	if res.Error != nil {
+		m.{{.Version.Field}} = version
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		m.{{.Version.Field}} = version
+		return &ErrStaleVersion{Table: "{{.TableNameStr}}", Version: int64(version)}
+	}
+	return nil
+}
+{{- else}}
+
+{{if .Shard -}}
+// Update writes every column of m but the {{.Shard.Key.Name}} shard key:
+// moving a row to another shard takes a Delete and a Create.
+{{end -}}
+func (d *{{.DAO}}) Update(ctx context.Context, m *{{.TableName}}) error {
+	return d.conn(ctx{{.ShardArgM}}).Model(m).Where({{where .PK "m."}}).Select("*"){{.OmitShard}}.Updates(m).Error
+}
+{{- end}}
+
+func (d *{{.DAO}}) Delete(ctx context.Context{{.ShardParam}}{{range .PK}}, {{.Param}} {{.Type}}{{end}}) error {
+	return d.conn(ctx{{$.ShardArg}}).Where({{where .PK ""}}).Delete(&{{.TableName}}{}).Error
+}
+{{- end}}
+{{- if .Bulk}}
+
+// {{.TableName}}BulkUpdateChunkSize is the maximum number of ids in the IN
+// clause of a single statement issued by the Update*ByIDs methods.
+const {{.TableName}}BulkUpdateChunkSize = 1000
+{{- $pk := index .PK 0}}
+
+// idsIn is the IN condition on the {{$pk.Name}} of ids.
+func (d *{{.DAO}}) idsIn(ids []{{$pk.Type}}) clause.IN {
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	return clause.IN{Column: clause.Column{Name: {{printf "%q" $pk.Name}}}, Values: values}
+}
+
+func (d *{{.DAO}}) getByIDs(ctx context.Context{{.ShardParam}}, ids []{{$pk.Type}}) ([]*{{.TableName}}, error) {
+	var ms []*{{.TableName}}
+	for start := 0; start < len(ids); start += {{.TableName}}BulkUpdateChunkSize {
+		end := start + {{.TableName}}BulkUpdateChunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		var chunk []*{{.TableName}}
+		if err := d.conn(ctx{{$.ShardArg}}).Where(d.idsIn(ids[start:end])).Find(&chunk).Error; err != nil {
+			return nil, err
+		}
+		ms = append(ms, chunk...)
+	}
+	return ms, nil
+}
+
+func (d *{{.DAO}}) updateByIDs(ctx context.Context{{.ShardParam}}, ids []{{$pk.Type}}, column string, value interface{}) (int64, error) {
+	var total int64
+	for start := 0; start < len(ids); start += {{.TableName}}BulkUpdateChunkSize {
+		end := start + {{.TableName}}BulkUpdateChunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		updates := map[string]interface{}{column: value}
+		{{- if .Version}}
+		updates[{{printf "%q" .Version.Name}}] = gorm.Expr("? + 1", clause.Column{Name: {{printf "%q" .Version.Name}}})
+		{{- end}}
+		res := d.conn(ctx{{$.ShardArg}}).Model(&{{.TableName}}{}).Where(d.idsIn(ids[start:end])).Updates(updates)
+		if res.Error != nil {
+			return total, res.Error
+		}
+		total += res.RowsAffected
+	}
+	return total, nil
+}
+{{- range .Bulk}}
+
+func (d *{{$.DAO}}) Update{{.Field}}ByIDs(ctx context.Context{{$.ShardParam}}, ids []{{$pk.Type}}, {{.Param}} {{.Type}}) (int64, error) {
+	return d.updateByIDs(ctx{{$.ShardArg}}, ids, {{printf "%q" .Name}}, {{.Param}})
+}
+{{- end}}
+{{- end}}
+`
+
+type dalColumn struct {
+	Name  string
+	Field string
+	Param string
+	Type  string
+	// Kind is the underlying Go type of Type, e.g. string for enum types.
+	Kind string
+}
+
+type dalIndex struct {
+	Name    string
+	Method  string
+	Columns []dalColumn
+}
+
+type dalParams struct {
+	Package      string
+	Database     string
+	TableName    string
+	TableNameStr string
+	DAO          string
+	PK           []dalColumn
+	Uniques      []dalIndex
+	Version      *dalColumn
+	Shard        *shardSpec
+	// ReadOnly DAOs, of views, have no write methods.
+	ReadOnly bool
+	// ShardParam, ShardArg and ShardArgM thread the shard key through the
+	// methods of sharded tables; they are empty otherwise.
+	ShardParam string
+	ShardArg   string
+	ShardArgM  string
+	// OmitShard keeps Update from writing the shard key, which would leave
+	// the row in the shard of its old key.
+	OmitShard string
+	Bulk      []dalColumn
+	Methods   []daoMethod
+	Imports   []string
+}
+
+// daoMethod describes a method of the generated DAO interface so that
+// decorators can be rendered without repeating every signature.
+type daoMethod struct {
+	Name    string
+	Params  string
+	Args    string
+	Results string
+}
+
+func daoMethods(p dalParams) []daoMethod {
+	params := func(cols []dalColumn) (string, string) {
+		ps, as := []string{"ctx context.Context" + p.ShardParam}, []string{"ctx" + p.ShardArg}
+		for _, c := range cols {
+			ps = append(ps, c.Param+" "+c.Type)
+			as = append(as, c.Param)
+		}
+		return strings.Join(ps, ", "), strings.Join(as, ", ")
+	}
+	one := "(*" + p.TableName + ", error)"
+	var ms []daoMethod
+	if len(p.PK) > 0 {
+		ps, as := params(p.PK)
+		ms = append(ms, daoMethod{"GetByID", ps, as, one})
+	}
+	for _, idx := range p.Uniques {
+		ps, as := params(idx.Columns)
+		ms = append(ms, daoMethod{idx.Method, ps, as, one})
+	}
+	ms = append(ms, daoMethod{"List", "ctx context.Context" + p.ShardParam + ", limit, offset int, scopes ...func(*gorm.DB) *gorm.DB", "ctx" + p.ShardArg + ", limit, offset, scopes...", "([]*" + p.TableName + ", error)"})
+	if p.ReadOnly {
+		return ms
+	}
+	ms = append(ms,
+		daoMethod{"Create", "ctx context.Context, m *" + p.TableName, "ctx, m", "error"},
+		daoMethod{"Upsert", "ctx context.Context, m *" + p.TableName, "ctx, m", "error"},
+	)
+	if len(p.PK) > 0 {
+		ps, as := params(p.PK)
+		ms = append(ms,
+			daoMethod{"Update", "ctx context.Context, m *" + p.TableName, "ctx, m", "error"},
+			daoMethod{"Delete", ps, as, "error"},
+		)
+	}
+	for _, c := range p.Bulk {
+		ms = append(ms, daoMethod{
+			"Update" + c.Field + "ByIDs",
+			"ctx context.Context" + p.ShardParam + ", ids []" + p.PK[0].Type + ", " + c.Param + " " + c.Type,
+			"ctx" + p.ShardArg + ", ids, " + c.Param,
+			"(int64, error)",
+		})
+	}
+	return ms
+}
+
+const dalErrorsTemplate = `
+package {{.Package}}
+
+import "fmt"
+
+// ErrStaleVersion is returned by a DAO Update when the optimistic lock
+// version of the row no longer matches.
+type ErrStaleVersion struct {
+	Table   string
+	Version int64
+}
+
+func (e *ErrStaleVersion) Error() string {
+	return fmt.Sprintf("%s: stale version %d", e.Table, e.Version)
+}
+`
+
+var dalFuncs = template.FuncMap{
+	"std":        stdImports,
+	"thirdParty": thirdPartyImports,
+	// where renders the conditions on the columns, their values the
+	// parameters or, with the "m." receiver, the fields of m.
+	"where": func(cols []dalColumn, recv string) string {
+		conds := make([]string, 0, len(cols))
+		for _, c := range cols {
+			value := c.Param
+			if recv != "" {
+				value = recv + c.Field
+			}
+			conds = append(conds, fmt.Sprintf("clause.Eq{Column: clause.Column{Name: %q}, Value: %s}", c.Name, value))
+		}
+		return strings.Join(conds, ", ")
+	},
+}
+
+// daoIdents are the receiver, parameters, variables and packages the DAO
+// methods and their decorators use beside the column parameters, which
+// toParamName suffixes with _ as it does keywords.
+var daoIdents = map[string]bool{
+	"d": true, "ctx": true, "shardKey": true, "m": true, "ms": true, "err": true,
+	"limit": true, "offset": true, "scopes": true, "ids": true, "version": true,
+	"key": true, "old": true, "n": true, "res": true, "span": true,
+	"context": true, "fmt": true, "gorm": true, "clause": true,
+}
+
+func toParamName(str string) string {
+	// only the first word is lower cased, api_url becomes apiURL
+	str = strings.TrimLeftFunc(str, notIdentRune)
+	if str == "" {
+		return "v"
+	}
+	first, rest := str, ""
+	if i := strings.IndexFunc(str, notIdentRune); i >= 0 {
+		first, rest = str[:i], str[i:]
+	}
+	s := lowerFirst(exportedIdent(ToCamelFirstUpper(first))) + ToCamelFirstUpper(rest)
+	if token.IsKeyword(s) || daoIdents[s] {
+		s += "_"
+	}
+	return s
+}
+
+func findColumn(ddl *sqlparser.DDL, name string) *sqlparser.ColumnDefinition {
+	for _, c := range ddl.TableSpec.Columns {
+		if c.Name.EqualString(name) {
+			return c
+		}
+	}
+	return nil
+}
+
+func newDALColumn(c *sqlparser.ColumnDefinition) dalColumn {
+	return dalColumn{
+		Name:  c.Name.String(),
+		Field: fieldName(c),
+		Param: toParamName(c.Name.String()),
+		Type:  GoType(c),
+		Kind:  underlyingType(c),
+	}
+}
+
+func indexColumns(ddl *sqlparser.DDL, idx *sqlparser.IndexDefinition) []dalColumn {
+	cols := make([]dalColumn, 0, len(idx.Columns))
+	for _, ic := range idx.Columns {
+		c := findColumn(ddl, ic.Column.String())
+		if c == nil {
+			return nil
+		}
+		cols = append(cols, newDALColumn(c))
+	}
+	return cols
+}
+
+// primaryKeyColumns returns the primary key columns of a table, whether
+// declared as a table-level PRIMARY KEY or inline on a column.
+func primaryKeyColumns(ddl *sqlparser.DDL) []dalColumn {
+	for _, idx := range ddl.TableSpec.Indexes {
+		if idx.Info.Primary {
+			return indexColumns(ddl, idx)
+		}
+	}
+	for _, c := range ddl.TableSpec.Columns {
+		if strings.Contains(sqlparser.String(&c.Type), "primary key") {
+			return []dalColumn{newDALColumn(c)}
+		}
+	}
+	return nil
+}
+
+func uniqueIndexes(ddl *sqlparser.DDL) []dalIndex {
+	var idxs []dalIndex
+	for _, idx := range ddl.TableSpec.Indexes {
+		if idx.Info.Primary || !idx.Info.Unique {
+			continue
+		}
+		cols := indexColumns(ddl, idx)
+		if cols == nil {
+			continue
+		}
+		idxs = append(idxs, dalIndex{Name: indexName(ddl, idx), Columns: cols})
+	}
+	for _, c := range ddl.TableSpec.Columns {
+		if inlineUnique(c) {
+			idxs = append(idxs, dalIndex{Name: c.Name.String(), Columns: []dalColumn{newDALColumn(c)}})
+		}
+	}
+	for i := range idxs {
+		fields := make([]string, 0, len(idxs[i].Columns))
+		for _, c := range idxs[i].Columns {
+			fields = append(fields, c.Field)
+		}
+		idxs[i].Method = "GetBy" + strings.Join(fields, "And")
+	}
+	return idxs
+}
+
+func newDALParams(pkg string, ddl *sqlparser.DDL) dalParams {
+	tableNameStr := ddl.NewName.Name.String()
+	tableName := modelName(tableNameStr)
+	p := dalParams{
+		Package:      pkg,
+		Database:     databaseName,
+		TableName:    tableName,
+		TableNameStr: tableNameStr,
+		DAO:          tableName + "DAO",
+		PK:           primaryKeyColumns(ddl),
+		Uniques:      uniqueIndexes(ddl),
+		Version:      versionColumn(ddl),
+		Shard:        tableShard(ddl),
+		ReadOnly:     viewTables[ddl],
+	}
+	if p.Shard != nil {
+		p.ShardParam = ", shardKey int64"
+		p.ShardArg = ", shardKey"
+		p.ShardArgM = ", int64(m." + p.Shard.Key.Field + ")"
+		p.OmitShard = fmt.Sprintf(".Omit(%q)", p.Shard.Key.Name)
+	}
+	p.Bulk = bulkColumns(ddl, p)
+	p.Methods = daoMethods(p)
+	var types []string
+	for _, cols := range [][]dalColumn{p.PK, p.Bulk} {
+		for _, c := range cols {
+			types = append(types, c.Type)
+		}
+	}
+	for _, idx := range p.Uniques {
+		for _, c := range idx.Columns {
+			types = append(types, c.Type)
+		}
+	}
+	p.Imports = importsOf(types...)
+	return p
+}
+
+// bulkUpdateAnnotation in a column comment restricts the Update*ByIDs helpers
+// of its table to the annotated columns.
+const bulkUpdateAnnotation = "dalgen:bulk"
+
+// bulkColumns returns the columns that get an Update*ByIDs helper: the
+// columns annotated with bulkUpdateAnnotation, or every column other than
+// the primary key and the version when none is annotated. The shard key of
+// a sharded table never gets one, as moving a row to another shard takes a
+// Delete and a Create. Only tables with a single-column primary key get the
+// helpers.
+func bulkColumns(ddl *sqlparser.DDL, p dalParams) []dalColumn {
+	if len(p.PK) != 1 {
+		return nil
+	}
+	var all, annotated []dalColumn
+	for _, c := range ddl.TableSpec.Columns {
+		col := newDALColumn(c)
+		if col.Name == p.PK[0].Name || p.Version != nil && col.Name == p.Version.Name || p.Shard != nil && col.Name == p.Shard.Key.Name || generatedColumns[c] {
+			continue
+		}
+		all = append(all, col)
+		if strings.Contains(getComment(c), bulkUpdateAnnotation) {
+			annotated = append(annotated, col)
+		}
+	}
+	if len(annotated) > 0 {
+		return annotated
+	}
+	return all
+}
+
+// versionColumn returns the -version-column of a table used for optimistic
+// locking, or nil if the table has none.
+func versionColumn(ddl *sqlparser.DDL) *dalColumn {
+	if versionColumnName == "" {
+		return nil
+	}
+	c := findColumn(ddl, versionColumnName)
+	if c == nil {
+		return nil
+	}
+	col := newDALColumn(c)
+	switch col.Type {
+	case "int", "int64", "uint64":
+		return &col
+	}
+	return nil
+}
+
+func genDALErrors(pkg string) string {
+	var buf bytes.Buffer
+	_ = template.Must(template.New("errors").Parse(dalErrorsTemplate)).Execute(&buf, struct{ Package string }{pkg})
+	return buf.String()
+}
+
+func genDAL(pkg string, ddl *sqlparser.DDL) string {
+	var buf bytes.Buffer
+	_ = template.Must(template.New("dal").Funcs(dalFuncs).Parse(dalTemplate)).Execute(&buf, newDALParams(pkg, ddl))
+	return buf.String()
+}
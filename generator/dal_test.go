@@ -0,0 +1,421 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestToParamName(t *testing.T) {
+	for _, tt := range []struct{ column, want string }{
+		{"user_id", "userID"},
+		{"api_url", "apiURL"},
+		{"my-col", "myCol"},
+		{"type", "type_"},
+		{"d", "d_"},
+		{"ctx", "ctx_"},
+		{"m", "m_"},
+		{"limit", "limit_"},
+		{"offset", "offset_"},
+		{"ids", "ids_"},
+	} {
+		if got := toParamName(tt.column); got != tt.want {
+			t.Errorf("toParamName(%q) = %q, want %q", tt.column, got, tt.want)
+		}
+	}
+}
+
+func TestDALConditions(t *testing.T) {
+	schema := "CREATE TABLE `t` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `c` int NOT NULL,\n" +
+		"  `d` int NOT NULL,\n" +
+		"  `my-col` int NOT NULL,\n" +
+		"  `order` int NOT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `cd` (`c`,`d`),\n" +
+		"  UNIQUE KEY `mo` (`my-col`,`order`)\n" +
+		");"
+	generate(t, schema, "t", "-dal")
+	got := gofmt(t, readFile(t, "model/t_dal.go"))
+	for _, want := range []string{
+		"func (d *TDAO) GetByCAndD(ctx context.Context, c int, d_ int) (*T, error) {",
+		`Where(clause.Eq{Column: clause.Column{Name: "c"}, Value: c}, clause.Eq{Column: clause.Column{Name: "d"}, Value: d_})`,
+		`Where(clause.Eq{Column: clause.Column{Name: "my-col"}, Value: myCol}, clause.Eq{Column: clause.Column{Name: "order"}, Value: order})`,
+		`Where(clause.Eq{Column: clause.Column{Name: "id"}, Value: m.ID}).Select("*")`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("t_dal.go lacks %s:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, `= ?"`) {
+		t.Errorf("t_dal.go still writes raw column conditions:\n%s", got)
+	}
+}
+
+func TestDALBulkUpdateParams(t *testing.T) {
+	schema := "CREATE TABLE `t` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `d` int NOT NULL,\n" +
+		"  `ids` varchar(255) NOT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		");"
+	generate(t, schema, "t", "-dal")
+	got := gofmt(t, readFile(t, "model/t_dal.go"))
+	for _, want := range []string{
+		"func (d *TDAO) UpdateDByIDs(ctx context.Context, ids []int64, d_ int) (int64, error) {",
+		`return d.updateByIDs(ctx, ids, "d", d_)`,
+		"func (d *TDAO) UpdateIDsByIDs(ctx context.Context, ids []int64, ids_ string) (int64, error) {",
+		`return clause.IN{Column: clause.Column{Name: "id"}, Values: values}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("t_dal.go lacks %s:\n%s", want, got)
+		}
+	}
+}
+
+const versionSchema = "CREATE TABLE `accounts` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `balance` int NOT NULL,\n" +
+	"  `version` int NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");\n" +
+	"CREATE TABLE `notes` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `body` text NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");"
+
+const versionTest = `package model
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func openDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a single connection keeps the one in-memory database
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&Accounts{}, &Notes{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestStaleVersion(t *testing.T) {
+	ctx := context.Background()
+	dao := NewAccountsDAO(openDB(t))
+	if err := dao.Create(ctx, &Accounts{Balance: 10}); err != nil {
+		t.Fatal(err)
+	}
+	a, err := dao.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := dao.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Version != 1 {
+		t.Fatalf("created at version %d, want 1", a.Version)
+	}
+	a.Balance = 20
+	if err := dao.Update(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Version != 2 {
+		t.Errorf("updated to version %d, want 2", a.Version)
+	}
+	b.Balance = 30
+	err = dao.Update(ctx, b)
+	var stale *ErrStaleVersion
+	if !errors.As(err, &stale) {
+		t.Fatalf("got %v, want an *ErrStaleVersion", err)
+	}
+	if stale.Table != "accounts" || stale.Version != 1 || b.Version != 1 {
+		t.Errorf("got %+v with b at version %d, want accounts at version 1", stale, b.Version)
+	}
+	got, err := dao.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Balance != 20 || got.Version != 2 {
+		t.Errorf("got %+v, want the first update", got)
+	}
+
+	// a deleted row is stale too
+	if err := dao.Delete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := dao.Update(ctx, got); !errors.As(err, &stale) {
+		t.Errorf("got %v updating a deleted row, want an *ErrStaleVersion", err)
+	}
+}
+
+func TestRacingUpdates(t *testing.T) {
+	ctx := context.Background()
+	dao := NewAccountsDAO(openDB(t))
+	if err := dao.Create(ctx, &Accounts{Balance: 10}); err != nil {
+		t.Fatal(err)
+	}
+	const n = 8
+	var read, done sync.WaitGroup
+	read.Add(n)
+	done.Add(n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer done.Done()
+			m, err := dao.GetByID(ctx, 1)
+			read.Done()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			// every update starts from the same version
+			read.Wait()
+			m.Balance += i
+			errs[i] = dao.Update(ctx, m)
+		}(i)
+	}
+	done.Wait()
+	won := 0
+	for _, err := range errs {
+		var stale *ErrStaleVersion
+		switch {
+		case err == nil:
+			won++
+		case !errors.As(err, &stale):
+			t.Errorf("got %v, want nil or an *ErrStaleVersion", err)
+		}
+	}
+	if won != 1 {
+		t.Errorf("%d updates won, want 1", won)
+	}
+	if got, _ := dao.GetByID(ctx, 1); got.Version != 2 {
+		t.Errorf("got version %d, want 2", got.Version)
+	}
+}
+
+func TestUnversioned(t *testing.T) {
+	ctx := context.Background()
+	dao := NewNotesDAO(openDB(t))
+	m := &Notes{Body: "a"}
+	if err := dao.Create(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	stale := *m
+	m.Body = "b"
+	if err := dao.Update(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	// the last write wins
+	stale.Body = "c"
+	if err := dao.Update(ctx, &stale); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := dao.GetByID(ctx, 1); got.Body != "c" {
+		t.Errorf("got %+v, want c", got)
+	}
+}
+`
+
+func TestDALVersion(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", versionSchema)
+	if err := run("-dal", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	accounts := gofmt(t, readFile(t, "model/accounts_dal.go"))
+	if !strings.Contains(accounts, `clause.Eq{Column: clause.Column{Name: "version"}, Value: version}`) {
+		t.Errorf("accounts_dal.go Update is not a compare-and-swap:\n%s", accounts)
+	}
+	if model := readFile(t, modelPath("accounts")); !strings.Contains(model, "default:1") {
+		t.Errorf("accounts.version does not default to 1:\n%s", model)
+	}
+	if notes := readFile(t, "model/notes_dal.go"); strings.Contains(notes, "ErrStaleVersion") {
+		t.Errorf("notes_dal.go checks a version:\n%s", notes)
+	}
+	writeFile(t, "model/version_test.go", versionTest)
+	goTest(t, "./model")
+}
+
+func TestDALVersionColumn(t *testing.T) {
+	schema := strings.Replace(versionSchema, "`version`", "`revision`", 1)
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	if err := run("-dal", "-version-column", "revision", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	accounts := gofmt(t, readFile(t, "model/accounts_dal.go"))
+	for _, want := range []string{
+		"// Update is a compare-and-swap on revision",
+		`clause.Eq{Column: clause.Column{Name: "revision"}, Value: version}`,
+		"m.Revision = version + 1",
+	} {
+		if !strings.Contains(accounts, want) {
+			t.Errorf("accounts_dal.go lacks %s:\n%s", want, accounts)
+		}
+	}
+
+	// without -version-column revision a version column is plain data
+	chdir(t)
+	writeFile(t, "schema.sql", versionSchema)
+	if err := run("-dal", "-version-column", "revision", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if accounts := readFile(t, "model/accounts_dal.go"); strings.Contains(accounts, "ErrStaleVersion") {
+		t.Errorf("accounts_dal.go checks the version column of -version-column revision:\n%s", accounts)
+	}
+	if _, err := os.Stat("model/dalgen_errors.go"); err == nil {
+		t.Error("dalgen_errors.go written without a version column")
+	}
+}
+
+const repositoryTest = `package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func checkMethodSet(t *testing.T, iface, dao reflect.Type) {
+	t.Helper()
+	if iface.NumMethod() != dao.NumMethod() {
+		t.Errorf("%s has %d methods, %s %d", iface, iface.NumMethod(), dao, dao.NumMethod())
+	}
+	for i := 0; i < dao.NumMethod(); i++ {
+		m := dao.Method(i)
+		im, ok := iface.MethodByName(m.Name)
+		if !ok {
+			t.Errorf("%s lacks %s", iface, m.Name)
+			continue
+		}
+		// the method type of the concrete type takes the receiver first
+		in := make([]reflect.Type, 0, m.Type.NumIn()-1)
+		for j := 1; j < m.Type.NumIn(); j++ {
+			in = append(in, m.Type.In(j))
+		}
+		out := make([]reflect.Type, 0, m.Type.NumOut())
+		for j := 0; j < m.Type.NumOut(); j++ {
+			out = append(out, m.Type.Out(j))
+		}
+		if want := reflect.FuncOf(in, out, m.Type.IsVariadic()); im.Type != want {
+			t.Errorf("%s.%s is %s, want %s", iface, m.Name, im.Type, want)
+		}
+	}
+}
+
+func TestRepositoryIface(t *testing.T) {
+	checkMethodSet(t, reflect.TypeOf((*AccountsRepositoryIface)(nil)).Elem(), reflect.TypeOf(&AccountsDAO{}))
+	checkMethodSet(t, reflect.TypeOf((*NotesRepositoryIface)(nil)).Elem(), reflect.TypeOf(&NotesDAO{}))
+	var _ AccountsDAOIface = AccountsRepositoryIface(nil)
+}
+`
+
+func TestDALRepositoryIface(t *testing.T) {
+	schema := strings.Replace(versionSchema, "  PRIMARY KEY (`id`)\n", "  PRIMARY KEY (`id`),\n  UNIQUE KEY `balance` (`balance`)\n", 1)
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	if err := run("-dal", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	accounts := gofmt(t, readFile(t, "model/accounts_dal.go"))
+	for _, want := range []string{
+		"type AccountsRepositoryIface interface {",
+		"var _ AccountsRepositoryIface = (*AccountsDAO)(nil)",
+		"GetByBalance(ctx context.Context, balance int) (*Accounts, error)",
+	} {
+		if !strings.Contains(accounts, want) {
+			t.Errorf("accounts_dal.go lacks %s:\n%s", want, accounts)
+		}
+	}
+	writeFile(t, "model/repository_test.go", repositoryTest)
+	goTest(t, "./model")
+}
+
+const bulkUpdateTest = `package model
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestUpdateByIDsChunks(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Accounts{}); err != nil {
+		t.Fatal(err)
+	}
+	rows := make([]Accounts, 2500)
+	for i := range rows {
+		rows[i].Balance = 1
+	}
+	if err := db.CreateInBatches(rows, 500).Error; err != nil {
+		t.Fatal(err)
+	}
+	statements := 0
+	db.Callback().Update().Before("gorm:update").Register("count", func(*gorm.DB) { statements++ })
+
+	// every other id of the first 2200, and 50 ids no row has
+	var ids []int64
+	for id := int64(1); id <= 2200; id += 2 {
+		ids = append(ids, id)
+	}
+	for id := int64(10001); id <= 10050; id++ {
+		ids = append(ids, id)
+	}
+	n, err := NewAccountsDAO(db).UpdateBalanceByIDs(context.Background(), ids, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1100 {
+		t.Errorf("%d rows affected, want 1100", n)
+	}
+	if want := (len(ids) + AccountsBulkUpdateChunkSize - 1) / AccountsBulkUpdateChunkSize; statements != want {
+		t.Errorf("%d statements for %d ids, want %d", statements, len(ids), want)
+	}
+	var updated, bumped int64
+	db.Model(&Accounts{}).Where("balance = 7").Count(&updated)
+	db.Model(&Accounts{}).Where("version = 2").Count(&bumped)
+	if updated != 1100 || bumped != 1100 {
+		t.Errorf("%d rows updated and %d versions bumped, want 1100", updated, bumped)
+	}
+	var even int64
+	db.Model(&Accounts{}).Where("balance = 7 AND id % 2 = 0").Count(&even)
+	if even != 0 {
+		t.Errorf("%d rows updated outside the ids", even)
+	}
+}
+`
+
+func TestDALBulkUpdateChunks(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", versionSchema)
+	if err := run("-dal", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if dal := readFile(t, "model/accounts_dal.go"); !strings.Contains(dal, "const AccountsBulkUpdateChunkSize = 1000") {
+		t.Errorf("no chunk size constant:\n%s", dal)
+	}
+	writeFile(t, "model/bulk_update_test.go", bulkUpdateTest)
+	goTest(t, "./model")
+}
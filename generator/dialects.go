@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// schemaPart is a run of consecutive input files of one dialect, parsed
+// apart from the others.
+type schemaPart struct {
+	dialect string
+	content []byte
+	spans   []inputSpan
+}
+
+// columnDialects holds the dialect of the file every column was parsed
+// from, for the type mapping of a schema read from several dialects.
+var columnDialects = make(map[*sqlparser.ColumnDefinition]string)
+
+// columnDialect returns the dialect c was parsed under.
+func columnDialect(c *sqlparser.ColumnDefinition) string {
+	if d, ok := columnDialects[c]; ok {
+		return d
+	}
+	return dialect
+}
+
+// dialectHint matches a leading -- dialect: postgres comment.
+var dialectHint = regexp.MustCompile(`(?i)^--\s*dialect:\s*(\S+)\s*$`)
+
+// dialectNames are the names a dialect hint may give.
+var dialectNames = map[string]string{
+	"mysql": "mysql", "postgres": "postgres", "postgresql": "postgres", "pg": "postgres", "sqlite": "sqlite",
+}
+
+// fileDialect returns the dialect an input file hints at by its
+// .mysql.sql, .pg.sql, .postgres.sql or .sqlite.sql extension, or by a
+// -- dialect: comment before its first statement, "" if it does not.
+func fileDialect(file string, content []byte) (string, error) {
+	name := file
+	if u, err := url.Parse(file); err == nil && isURL(file) {
+		name = u.Path
+	}
+	name = strings.ToLower(strings.TrimSuffix(name, ".gz"))
+	for _, ext := range []string{"mysql", "pg", "postgres", "sqlite"} {
+		if strings.HasSuffix(name, "."+ext+".sql") {
+			return dialectNames[ext], nil
+		}
+	}
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		m := dialectHint.FindStringSubmatch(line)
+		if m == nil {
+			if strings.HasPrefix(line, "--") {
+				continue
+			}
+			break
+		}
+		d, ok := dialectNames[strings.ToLower(m[1])]
+		if !ok {
+			return "", fmt.Errorf("%s: unknown dialect %q, expected mysql, postgres or sqlite", file, m[1])
+		}
+		return d, nil
+	}
+	return "", nil
+}
+
+// parseSchema parses every part of the schema under its dialect, merging
+// their tables in order. A schema of one dialect leaves -dialect set to
+// it; the dialects of a mixed one are kept per column, -dialect auto
+// falling back to mysql for the rest.
+func parseSchema(parts []schemaPart) ([]*sqlparser.DDL, error) {
+	flagDialect := dialect
+	if flagDialect == "auto" {
+		flagDialect = "mysql"
+	}
+	var all []*sqlparser.DDL
+	for _, part := range parts {
+		dialect = part.dialect
+		inputSpans = part.spans
+		var ddls []*sqlparser.DDL
+		var err error
+		switch dialect {
+		case "postgres":
+			ddls, err = ParsePostgresSQLs(string(part.content))
+		case "sqlite":
+			ddls, err = ParsePostgresSQLs(string(part.content))
+		default:
+			ddls, err = ParseSQLs(string(part.content))
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, ddl := range ddls {
+			for _, c := range ddl.TableSpec.Columns {
+				columnDialects[c] = part.dialect
+			}
+			if all, err = addTable(all, ddl, tablePositions[ddl]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, part := range parts {
+		if part.dialect != parts[0].dialect {
+			dialect = flagDialect
+			break
+		}
+	}
+	return all, nil
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFileDialect(t *testing.T) {
+	for _, tt := range []struct {
+		file, content, want string
+	}{
+		{"users.mysql.sql", "CREATE TABLE users (id bigint);", "mysql"},
+		{"users.pg.sql", "CREATE TABLE users (id bigint);", "postgres"},
+		{"users.postgres.sql", "CREATE TABLE users (id bigint);", "postgres"},
+		{"users.SQLite.sql", "CREATE TABLE users (id bigint);", "sqlite"},
+		{"users.pg.sql.gz", "", "postgres"},
+		{"https://example.com/schema/users.pg.sql?v=2", "", "postgres"},
+		// the extension wins over the comment
+		{"users.mysql.sql", "-- dialect: postgres\nCREATE TABLE users (id bigint);", "mysql"},
+		{"users.sql", "-- dialect: postgres\nCREATE TABLE users (id bigint);", "postgres"},
+		{"users.sql", "\n-- generated by pg_dump\n--   Dialect:  PostgreSQL  \nCREATE TABLE users (id bigint);", "postgres"},
+		{"users.sql", "-- dialect: sqlite\n", "sqlite"},
+		// only before the first statement
+		{"users.sql", "CREATE TABLE users (id bigint);\n-- dialect: postgres\n", ""},
+		{"users.sql", "CREATE TABLE users (id bigint);", ""},
+		{"users.sql.bak", "", ""},
+	} {
+		got, err := fileDialect(tt.file, []byte(tt.content))
+		if err != nil || got != tt.want {
+			t.Errorf("%s %q: got %q, %v, want %q", tt.file, tt.content, got, err, tt.want)
+		}
+	}
+	_, err := fileDialect("users.sql", []byte("-- dialect: oracle\n"))
+	if want := `users.sql: unknown dialect "oracle", expected mysql, postgres or sqlite`; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %s", err, want)
+	}
+}
+
+func TestMixedDialects(t *testing.T) {
+	chdir(t)
+	// real is a double in MySQL but single precision in Postgres
+	writeFile(t, "items.mysql.sql", "CREATE TABLE `items` (\n"+
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n"+
+		"  `price` real NOT NULL,\n"+
+		"  PRIMARY KEY (`id`)\n"+
+		");\n")
+	writeFile(t, "events.sql", `-- schema of the events
+-- dialect: postgres
+CREATE TABLE events (
+    id bigint NOT NULL,
+    price real NOT NULL,
+    tags text[] NOT NULL,
+    PRIMARY KEY (id)
+);
+`)
+	if err := run("-strict", "items.mysql.sql", "events.sql"); err != nil {
+		t.Fatal(err)
+	}
+	items := gofmt(t, readFile(t, modelPath("items")))
+	if want := "Price float64 `gorm:\"Column:price\" json:\"price\"`"; !strings.Contains(items, want) {
+		t.Errorf("no %s in:\n%s", want, items)
+	}
+	events := gofmt(t, readFile(t, modelPath("events")))
+	for _, want := range []string{
+		"Price float32        `gorm:\"Column:price\" json:\"price\"`",
+		"Tags  pq.StringArray `gorm:\"Column:tags;type:text[]\" json:\"tags\"`",
+	} {
+		if !strings.Contains(events, want) {
+			t.Errorf("no %s in:\n%s", want, events)
+		}
+	}
+	goTest(t, "./model")
+}
@@ -0,0 +1,283 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// schemaDiff is what dalgen diff reports between two schemas.
+type schemaDiff struct {
+	AddedTables   []string    `json:"added_tables"`
+	RemovedTables []string    `json:"removed_tables"`
+	ChangedTables []tableDiff `json:"changed_tables"`
+}
+
+type tableDiff struct {
+	Table          string         `json:"table"`
+	AddedColumns   []diffColumn   `json:"added_columns,omitempty"`
+	RemovedColumns []diffColumn   `json:"removed_columns,omitempty"`
+	ChangedColumns []columnChange `json:"changed_columns,omitempty"`
+	AddedIndexes   []diffIndex    `json:"added_indexes,omitempty"`
+	RemovedIndexes []diffIndex    `json:"removed_indexes,omitempty"`
+	ChangedIndexes []indexChange  `json:"changed_indexes,omitempty"`
+}
+
+// diffColumn is a column as dalgen diff compares it: its SQL type, without
+// the constraints and default, the Go type of its field and whether it is
+// NOT NULL.
+type diffColumn struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	GoType  string `json:"go_type"`
+	NotNull bool   `json:"not_null"`
+}
+
+type columnChange struct {
+	Name string     `json:"name"`
+	Old  diffColumn `json:"old"`
+	New  diffColumn `json:"new"`
+}
+
+// diffIndex is an index by name, e.g. PRIMARY for the primary key, and its
+// definition, e.g. UNIQUE (email).
+type diffIndex struct {
+	Name       string `json:"name"`
+	Definition string `json:"definition"`
+}
+
+type indexChange struct {
+	Name string    `json:"name"`
+	Old  diffIndex `json:"old"`
+	New  diffIndex `json:"new"`
+}
+
+func (d *schemaDiff) empty() bool {
+	return len(d.AddedTables) == 0 && len(d.RemovedTables) == 0 && len(d.ChangedTables) == 0
+}
+
+// diffSchemas writes the differences between the tables of the old and
+// new schema files to w, as text or under -json as JSON, and reports
+// whether there are any.
+func diffSchemas(oldFile, newFile string, w io.Writer) (bool, error) {
+	oldDDLs, err := readSchema(oldFile)
+	if err != nil {
+		return false, err
+	}
+	newDDLs, err := readSchema(newFile)
+	if err != nil {
+		return false, err
+	}
+	d := diffTables(oldDDLs, newDDLs)
+	if diffJSON {
+		data, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintf(w, "%s\n", data)
+	} else {
+		d.write(w)
+	}
+	return !d.empty(), nil
+}
+
+func readSchema(file string) ([]*sqlparser.DDL, error) {
+	parts, err := readInputs([]string{file})
+	if err != nil {
+		return nil, err
+	}
+	return parseSchema(parts)
+}
+
+func diffTables(oldDDLs, newDDLs []*sqlparser.DDL) *schemaDiff {
+	d := &schemaDiff{AddedTables: []string{}, RemovedTables: []string{}, ChangedTables: []tableDiff{}}
+	olds := make(map[string]*sqlparser.DDL)
+	for _, ddl := range oldDDLs {
+		olds[ddl.NewName.Name.String()] = ddl
+	}
+	news := make(map[string]*sqlparser.DDL)
+	for _, ddl := range newDDLs {
+		news[ddl.NewName.Name.String()] = ddl
+	}
+	for _, ddl := range newDDLs {
+		name := ddl.NewName.Name.String()
+		old, ok := olds[name]
+		if !ok {
+			d.AddedTables = append(d.AddedTables, name)
+			continue
+		}
+		if td := diffTable(old, ddl); td != nil {
+			d.ChangedTables = append(d.ChangedTables, *td)
+		}
+	}
+	for _, ddl := range oldDDLs {
+		if name := ddl.NewName.Name.String(); news[name] == nil {
+			d.RemovedTables = append(d.RemovedTables, name)
+		}
+	}
+	sort.Strings(d.AddedTables)
+	sort.Strings(d.RemovedTables)
+	sort.Slice(d.ChangedTables, func(i, j int) bool { return d.ChangedTables[i].Table < d.ChangedTables[j].Table })
+	return d
+}
+
+// diffTable compares the columns, in the order of the new table, and the
+// indexes of a table, nil if they are the same.
+func diffTable(old, new *sqlparser.DDL) *tableDiff {
+	td := &tableDiff{Table: new.NewName.Name.String()}
+	oldCols := make(map[string]diffColumn)
+	for _, c := range old.TableSpec.Columns {
+		oldCols[c.Name.String()] = newDiffColumn(c)
+	}
+	newCols := make(map[string]bool)
+	for _, c := range new.TableSpec.Columns {
+		nc := newDiffColumn(c)
+		newCols[nc.Name] = true
+		oc, ok := oldCols[nc.Name]
+		switch {
+		case !ok:
+			td.AddedColumns = append(td.AddedColumns, nc)
+		case oc != nc:
+			td.ChangedColumns = append(td.ChangedColumns, columnChange{nc.Name, oc, nc})
+		}
+	}
+	for _, c := range old.TableSpec.Columns {
+		if !newCols[c.Name.String()] {
+			td.RemovedColumns = append(td.RemovedColumns, oldCols[c.Name.String()])
+		}
+	}
+	oldIdx := diffIndexes(old)
+	newIdx := diffIndexes(new)
+	oldByName := make(map[string]diffIndex)
+	for _, idx := range oldIdx {
+		oldByName[idx.Name] = idx
+	}
+	newByName := make(map[string]bool)
+	for _, idx := range newIdx {
+		newByName[idx.Name] = true
+		oi, ok := oldByName[idx.Name]
+		switch {
+		case !ok:
+			td.AddedIndexes = append(td.AddedIndexes, idx)
+		case oi != idx:
+			td.ChangedIndexes = append(td.ChangedIndexes, indexChange{idx.Name, oi, idx})
+		}
+	}
+	for _, idx := range oldIdx {
+		if !newByName[idx.Name] {
+			td.RemovedIndexes = append(td.RemovedIndexes, idx)
+		}
+	}
+	if len(td.AddedColumns)+len(td.RemovedColumns)+len(td.ChangedColumns)+
+		len(td.AddedIndexes)+len(td.RemovedIndexes)+len(td.ChangedIndexes) == 0 {
+		return nil
+	}
+	return td
+}
+
+func newDiffColumn(c *sqlparser.ColumnDefinition) diffColumn {
+	t := c.Type
+	t.NotNull, t.Autoincrement, t.KeyOpt = false, false, 0
+	t.Default, t.OnUpdate, t.Comment = nil, nil, nil
+	return diffColumn{
+		Name:    c.Name.String(),
+		Type:    sqlparser.String(&t),
+		GoType:  safeGoType(c),
+		NotNull: bool(c.Type.NotNull),
+	}
+}
+
+// safeGoType is the Go type of a column, "" for a type dalgen cannot map,
+// which the diff and -emit ir still report.
+func safeGoType(c *sqlparser.ColumnDefinition) (goType string) {
+	defer func() {
+		if recover() != nil {
+			goType = ""
+		}
+	}()
+	return GoType(c)
+}
+
+// diffIndexes returns the indexes of a table, the inline primary key and
+// unique columns included.
+func diffIndexes(ddl *sqlparser.DDL) []diffIndex {
+	var indexes []diffIndex
+	for _, c := range ddl.TableSpec.Columns {
+		switch kind := sqlparser.String(&c.Type); {
+		case strings.Contains(kind, "primary key"):
+			indexes = append(indexes, diffIndex{"PRIMARY", "PRIMARY KEY (" + c.Name.String() + ")"})
+		case inlineUnique(c):
+			indexes = append(indexes, diffIndex{c.Name.String(), "UNIQUE (" + c.Name.String() + ")"})
+		}
+	}
+	for _, idx := range ddl.TableSpec.Indexes {
+		var cols []string
+		for _, ic := range idx.Columns {
+			cols = append(cols, ic.Column.String())
+		}
+		kind := "KEY"
+		switch {
+		case idx.Info.Primary:
+			kind = "PRIMARY KEY"
+		case idx.Info.Unique:
+			kind = "UNIQUE"
+		case indexClass(idx) != "":
+			kind = indexClass(idx)
+		}
+		name := indexName(ddl, idx)
+		if idx.Info.Primary {
+			name = "PRIMARY"
+		}
+		indexes = append(indexes, diffIndex{name, kind + " (" + strings.Join(cols, ", ") + ")"})
+	}
+	return indexes
+}
+
+// write renders the diff as text: + for the added tables, columns and
+// indexes, - for the removed ones and ~ for the changed ones, with their
+// old → new definitions.
+func (d *schemaDiff) write(w io.Writer) {
+	for _, t := range d.AddedTables {
+		fmt.Fprintf(w, "+ table %s\n", t)
+	}
+	for _, t := range d.RemovedTables {
+		fmt.Fprintf(w, "- table %s\n", t)
+	}
+	for _, td := range d.ChangedTables {
+		fmt.Fprintf(w, "~ table %s\n", td.Table)
+		for _, c := range td.AddedColumns {
+			fmt.Fprintf(w, "    + column %s %s\n", c.Name, c.describe())
+		}
+		for _, c := range td.RemovedColumns {
+			fmt.Fprintf(w, "    - column %s %s\n", c.Name, c.describe())
+		}
+		for _, c := range td.ChangedColumns {
+			fmt.Fprintf(w, "    ~ column %s: %s → %s\n", c.Name, c.Old.describe(), c.New.describe())
+		}
+		for _, idx := range td.AddedIndexes {
+			fmt.Fprintf(w, "    + index %s %s\n", idx.Name, idx.Definition)
+		}
+		for _, idx := range td.RemovedIndexes {
+			fmt.Fprintf(w, "    - index %s %s\n", idx.Name, idx.Definition)
+		}
+		for _, idx := range td.ChangedIndexes {
+			fmt.Fprintf(w, "    ~ index %s: %s → %s\n", idx.Name, idx.Old.Definition, idx.New.Definition)
+		}
+	}
+}
+
+// describe renders a column as e.g. varchar(64) NOT NULL (string).
+func (c diffColumn) describe() string {
+	s := c.Type
+	if c.NotNull {
+		s += " NOT NULL"
+	}
+	if c.GoType != "" {
+		s += " (" + c.GoType + ")"
+	}
+	return s
+}
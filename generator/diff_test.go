@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const (
+	diffOld = `CREATE TABLE users (id bigint NOT NULL AUTO_INCREMENT, email varchar(64) NOT NULL, legacy int, PRIMARY KEY (id), KEY idx_email (email));
+CREATE TABLE old_t (id int);
+CREATE TABLE same (id int);`
+	diffNew = `CREATE TABLE users (id bigint NOT NULL AUTO_INCREMENT, email varchar(255) NOT NULL, name text, PRIMARY KEY (id), UNIQUE KEY idx_email (email));
+CREATE TABLE new_t (id int);
+CREATE TABLE same (id int);`
+)
+
+func TestDiffSchemas(t *testing.T) {
+	reset()
+	chdir(t)
+	writeFile(t, "old.sql", diffOld)
+	writeFile(t, "new.sql", diffNew)
+	var out bytes.Buffer
+	changed, err := diffSchemas("old.sql", "new.sql", &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `+ table new_t
+- table old_t
+~ table users
+    + column name text (string)
+    - column legacy int (int)
+    ~ column email: varchar(64) NOT NULL (string) → varchar(255) NOT NULL (string)
+    ~ index idx_email: KEY (email) → UNIQUE (email)
+`
+	if !changed || out.String() != want {
+		t.Errorf("got changed %v and\n%s\nwant true and\n%s", changed, out.String(), want)
+	}
+}
+
+func TestDiffSchemasJSON(t *testing.T) {
+	reset()
+	diffJSON = true
+	chdir(t)
+	writeFile(t, "old.sql", diffOld)
+	writeFile(t, "new.sql", diffNew)
+	var out bytes.Buffer
+	if _, err := diffSchemas("old.sql", "new.sql", &out); err != nil {
+		t.Fatal(err)
+	}
+	var got schemaDiff
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("%v:\n%s", err, out.String())
+	}
+	want := schemaDiff{
+		AddedTables:   []string{"new_t"},
+		RemovedTables: []string{"old_t"},
+		ChangedTables: []tableDiff{{
+			Table:          "users",
+			AddedColumns:   []diffColumn{{"name", "text", "string", false}},
+			RemovedColumns: []diffColumn{{"legacy", "int", "int", false}},
+			ChangedColumns: []columnChange{{"email",
+				diffColumn{"email", "varchar(64)", "string", true},
+				diffColumn{"email", "varchar(255)", "string", true}}},
+			ChangedIndexes: []indexChange{{"idx_email",
+				diffIndex{"idx_email", "KEY (email)"},
+				diffIndex{"idx_email", "UNIQUE (email)"}}},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffSchemasSame(t *testing.T) {
+	reset()
+	chdir(t)
+	writeFile(t, "old.sql", diffOld)
+	var out bytes.Buffer
+	changed, err := diffSchemas("old.sql", "old.sql", &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed || out.Len() != 0 {
+		t.Errorf("got changed %v and %q for the same schema", changed, out.String())
+	}
+}
@@ -0,0 +1,19 @@
+// Package generator is dalgen, the gorm model and DAO generator, as a
+// library: a command of your own registers hooks post-processing the
+// generated files and runs it with Main.
+//
+//	package main
+//
+//	import "github.com/web3-luoxi/dalgen/generator"
+//
+//	func main() {
+//		generator.RegisterHook(func(file *generator.GeneratedFile) error {
+//			file.Content += "\n// reviewed\n"
+//			return nil
+//		})
+//		generator.Main()
+//	}
+//
+// The package registers the dalgen flags on the default flag set when it
+// is imported, so that Main parses them as the dalgen command does.
+package generator
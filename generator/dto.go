@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const dtoTemplate = `
+package {{.Package}}
+
+{{.Imports}}
+type {{.TableName}}DTO struct {
+{{- range .Fields}}
+	{{.Field}} {{if .Nullable}}*{{end}}{{.Type}} ` + "`json:\"{{.JSON}}{{if .Nullable}},omitempty{{end}}\"`" + `
+{{- end}}
+}
+
+func (m *{{.TableName}}) ToDTO() *{{.TableName}}DTO {
+	d := &{{.TableName}}DTO{}
+{{- range .Fields}}
+{{- if .Nullable}}
+	d.{{.Field}} = new({{.Type}})
+	*d.{{.Field}} = m.{{.Field}}
+{{- else}}
+	d.{{.Field}} = m.{{.Field}}
+{{- end}}
+{{- end}}
+	return d
+}
+
+func (m *{{.TableName}}) FromDTO(d *{{.TableName}}DTO) {
+{{- range .Fields}}
+{{- if .Nullable}}
+	if d.{{.Field}} != nil {
+		m.{{.Field}} = *d.{{.Field}}
+	} else {
+		var zero {{.Type}}
+		m.{{.Field}} = zero
+	}
+{{- else}}
+	m.{{.Field}} = d.{{.Field}}
+{{- end}}
+{{- end}}
+}
+`
+
+type dtoField struct {
+	dalColumn
+	JSON     string
+	Nullable bool
+}
+
+func genDTO(pkg string, ddl *sqlparser.DDL) string {
+	tableNameStr := ddl.NewName.Name.String()
+	var fields []dtoField
+	var types []string
+	for _, c := range ddl.TableSpec.Columns {
+		if jsonExcluded(tableNameStr, c.Name.String()) {
+			continue
+		}
+		f := dtoField{dalColumn: newDALColumn(c), JSON: jsonName(c.Name.String()), Nullable: isNullable(c)}
+		types = append(types, f.Type)
+		fields = append(fields, f)
+	}
+
+	params := struct {
+		Package   string
+		TableName string
+		Imports   string
+		Fields    []dtoField
+	}{
+		Package:   pkg,
+		TableName: modelName(tableNameStr),
+		Imports:   renderImports(importsOf(types...)),
+		Fields:    fields,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("dto").Parse(dtoTemplate)).Execute(&buf, params)
+	return buf.String()
+}
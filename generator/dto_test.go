@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const dtoSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  password_hash varchar(255) NOT NULL,
+  nickname varchar(64) DEFAULT NULL,
+  avatar blob,
+  born_at datetime NULL,
+  PRIMARY KEY (id)
+);`
+
+const dtoTest = `package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDTORoundTrip(t *testing.T) {
+	m := Users{ID: 1, Email: "a@example.com", PasswordHash: "secret", Nickname: "ann", Avatar: []byte{1}, BornAt: time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)}
+	d := m.ToDTO()
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret") || strings.Contains(string(b), "password") {
+		t.Errorf("the DTO leaks the password hash: %s", b)
+	}
+	var back Users
+	back.FromDTO(d)
+	m.PasswordHash = ""
+	if !reflect.DeepEqual(back, m) {
+		t.Errorf("got %+v, want %+v", back, m)
+	}
+}
+
+func TestDTONullable(t *testing.T) {
+	var d UsersDTO
+	if err := json.Unmarshal([]byte(` + "`" + `{"id":2,"email":"b@example.com"}` + "`" + `), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Nickname != nil || d.BornAt != nil {
+		t.Errorf("absent nullable fields decoded as %+v", d)
+	}
+	m := Users{Nickname: "stale"}
+	m.FromDTO(&d)
+	if m.ID != 2 || m.Email != "b@example.com" || m.Nickname != "" || !m.BornAt.IsZero() {
+		t.Errorf("got %+v, want the absent fields zero", m)
+	}
+}
+`
+
+func TestDTO(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", dtoSchema)
+	if err := run("-gen-dto", "-json-exclude", "password_hash", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	dto := gofmt(t, readFile(t, "model/users_dto.go"))
+	if strings.Contains(dto, "PasswordHash") {
+		t.Errorf("the excluded column is in the DTO:\n%s", dto)
+	}
+	if strings.Contains(dto, "gorm:") {
+		t.Errorf("the DTO has gorm tags:\n%s", dto)
+	}
+	for _, want := range []string{
+		"Email    string     `json:\"email\"`",
+		"Nickname *string    `json:\"nickname,omitempty\"`",
+	} {
+		if !strings.Contains(dto, want) {
+			t.Errorf("no %q in:\n%s", want, dto)
+		}
+	}
+	writeFile(t, "model/dto_test.go", dtoTest)
+	goTest(t, "./model")
+}
+
+const dtoPointersTest = `package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDTOPointers(t *testing.T) {
+	nickname := "ann"
+	for _, m := range []Users{{ID: 1, Email: "a@example.com", Nickname: &nickname}, {ID: 2}} {
+		var back Users
+		back.FromDTO(m.ToDTO())
+		if !reflect.DeepEqual(back, m) {
+			t.Errorf("got %+v, want %+v", back, m)
+		}
+	}
+}
+`
+
+func TestDTONullablePointers(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", dtoSchema)
+	if err := run("-gen-dto", "-nullable-pointers", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	// the model field is a pointer already
+	if dto := gofmt(t, readFile(t, "model/users_dto.go")); !strings.Contains(dto, "d.Nickname = m.Nickname") {
+		t.Errorf("nickname is not copied as is:\n%s", dto)
+	}
+	writeFile(t, "model/dto_test.go", dtoPointersTest)
+	goTest(t, "./model")
+}
@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// delimiterLine matches a mysql client DELIMITER command.
+	delimiterLine = regexp.MustCompile(`(?i)^\s*delimiter\s+(\S+)\s*$`)
+	// conditionalVersion matches the server version opening the body of a
+	// /*!40101 ... */ conditional comment.
+	conditionalVersion = regexp.MustCompile(`^\d{5,6}`)
+)
+
+// tidbAutoRandom opens the feature comment TiDB wraps AUTO_RANDOM in.
+const tidbAutoRandom = "/*T![auto_rand]"
+
+// stripDelimiterBlocks removes the DELIMITER ;; ... DELIMITER ; blocks
+// mysqldump writes around triggers and routines, which are no DDL the
+// models need and whose bodies the statement splitter cannot handle.
+func stripDelimiterBlocks(content string) string {
+	lines := strings.SplitAfter(content, "\n")
+	var b strings.Builder
+	custom := false
+	for _, line := range lines {
+		if m := delimiterLine.FindStringSubmatch(line); m != nil {
+			custom = m[1] != ";"
+			b.WriteString("\n")
+			continue
+		}
+		if custom {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// commentEnd returns the index of the */ closing the comment whose body
+// starts at i, skipping quoted strings when quoted is set, len(s) if the
+// comment is not closed.
+func commentEnd(s string, i int, quoted bool) int {
+	var quote byte
+	for ; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case quoted && (c == '\'' || c == '"' || c == '`'):
+			quote = c
+		case c == '*' && i+1 < len(s) && s[i+1] == '/':
+			return i
+		}
+	}
+	return len(s)
+}
+
+// blankComment replaces a dropped comment, keeping its newlines so that
+// statement lines still match the input.
+func blankComment(comment string) string {
+	if n := strings.Count(comment, "\n"); n > 0 {
+		return strings.Repeat("\n", n)
+	}
+	return " "
+}
+
+// stripDumpArtifacts prepares a mysqldump file for the statement splitter:
+// it drops the DELIMITER blocks, the -- and # line comments and the /* */
+// comments. Conditional /*!40101 ... */ comments are dropped too, SET
+// statements and DISABLE KEYS being all they hold, except for those
+// opening a CREATE statement, such as the /*!50001 CREATE ALGORITHM=... */
+// of views, which are unwrapped along with the rest of their statement,
+// and the /*!50001 DROP VIEW ... */ before them. The /*T![auto_rand] */
+// comments of TiDB are unwrapped as well.
+func stripDumpArtifacts(content string) string {
+	content = stripDelimiterBlocks(content)
+	var b strings.Builder
+	var quote byte
+	inCreate := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			b.WriteByte(c)
+			if c == '\\' && quote != '`' && i+1 < len(content) {
+				i++
+				b.WriteByte(content[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '#' || c == '-' && strings.HasPrefix(content[i:], "--") &&
+			(i+2 == len(content) || strings.IndexByte(" \t\r\n", content[i+2]) >= 0):
+			if j := strings.IndexByte(content[i:], '\n'); j >= 0 {
+				i += j - 1
+			} else {
+				i = len(content)
+			}
+			continue
+		case strings.HasPrefix(content[i:], "/*!"):
+			end := commentEnd(content, i+3, true)
+			body := conditionalVersion.ReplaceAllString(content[i+3:end], "")
+			if _, ok := sqlKeyword(body, "create"); ok || inCreate {
+				inCreate = true
+				b.WriteString(" " + stripDumpArtifacts(body) + " ")
+			} else if _, ok := sqlKeyword(body, "drop"); ok {
+				b.WriteString(" " + body + " ")
+			} else {
+				b.WriteString(blankComment(content[i:end]))
+			}
+			i = end + 1
+			continue
+		case strings.HasPrefix(content[i:], tidbAutoRandom):
+			// TiDB writes the AUTO_RANDOM of a column so, the other
+			// /*T![feature] */ comments are dropped
+			end := commentEnd(content, i+len(tidbAutoRandom), true)
+			b.WriteString(" " + content[i+len(tidbAutoRandom):end] + " ")
+			i = end + 1
+			continue
+		case strings.HasPrefix(content[i:], "/*"):
+			end := commentEnd(content, i+2, false)
+			b.WriteString(blankComment(content[i:end]))
+			i = end + 1
+			continue
+		case c == ';':
+			inCreate = false
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package generator
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// TestMysqldumpGolden generates the models of testdata/mysqldump.sql, an
+// unmodified mysqldump of a two-table database with its data, conditional
+// comments, LOCK TABLES and a trigger in a DELIMITER block, under -strict,
+// and compares them with testdata/mysqldump, which go test -update
+// rewrites.
+func TestMysqldumpGolden(t *testing.T) {
+	dump, err := ioutil.ReadFile("testdata/mysqldump.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := testdataPath(t, "mysqldump")
+	chdir(t)
+	writeFile(t, "dump.sql", string(dump))
+	_, stderr, err := capture(t, func() error { return run("-strict", "dump.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stderr != "" {
+		t.Errorf("got warnings:\n%s", stderr)
+	}
+	for _, table := range []string{"customers", "orders"} {
+		checkGolden(t, filepath.Join(golden, table+".go.golden"), gofmt(t, readFile(t, modelPath(table))))
+	}
+}
@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// inputSpan is the part of the schema read from one input file.
+type inputSpan struct {
+	file string
+	// line is the first line of the file in the concatenated schema.
+	line int
+}
+
+// inputSpans are the input files in the order readInputs concatenated
+// them.
+var inputSpans []inputSpan
+
+// tablePositions holds the file:line of the CREATE statement of every
+// table, for reporting duplicate definitions.
+var tablePositions = make(map[*sqlparser.DDL]string)
+
+// inputPosition returns the file:line of a line of the concatenated schema.
+func inputPosition(line int) string {
+	for i := len(inputSpans) - 1; i >= 0; i-- {
+		if s := inputSpans[i]; line >= s.line {
+			return fmt.Sprintf("%s:%d", s.file, line-s.line+1)
+		}
+	}
+	return fmt.Sprintf("line %d", line)
+}
+
+// statementLines returns the line each statement starts at, stmts being
+// successive substrings of content.
+func statementLines(content string, stmts []string) []int {
+	lines := make([]int, len(stmts))
+	offset, line := 0, 1
+	for i, stmt := range stmts {
+		at := strings.Index(content[offset:], stmt)
+		if at < 0 {
+			at = 0
+		}
+		start := offset + at + len(stmt) - len(strings.TrimLeft(stmt, " \t\r\n"))
+		line += strings.Count(content[offset:start], "\n")
+		lines[i] = line
+		offset = start
+	}
+	return lines
+}
+
+// tableIndex returns the index in ddls of the table named name, compared
+// case-insensitively, or -1.
+func tableIndex(ddls []*sqlparser.DDL, name string) int {
+	for i, ddl := range ddls {
+		if strings.EqualFold(ddl.NewName.Name.String(), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// addTable appends a table created at pos to ddls. A table created twice
+// without a DROP TABLE in between, as when migration files are concatenated,
+// keeps its last definition with a warning, or fails under -strict.
+func addTable(ddls []*sqlparser.DDL, ddl *sqlparser.DDL, pos string) ([]*sqlparser.DDL, error) {
+	tablePositions[ddl] = pos
+	name := ddl.NewName.Name.String()
+	if i := tableIndex(ddls, name); i >= 0 {
+		msg := fmt.Sprintf("table %s is created at %s and again at %s", name, tablePositions[ddls[i]], pos)
+		if strict {
+			return nil, fmt.Errorf("%s", msg)
+		}
+		fmt.Fprintf(os.Stderr, "warning: %s, using the last definition\n", msg)
+		ddls = append(ddls[:i], ddls[i+1:]...)
+	}
+	return append(ddls, ddl), nil
+}
+
+// dropTable removes a table a DROP TABLE or DROP VIEW statement drops.
+func dropTable(ddls []*sqlparser.DDL, name string) []*sqlparser.DDL {
+	if i := tableIndex(ddls, name); i >= 0 {
+		ddls = append(ddls[:i], ddls[i+1:]...)
+	}
+	return ddls
+}
@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	firstUsers  = "-- users\nCREATE TABLE users (id bigint NOT NULL, PRIMARY KEY (id));\n"
+	secondUsers = "CREATE TABLE posts (id bigint NOT NULL, PRIMARY KEY (id));\n\n" +
+		"CREATE TABLE users (\n" +
+		"  id bigint NOT NULL,\n" +
+		"  email varchar(255) NOT NULL,\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n"
+)
+
+func TestDuplicateTableWarning(t *testing.T) {
+	chdir(t)
+	writeFile(t, "a.sql", firstUsers)
+	writeFile(t, "b.sql", strings.Replace(secondUsers, "TABLE users", "TABLE `Users`", 1))
+	_, stderr, err := capture(t, func() error { return run("a.sql", "b.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "warning: table Users is created at a.sql:2 and again at b.sql:3, using the last definition\n"; stderr != want {
+		t.Errorf("got warnings\n%s\nwant\n%s", stderr, want)
+	}
+	if users := readFile(t, modelPath("Users")); !strings.Contains(users, "Email") {
+		t.Errorf("the first definition was used:\n%s", users)
+	}
+	if _, err := os.Stat(modelPath("users")); err == nil {
+		t.Error("users.go written for the overridden definition")
+	}
+}
+
+func TestDuplicateTableStrict(t *testing.T) {
+	chdir(t)
+	writeFile(t, "a.sql", firstUsers)
+	writeFile(t, "b.sql", secondUsers)
+	_, _, err := capture(t, func() error { return run("-strict", "a.sql", "b.sql") })
+	if want := "table users is created at a.sql:2 and again at b.sql:3"; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %s", err, want)
+	}
+	if _, err := os.Stat("model"); err == nil {
+		t.Error("models written despite the duplicate")
+	}
+}
+
+func TestDropThenCreate(t *testing.T) {
+	chdir(t)
+	writeFile(t, "a.sql", firstUsers)
+	writeFile(t, "b.sql", "DROP TABLE IF EXISTS `users`;\n"+secondUsers)
+	stdout, stderr, err := capture(t, func() error { return run("-strict", "a.sql", "b.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stderr != "" {
+		t.Errorf("got warnings:\n%s", stderr)
+	}
+	if strings.Count(stdout, "users.go") != 1 {
+		t.Errorf("users.go not written once:\n%s", stdout)
+	}
+	if users := readFile(t, modelPath("users")); !strings.Contains(users, "Email") {
+		t.Errorf("the dropped definition was used:\n%s", users)
+	}
+}
@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// embedField is a field of the -embed-struct base struct.
+type embedField struct {
+	Name   string
+	Column string
+	Type   string
+}
+
+// tableEmbeds holds the columns of every table that the -embed-struct base
+// struct replaces.
+var tableEmbeds = make(map[*sqlparser.DDL]map[string]bool)
+
+// snakeCase is the gorm default column name of a field: CreatedAt becomes
+// created_at and ID id.
+func snakeCase(s string) string {
+	rs := []rune(s)
+	var b strings.Builder
+	for i, r := range rs {
+		if unicode.IsUpper(r) && i > 0 &&
+			(unicode.IsLower(rs[i-1]) || unicode.IsDigit(rs[i-1]) || i+1 < len(rs) && unicode.IsLower(rs[i+1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// gormColumn returns the column of a gorm tag, "" if it has none, and
+// whether the tag ignores the field.
+func gormColumn(tag string) (string, bool) {
+	for _, opt := range strings.Split(reflect.StructTag(tag).Get("gorm"), ";") {
+		opt = strings.TrimSpace(opt)
+		if opt == "-" {
+			return "", true
+		}
+		if i := strings.IndexByte(opt, ':'); i >= 0 && strings.EqualFold(opt[:i], "column") {
+			return opt[i+1:], false
+		}
+	}
+	return "", false
+}
+
+// loadEmbedStruct reads the fields of the named struct from the Go files of
+// dir, where the user provides it next to the generated models.
+func loadEmbedStruct(dir, name string) ([]embedField, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || gd.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok || ts.Name.Name != name {
+						continue
+					}
+					var fields []embedField
+					for _, field := range st.Fields.List {
+						tag := ""
+						if field.Tag != nil {
+							tag = strings.Trim(field.Tag.Value, "`")
+						}
+						column, ignored := gormColumn(tag)
+						if ignored {
+							continue
+						}
+						// embedded fields of the base are not matched
+						for _, n := range field.Names {
+							c := column
+							if c == "" {
+								c = snakeCase(n.Name)
+							}
+							fields = append(fields, embedField{Name: n.Name, Column: c, Type: types.ExprString(field.Type)})
+						}
+					}
+					return fields, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("-embed-struct: struct %s not found in %s", name, dir)
+}
+
+// embedColumns returns the columns of ddl the base struct replaces, nil
+// unless the table has a column of the same Go type for every base field.
+func embedColumns(ddl *sqlparser.DDL, fields []embedField) map[string]bool {
+	if len(fields) == 0 {
+		return nil
+	}
+	columns := make(map[string]bool)
+	for _, f := range fields {
+		c := findColumn(ddl, f.Column)
+		if c == nil || GoType(c) != f.Type {
+			return nil
+		}
+		columns[c.Name.String()] = true
+	}
+	return columns
+}
+
+// applyEmbedStruct records in tableEmbeds the tables that get the
+// -embed-struct base struct embedded in place of its columns.
+func applyEmbedStruct(ddls []*sqlparser.DDL) error {
+	byDir := make(map[string][]embedField)
+	for _, ddl := range ddls {
+		dir := packageDir(tableGroup(ddl.NewName.Name.String()))
+		fields, ok := byDir[dir]
+		if !ok {
+			var err error
+			if fields, err = loadEmbedStruct(dir, embedStruct); err != nil {
+				return err
+			}
+			byDir[dir] = fields
+		}
+		if columns := embedColumns(ddl, fields); columns != nil {
+			tableEmbeds[ddl] = columns
+		}
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package generator
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const enumTemplate = `
+{{- range $e := .}}
+{{- if .Set}}
+
+// {{.Type}} is the {{.Table}}.{{.Column}} set.
+type {{.Type}} []string
+
+const (
+{{- range .Members}}
+	{{.Name}} = {{.Value}}
+{{- end}}
+)
+
+func (s {{.Type}}) Value() (driver.Value, error) {
+	return strings.Join(s, ","), nil
+}
+
+func (s *{{.Type}}) Scan(src interface{}) error {
+	var v string
+	switch src := src.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case string:
+		v = src
+	case []byte:
+		v = string(src)
+	default:
+		return fmt.Errorf("{{.Type}}: cannot scan %T", src)
+	}
+	if v == "" {
+		*s = {{.Type}}{}
+		return nil
+	}
+	*s = strings.Split(v, ",")
+	return nil
+}
+{{- else}}
+
+{{if .SQLType}}// {{.Type}} is the {{.SQLType}} enum type.{{else}}// {{.Type}} is the {{.Table}}.{{.Column}} enum.{{end}}
+type {{.Type}} string
+
+const (
+{{- range .Members}}
+	{{.Name}} {{$e.Type}} = {{.Value}}
+{{- end}}
+)
+
+func (e {{.Type}}) Valid() bool {
+	switch e {
+	case {{range $i, $m := .Members}}{{if $i}}, {{end}}{{$m.Name}}{{end}}:
+		return true
+	}
+	return false
+}
+
+func (e {{.Type}}) Value() (driver.Value, error) {
+	return string(e), nil
+}
+
+func (e *{{.Type}}) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*e = ""
+	case string:
+		*e = {{.Type}}(src)
+	case []byte:
+		*e = {{.Type}}(src)
+	default:
+		return fmt.Errorf("{{.Type}}: cannot scan %T", src)
+	}
+	return nil
+}
+{{- end}}
+{{- end}}
+`
+
+const fuzzTemplate = `
+package {{.Package}}
+
+import (
+	"reflect"
+	"testing"
+)
+{{range .Enums}}
+{{- if .Set}}
+// Fuzz{{.Type}} round-trips subsets of the declared members, selected by
+// the bits of mask, through Value and Scan.
+func Fuzz{{.Type}}(f *testing.F) {
+	members := {{.Type}}{ {{- range $i, $m := .Members}}{{if $i}}, {{end}}{{$m.Name}}{{end}}}
+	f.Add(uint64(0))
+	for i := range members {
+		f.Add(uint64(1) << uint(i))
+	}
+	f.Add(uint64(1)<<uint(len(members)) - 1)
+	f.Fuzz(func(t *testing.T, mask uint64) {
+		v := {{.Type}}{}
+		for i, m := range members {
+			if mask&(uint64(1)<<uint(i)) != 0 {
+				v = append(v, m)
+			}
+		}
+		dv, err := v.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got {{.Type}}
+		if err := got.Scan(dv); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, v) {
+			t.Fatalf("round trip %q: got %q", v, got)
+		}
+	})
+}
+{{- else}}
+func Fuzz{{.Type}}(f *testing.F) {
+{{- range .Members}}
+	f.Add(string({{.Name}}))
+{{- end}}
+	f.Fuzz(func(t *testing.T, s string) {
+		v := {{.Type}}(s)
+		dv, err := v.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got {{.Type}}
+		if err := got.Scan(dv); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, v) {
+			t.Fatalf("round trip %q: got %q", v, got)
+		}
+	})
+}
+{{- end}}
+{{end}}`
+
+type enumMember struct {
+	Name  string
+	Value string
+}
+
+type enumType struct {
+	Table  string
+	Column string
+	// SQLType is the Postgres enum type of the column, "" for MySQL enums.
+	SQLType string
+	Type    string
+	Set     bool
+	Members []enumMember
+}
+
+// columnEnumTypes holds the generated Go type of every enum and set column.
+var columnEnumTypes = make(map[*sqlparser.ColumnDefinition]string)
+
+func isEnumColumn(c *sqlparser.ColumnDefinition) bool {
+	return c.Type.Type == "enum" || c.Type.Type == "set"
+}
+
+// applyEnumTypes names the Go type of every enum and set column after its
+// table and column, e.g. UsersStatus, and that of the columns of a Postgres
+// enum type after the type, e.g. OrderStatus for order_status, shared by
+// all its columns.
+func applyEnumTypes(ddls []*sqlparser.DDL) {
+	for _, ddl := range ddls {
+		table := modelName(ddl.NewName.Name.String())
+		for _, c := range ddl.TableSpec.Columns {
+			if name, ok := pgEnumColumns[c]; ok {
+				columnEnumTypes[c] = exportedIdent(ToCamelFirstUpper(name))
+			} else if isEnumColumn(c) {
+				columnEnumTypes[c] = table + fieldName(c)
+			}
+		}
+	}
+}
+
+// underlyingType returns the built-in Go type behind the generated type of a
+// column: string for enums and []string for sets.
+func underlyingType(c *sqlparser.ColumnDefinition) string {
+	if _, ok := columnEnumTypes[c]; ok && columnOverrides[c].Type == "" {
+		if c.Type.Type == "set" {
+			return "[]string"
+		}
+		if pointerColumns[c] {
+			return "*string"
+		}
+		return "string"
+	}
+	return GoType(c)
+}
+
+// enumValues returns the unquoted members of an enum or set column.
+func enumValues(c *sqlparser.ColumnDefinition) []string {
+	values := make([]string, 0, len(c.Type.EnumValues))
+	for _, v := range c.Type.EnumValues {
+		v = strings.TrimSuffix(strings.TrimPrefix(v, "'"), "'")
+		values = append(values, strings.Replace(v, "''", "'", -1))
+	}
+	return values
+}
+
+func enumConstName(typeName string, value string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, value)
+	name = ToCamelFirstUpper(name)
+	if name == "" {
+		name = "Empty"
+	}
+	return typeName + name
+}
+
+func tableEnums(ddl *sqlparser.DDL) []enumType {
+	var enums []enumType
+	for _, c := range ddl.TableSpec.Columns {
+		typeName, ok := columnEnumTypes[c]
+		if !ok || columnOverrides[c].Type != "" {
+			continue
+		}
+		e := enumType{
+			Table:   ddl.NewName.Name.String(),
+			Column:  c.Name.String(),
+			SQLType: pgEnumColumns[c],
+			Type:    typeName,
+			Set:     c.Type.Type == "set",
+		}
+		used := make(map[string]bool)
+		for _, v := range enumValues(c) {
+			name := enumConstName(typeName, v)
+			for used[name] {
+				name += "_"
+			}
+			used[name] = true
+			e.Members = append(e.Members, enumMember{Name: name, Value: strconv.Quote(v)})
+		}
+		enums = append(enums, e)
+	}
+	return enums
+}
+
+func enumImports(enums []enumType) []string {
+	if len(enums) == 0 {
+		return nil
+	}
+	paths := []string{"database/sql/driver", "fmt"}
+	for _, e := range enums {
+		if e.Set {
+			return append(paths, "strings")
+		}
+	}
+	return paths
+}
+
+func genEnumTypes(enums []enumType) string {
+	var buf bytes.Buffer
+	_ = template.Must(template.New("enum").Parse(enumTemplate)).Execute(&buf, enums)
+	return buf.String()
+}
+
+func genFuzz(pkg string, enums []enumType) string {
+	params := struct {
+		Package string
+		Enums   []enumType
+	}{
+		Package: pkg,
+		Enums:   enums,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("fuzz").Parse(fuzzTemplate)).Execute(&buf, params)
+	return buf.String()
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const fuzzSchema = "CREATE TABLE `orders` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `state` enum('new','paid','shipped') NOT NULL,\n" +
+	"  `flags` set('gift','rush') NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");\n" +
+	"CREATE TABLE `notes` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `body` text NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");"
+
+func TestGenFuzz(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", fuzzSchema)
+	if err := run("-gen-fuzz", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	fuzz := gofmt(t, readFile(t, "model/orders_fuzz_test.go"))
+	for _, want := range []string{
+		"func FuzzOrdersState(f *testing.F) {",
+		"f.Add(string(OrdersStateShipped))",
+		"func FuzzOrdersFlags(f *testing.F) {",
+		"members := OrdersFlags{OrdersFlagsGift, OrdersFlagsRush}",
+	} {
+		if !strings.Contains(fuzz, want) {
+			t.Errorf("orders_fuzz_test.go lacks %q:\n%s", want, fuzz)
+		}
+	}
+	// no custom types, no fuzz targets
+	if _, err := os.Stat("model/notes_fuzz_test.go"); err == nil {
+		t.Error("notes_fuzz_test.go written for a table without enums")
+	}
+	goTestFlags(t, []string{"-run", "Fuzz", "-fuzztime", "1x"}, "./model")
+	goTestFlags(t, []string{"-run", "^$", "-fuzz", "^FuzzOrdersFlags$", "-fuzztime", "1x"}, "./model")
+}
+
+func TestGenFuzzTypesFile(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", fuzzSchema)
+	if err := run("-gen-fuzz", "-types-file", "types.go", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("model/orders_fuzz_test.go"); err == nil {
+		t.Error("orders_fuzz_test.go written with -types-file")
+	}
+	fuzz := readFile(t, "model/types_fuzz_test.go")
+	for _, want := range []string{"func FuzzOrdersState(", "func FuzzOrdersFlags("} {
+		if !strings.Contains(fuzz, want) {
+			t.Errorf("types_fuzz_test.go lacks %s:\n%s", want, fuzz)
+		}
+	}
+	goTestFlags(t, []string{"-run", "Fuzz", "-fuzztime", "1x"}, "./model")
+}
+
+func TestGenFuzzOff(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", fuzzSchema)
+	if err := run("schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("model/orders_fuzz_test.go"); err == nil {
+		t.Error("orders_fuzz_test.go written without -gen-fuzz")
+	}
+}
@@ -0,0 +1,358 @@
+package generator
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const eventsTemplate = `
+package {{.Package}}
+
+import (
+{{- range std .Imports}}
+	"{{.}}"
+{{- end}}
+{{- with thirdParty .Imports}}
+{{range .}}
+	"{{.}}"
+{{- end}}
+{{- end}}
+)
+
+// {{.TableName}}ChangeEvent is a Debezium change event of {{.TableNameStr}}.
+type {{.TableName}}ChangeEvent struct {
+	// Before is nil for creates and snapshot reads.
+	Before *{{.TableName}}
+	// After is nil for deletes.
+	After *{{.TableName}}
+	// Op is the Debezium operation: c, u, d, r or t.
+	Op       string
+	SourceTs time.Time
+}
+
+// {{.Row}} is a {{.TableNameStr}} row as encoded by Debezium, keyed by the
+// raw column names.
+type {{.Row}} struct {
+{{- range .Fields}}
+	{{.Field}} {{.RowType}} ` + "`" + `json:"{{.Name}}"` + "`" + `
+{{- end}}
+}
+
+// UnmarshalDebezium decodes a Debezium JSON change event of {{.TableNameStr}},
+// with or without the schema envelope. A tombstone, the empty or null event
+// following a delete, decodes to the zero event.
+func (e *{{.TableName}}ChangeEvent) UnmarshalDebezium(payload []byte) error {
+	if p := bytes.TrimSpace(payload); len(p) == 0 || string(p) == "null" {
+		*e = {{.TableName}}ChangeEvent{}
+		return nil
+	}
+	env, err := decodeDebeziumEnvelope(payload)
+	if err != nil {
+		return err
+	}
+	if e.Before, err = decode{{.TableName}}DebeziumRow(env.Before); err != nil {
+		return err
+	}
+	if e.After, err = decode{{.TableName}}DebeziumRow(env.After); err != nil {
+		return err
+	}
+	e.Op = env.Op
+	e.SourceTs = time.Unix(0, env.Source.TsMs*int64(time.Millisecond))
+	return nil
+}
+
+func decode{{.TableName}}DebeziumRow(raw json.RawMessage) (*{{.TableName}}, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var r {{.Row}}
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, err
+	}
+	// assigned field by field, fields may be promoted from an embedded struct
+	m := &{{.TableName}}{}
+{{- range .Fields}}{{if not .Convert}}
+	m.{{.Field}} = r.{{.Field}}
+{{- end}}{{end}}
+	var err error
+{{- range .Fields}}
+{{- if eq .Convert "time"}}
+	if m.{{.Field}}, err = debeziumTime(r.{{.Field}}, {{.Unit}}); err != nil {
+		return nil, err
+	}
+{{- else if eq .Convert "timeptr"}}
+	if len(r.{{.Field}}) > 0 && string(r.{{.Field}}) != "null" {
+		t, err := debeziumTime(r.{{.Field}}, {{.Unit}})
+		if err != nil {
+			return nil, err
+		}
+		m.{{.Field}} = &t
+	}
+{{- else if eq .Convert "scan"}}
+	if err = m.{{.Field}}.Scan(r.{{.Field}}); err != nil {
+		return nil, err
+	}
+{{- else if eq .Convert "json"}}
+	m.{{.Field}} = debeziumJSON(r.{{.Field}})
+{{- else if eq .Convert "decimal"}}
+	if text, err := debeziumDecimal(r.{{.Field}}, {{.Scale}}); err != nil {
+		return nil, err
+	} else if text != "" {
+	{{- if eq .Kind "float64" "*float64"}}
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, err
+		}
+		m.{{.Field}} = {{if eq .Kind "*float64"}}&{{end}}v
+	{{- else if eq .Kind "string" "*string"}}
+		m.{{.Field}} = {{if eq .Kind "*string"}}&{{end}}text
+	{{- else}}
+		{{- if eq (slice .Kind 0 1) "*"}}
+		m.{{.Field}} = new({{slice .Kind 1}})
+		{{- end}}
+		if err := m.{{.Field}}.Scan(text); err != nil {
+			return nil, err
+		}
+	{{- end}}
+	}
+{{- end}}
+{{- end}}
+	return m, err
+}
+`
+
+const eventsHelpersTemplate = `
+package {{.Package}}
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+type debeziumEnvelope struct {
+	Before json.RawMessage ` + "`" + `json:"before"` + "`" + `
+	After  json.RawMessage ` + "`" + `json:"after"` + "`" + `
+	Op     string          ` + "`" + `json:"op"` + "`" + `
+	Source struct {
+		TsMs int64 ` + "`" + `json:"ts_ms"` + "`" + `
+	} ` + "`" + `json:"source"` + "`" + `
+}
+
+// decodeDebeziumEnvelope decodes the payload of a Debezium change event,
+// unwrapping it from the schema envelope of the JSON converter if needed.
+func decodeDebeziumEnvelope(payload []byte) (debeziumEnvelope, error) {
+	var env struct {
+		debeziumEnvelope
+		Payload json.RawMessage ` + "`" + `json:"payload"` + "`" + `
+	}
+	if err := json.Unmarshal(payload, &env); err != nil {
+		return debeziumEnvelope{}, err
+	}
+	if env.Op != "" || len(env.Payload) == 0 {
+		return env.debeziumEnvelope, nil
+	}
+	var inner debeziumEnvelope
+	err := json.Unmarshal(env.Payload, &inner)
+	return inner, err
+}
+
+// debeziumTime decodes a Debezium temporal value, either an ISO-8601 string
+// (ZonedTimestamp) or a count of unit since the epoch (Date, Timestamp,
+// MicroTimestamp).
+func debeziumTime(raw json.RawMessage, unit time.Duration) (time.Time, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return time.Time{}, nil
+	}
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return time.Time{}, err
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	var n int64
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, 0).Add(time.Duration(n) * unit).UTC(), nil
+}
+
+// debeziumJSON decodes a Debezium JSON column, which arrives as a string
+// holding the JSON text.
+func debeziumJSON(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return json.RawMessage(s)
+	}
+	return append(json.RawMessage(nil), raw...)
+}
+
+// debeziumDecimal decodes a Debezium DECIMAL value of scale digits after the
+// point into its text form, "" for NULL. It is a number in the double
+// decimal.handling.mode and a string, e.g. "12.50", in the string mode. In
+// the default precise mode it is the base64 of the big-endian two's
+// complement unscaled value, e.g. "BOI=" for 12.50, wrapped with its scale
+// in an object for the Postgres numeric columns of no declared scale.
+func debeziumDecimal(raw json.RawMessage, scale int) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	var s string
+	switch raw[0] {
+	case '"':
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", err
+		}
+		// base64 has no point nor minus sign, a string of digits only is
+		// taken as base64
+		if strings.ContainsAny(s, ".-") {
+			return s, nil
+		}
+	case '{':
+		var v struct {
+			Scale int    ` + "`" + `json:"scale"` + "`" + `
+			Value string ` + "`" + `json:"value"` + "`" + `
+		}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return "", err
+		}
+		s, scale = v.Value, v.Scale
+	default:
+		return string(raw), nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("decimal %s: %v", raw, err)
+	}
+	n := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	digits := new(big.Int).Abs(n).String()
+	if scale > 0 {
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if n.Sign() < 0 {
+		digits = "-" + digits
+	}
+	return digits, nil
+}
+`
+
+type eventField struct {
+	Name    string
+	Field   string
+	RowType string
+	// Convert is how the model field is decoded from the row field: "" for
+	// a plain copy, "time" and "timeptr" for Debezium temporals, "scan" for
+	// sets, "json" for JSON columns and "decimal" for DECIMAL columns.
+	Convert string
+	Unit    string
+	// Kind is the underlying type of the field of a DECIMAL column, which
+	// is parsed from the decimal text unless a string, or else scanned from
+	// it, as decimal.Decimal does.
+	Kind  string
+	Scale int
+}
+
+// debeziumTimeUnit returns the unit of the numeric Debezium encoding of a
+// temporal column under the default adaptive time precision mode.
+func debeziumTimeUnit(c *sqlparser.ColumnDefinition) string {
+	if c.Type.Type == "date" {
+		return "24 * time.Hour"
+	}
+	fsp := 0
+	if columnDialect(c) == "postgres" {
+		fsp = 6
+	}
+	if c.Type.Length != nil {
+		fsp, _ = strconv.Atoi(string(c.Type.Length.Val))
+	}
+	if fsp <= 3 {
+		return "time.Millisecond"
+	}
+	return "time.Microsecond"
+}
+
+func genEvents(pkg string, ddl *sqlparser.DDL) string {
+	tableNameStr := ddl.NewName.Name.String()
+	tableName := modelName(tableNameStr)
+
+	var fields []eventField
+	var types, imports []string
+	for _, c := range ddl.TableSpec.Columns {
+		col := newDALColumn(c)
+		f := eventField{Name: col.Name, Field: col.Field, RowType: col.Type}
+		switch {
+		case col.Kind == "time.Time":
+			f.RowType, f.Convert, f.Unit = "json.RawMessage", "time", debeziumTimeUnit(c)
+		case col.Kind == "*time.Time":
+			f.RowType, f.Convert, f.Unit = "json.RawMessage", "timeptr", debeziumTimeUnit(c)
+		case col.Kind == "[]string" && col.Type != col.Kind:
+			// Debezium sends sets as their comma separated string
+			f.RowType, f.Convert = "string", "scan"
+		case col.Kind == "json.RawMessage":
+			f.RowType, f.Convert = "json.RawMessage", "json"
+		case c.Type.Type == "decimal" || c.Type.Type == "numeric":
+			f.RowType, f.Convert, f.Kind = "json.RawMessage", "decimal", col.Kind
+			if c.Type.Scale != nil {
+				f.Scale, _ = strconv.Atoi(string(c.Type.Scale.Val))
+			}
+			switch col.Kind {
+			case "float64", "*float64":
+				imports = append(imports, "strconv")
+			case "string", "*string":
+			default:
+				if strings.HasPrefix(col.Kind, "*") {
+					// allocated by new before the Scan
+					types = append(types, col.Type)
+				}
+			}
+		default:
+			types = append(types, col.Type)
+		}
+		fields = append(fields, f)
+	}
+
+	imports = append(imports, "bytes", "encoding/json", "time")
+	imports = append(imports, importsOf(types...)...)
+	params := struct {
+		Package      string
+		TableName    string
+		TableNameStr string
+		Row          string
+		Fields       []eventField
+		Imports      []string
+	}{
+		Package:      pkg,
+		TableName:    tableName,
+		TableNameStr: tableNameStr,
+		Row:          lowerFirst(tableName) + "DebeziumRow",
+		Fields:       fields,
+		Imports:      uniqueSorted(imports),
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("events").Funcs(importFuncs).Parse(eventsTemplate)).Execute(&buf, params)
+	return buf.String()
+}
+
+func genEventsHelpers(pkg string) string {
+	var buf bytes.Buffer
+	_ = template.Must(template.New("events_helpers").Parse(eventsHelpersTemplate)).Execute(&buf, struct{ Package string }{pkg})
+	return buf.String()
+}
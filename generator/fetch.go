@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+func isURL(file string) bool {
+	return strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://")
+}
+
+// displayURL is the URL of an input without its password, for messages.
+func displayURL(file string) string {
+	u, err := url.Parse(file)
+	if err != nil {
+		return file
+	}
+	return u.Redacted()
+}
+
+// fetchInput downloads a schema given as an http or https URL, within
+// -http-timeout, as the bearer of the token in the -bearer-token-env
+// variable, and verifies it against -sha256.
+func fetchInput(file string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, file, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", displayURL(file), err)
+	}
+	if bearerTokenEnv != "" {
+		token := os.Getenv(bearerTokenEnv)
+		if token == "" {
+			return nil, fmt.Errorf("-bearer-token-env: $%s is not set", bearerTokenEnv)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	client := &http.Client{Timeout: httpTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		// the url.Error of the client already names the URL
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", displayURL(file), resp.Status)
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %v", displayURL(file), err)
+	}
+	if schemaSHA256 != "" {
+		sum := sha256.Sum256(content)
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, schemaSHA256) {
+			return nil, fmt.Errorf("%s: sha256 %s does not match -sha256 %s", displayURL(file), got, schemaSHA256)
+		}
+	}
+	return content, nil
+}
+
+// checkSHA256 fails unless -sha256 is given with exactly one URL input,
+// the one it verifies.
+func checkSHA256(files []string) error {
+	if schemaSHA256 == "" {
+		return nil
+	}
+	if _, err := hex.DecodeString(schemaSHA256); err != nil || len(schemaSHA256) != 2*sha256.Size {
+		return fmt.Errorf("-sha256 %q is not a hex encoded SHA-256 checksum", schemaSHA256)
+	}
+	urls := 0
+	for _, file := range files {
+		if isURL(file) {
+			urls++
+		}
+	}
+	if urls != 1 {
+		return fmt.Errorf("-sha256 verifies a single URL input, got %d", urls)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fetchSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (id)
+);`
+
+// schemaServer serves fetchSchema at /schema.sql to the bearer of token,
+// and hangs at /slow.
+func schemaServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "no token", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/schema.sql":
+			w.Write([]byte(fetchSchema))
+		case "/slow":
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(func() {
+		close(done)
+		srv.Close()
+	})
+	return srv
+}
+
+func fetchSum() string {
+	sum := sha256.Sum256([]byte(fetchSchema))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFetchInput(t *testing.T) {
+	srv := schemaServer(t, "s3cret")
+	t.Setenv("SCHEMA_TOKEN", "s3cret")
+	chdir(t)
+	if err := run("-bearer-token-env", "SCHEMA_TOKEN", "-sha256", strings.ToUpper(fetchSum()), srv.URL+"/schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if users := readFile(t, modelPath("users")); !strings.Contains(users, "type Users struct") {
+		t.Errorf("got:\n%s", users)
+	}
+}
+
+func TestFetchInputErrors(t *testing.T) {
+	srv := schemaServer(t, "s3cret")
+	t.Setenv("SCHEMA_TOKEN", "s3cret")
+	wrongSum := strings.Repeat("0", 64)
+	for _, tt := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"not found", []string{"-bearer-token-env", "SCHEMA_TOKEN", srv.URL + "/missing.sql"},
+			"GET " + srv.URL + "/missing.sql: 404 Not Found"},
+		{"checksum mismatch", []string{"-bearer-token-env", "SCHEMA_TOKEN", "-sha256", wrongSum, srv.URL + "/schema.sql"},
+			srv.URL + "/schema.sql: sha256 " + fetchSum() + " does not match -sha256 " + wrongSum},
+		{"no token", []string{srv.URL + "/schema.sql"},
+			"GET " + srv.URL + "/schema.sql: 401 Unauthorized"},
+		{"token unset", []string{"-bearer-token-env", "NO_SUCH_TOKEN", srv.URL + "/schema.sql"},
+			"-bearer-token-env: $NO_SUCH_TOKEN is not set"},
+		{"timeout", []string{"-bearer-token-env", "SCHEMA_TOKEN", "-http-timeout", "50ms", srv.URL + "/slow"},
+			"Client.Timeout exceeded"},
+		{"bad checksum", []string{"-sha256", "abc", srv.URL + "/schema.sql"},
+			`-sha256 "abc" is not a hex encoded SHA-256 checksum`},
+		{"checksum of a file", []string{"-sha256", wrongSum, "schema.sql"},
+			"-sha256 verifies a single URL input, got 0"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t)
+			writeFile(t, "schema.sql", fetchSchema)
+			err := run(tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+			if _, err := os.Stat("model"); err == nil {
+				t.Error("files written despite the error")
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// columnFields holds the Go field names that differ from the camel cased
+// column name, see applyFieldNames.
+var columnFields = make(map[*sqlparser.ColumnDefinition]string)
+
+// fieldName returns the Go field name of a column.
+func fieldName(c *sqlparser.ColumnDefinition) string {
+	if f, ok := columnFields[c]; ok {
+		return f
+	}
+	return exportedIdent(ToCamelFirstUpper(c.Name.String()))
+}
+
+// applyFieldNames renames the fields that would collide with a generated
+// model method, e.g. the column table_name with TableName(), to
+// TableNameField, and the columns camel casing to the same field, e.g.
+// user_name and userName, to UserName and UserName2, with a warning. The
+// json and column tags keep the column name. The -type-map renames are
+// used as is; one colliding with a method or another field is an error.
+func applyFieldNames(ddls []*sqlparser.DDL) error {
+	methods := make(map[string]bool)
+	for _, m := range modelMethods {
+		methods[m] = true
+	}
+	for _, ddl := range ddls {
+		table := ddl.NewName.Name.String()
+		used := make(map[string]string)
+		renamed := make(map[string]bool)
+		for _, c := range ddl.TableSpec.Columns {
+			column, name := c.Name.String(), columnOverrides[c].Rename
+			if name == "" {
+				continue
+			}
+			if methods[name] {
+				return fmt.Errorf("table %s: rename %s of column %s collides with the %s method", table, name, column, name)
+			}
+			if other, ok := used[name]; ok {
+				return fmt.Errorf("table %s: columns %s and %s are both renamed %s", table, other, column, name)
+			}
+			used[name] = column
+			renamed[column] = true
+			columnFields[c] = name
+		}
+		for _, c := range ddl.TableSpec.Columns {
+			column := c.Name.String()
+			if renamed[column] {
+				continue
+			}
+			field := exportedIdent(ToCamelFirstUpper(column))
+			name := field
+			if methods[name] {
+				name += "Field"
+				fmt.Fprintf(os.Stderr, "warning: table %s: field %s of column %s collides with the %s method, using %s\n", table, field, column, field, name)
+			}
+			if other, ok := used[name]; ok && renamed[other] {
+				return fmt.Errorf("table %s: rename %s of column %s collides with the field of column %s", table, name, other, column)
+			} else if ok {
+				base := name
+				for n := 2; used[name] != "" || methods[name]; n++ {
+					name = base + strconv.Itoa(n)
+				}
+				fmt.Fprintf(os.Stderr, "warning: table %s: columns %s and %s both camel case to %s, using %s for %s\n", table, other, column, base, name, column)
+			}
+			used[name] = column
+			if name != field {
+				columnFields[c] = name
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const collidingSchema = "CREATE TABLE docs (\n" +
+	"  id bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  table_name varchar(64) NOT NULL,\n" +
+	"  `type` varchar(16) NOT NULL,\n" +
+	"  `func` varchar(16) NOT NULL,\n" +
+	"  `range` int NOT NULL,\n" +
+	"  user_id bigint NOT NULL,\n" +
+	"  userId bigint NOT NULL,\n" +
+	"  PRIMARY KEY (id)\n" +
+	");"
+
+const fieldNamesTest = `package model
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRenamedFields(t *testing.T) {
+	if got := (Docs{}).TableName(); got != "docs" {
+		t.Errorf("TableName() = %s, want docs", got)
+	}
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Docs{}); err != nil {
+		t.Fatal(err)
+	}
+	in := Docs{TableNameField: "t", Type: "a", Func: "f", Range: 3, UserID: 1, UserID2: 2}
+	if err := db.Create(&in).Error; err != nil {
+		t.Fatal(err)
+	}
+	// the renamed fields are written to the real columns
+	var tableName string
+	var userID, userID2 int64
+	if err := db.Raw("SELECT table_name, user_id, userId FROM docs").Row().Scan(&tableName, &userID, &userID2); err != nil {
+		t.Fatal(err)
+	}
+	if tableName != "t" || userID != 1 || userID2 != 2 {
+		t.Errorf("got %s, %d and %d", tableName, userID, userID2)
+	}
+	var got Docs
+	if err := db.First(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got != in {
+		t.Errorf("got %+v, want %+v", got, in)
+	}
+}
+`
+
+func TestFieldNameCollisions(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", collidingSchema)
+	_, stderr, err := capture(t, func() error { return run("schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "warning: table docs: field TableName of column table_name collides with the TableName method, using TableNameField\n" +
+		"warning: table docs: columns user_id and userId both camel case to UserID, using UserID2 for userId\n"
+	if stderr != want {
+		t.Errorf("got warnings\n%s\nwant\n%s", stderr, want)
+	}
+	docs := gofmt(t, readFile(t, modelPath("docs")))
+	for _, field := range []string{
+		"TableNameField string `gorm:\"Column:table_name;size:64\" json:\"table_name\"`",
+		// keywords are capitalized out of the way
+		"Type           string `gorm:\"Column:type;size:16\" json:\"type\"`",
+		"Func           string `gorm:\"Column:func;size:16\" json:\"func\"`",
+		"Range          int    `gorm:\"Column:range\" json:\"range\"`",
+		"UserID         int64  `gorm:\"Column:user_id\" json:\"user_id\"`",
+		"UserID2        int64  `gorm:\"Column:userId\" json:\"userId\"`",
+	} {
+		if !strings.Contains(docs, field) {
+			t.Errorf("no %s in:\n%s", field, docs)
+		}
+	}
+	writeFile(t, "model/fieldnames_test.go", fieldNamesTest)
+	goTest(t, "./model")
+}
+
+func TestFieldRenameCollisions(t *testing.T) {
+	for _, tt := range []struct {
+		columns string
+		want    string
+	}{
+		{`"docs.user_id": {"rename": "TableName"}`, "table docs: rename TableName of column user_id collides with the TableName method"},
+		{`"docs.user_id": {"rename": "Owner"}, "docs.userId": {"rename": "Owner"}`, "table docs: columns user_id and userId are both renamed Owner"},
+		{`"docs.userId": {"rename": "Type"}`, "table docs: rename Type of column userId collides with the field of column type"},
+	} {
+		chdir(t)
+		writeFile(t, "schema.sql", collidingSchema)
+		writeFile(t, "types.json", `{"columns": {`+tt.columns+`}}`)
+		_, _, err := capture(t, func() error { return run("-type-map", "types.json", "schema.sql") })
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.columns, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const fieldsValuesTemplate = `
+package {{.Package}}
+
+// Fields returns the columns of {{.TableNameStr}} in the order of Values.
+func ({{.TableName}}) Fields() []string {
+	return []string{
+{{- range .Columns}}
+		"{{.Name}}",
+{{- end}}
+	}
+}
+
+// Values returns pointers to the fields of m in the order of Fields, for
+// scanning rows into m and as the arguments of an insert.
+func (m *{{.TableName}}) Values() []interface{} {
+	return []interface{}{
+{{- range .Columns}}
+		&m.{{.Field}},
+{{- end}}
+	}
+}
+`
+
+func genFieldsValues(pkg string, ddl *sqlparser.DDL) string {
+	cols := make([]dalColumn, 0, len(ddl.TableSpec.Columns))
+	for _, c := range ddl.TableSpec.Columns {
+		cols = append(cols, newDALColumn(c))
+	}
+	tableNameStr := ddl.NewName.Name.String()
+	params := struct {
+		Package      string
+		TableName    string
+		TableNameStr string
+		Columns      []dalColumn
+	}{
+		Package:      pkg,
+		TableName:    modelName(tableNameStr),
+		TableNameStr: tableNameStr,
+		Columns:      cols,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("fieldsvalues").Parse(fieldsValuesTemplate)).Execute(&buf, params)
+	return buf.String()
+}
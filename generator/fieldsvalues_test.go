@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const fieldsValuesSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  nickname varchar(64),
+  age int,
+  avatar blob,
+  born_at datetime,
+  PRIMARY KEY (id)
+);`
+
+const fieldsValuesTest = `package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestFieldsValuesLineUp checks that the i-th value points to the i-th
+// field of the struct, whose column is the i-th of Fields.
+func TestFieldsValuesLineUp(t *testing.T) {
+	var m Users
+	fields, values := m.Fields(), m.Values()
+	v := reflect.ValueOf(&m).Elem()
+	if len(fields) != v.NumField() || len(values) != v.NumField() {
+		t.Fatalf("%d fields and %d values for %d struct fields", len(fields), len(values), v.NumField())
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if want := "Column:" + fields[i]; !strings.HasPrefix(f.Tag.Get("gorm"), want) {
+			t.Errorf("field %d %s has gorm tag %q, want %s", i, f.Name, f.Tag.Get("gorm"), want)
+		}
+		if p := reflect.ValueOf(values[i]); p.Pointer() != v.Field(i).Addr().Pointer() {
+			t.Errorf("value %d does not point to %s", i, f.Name)
+		}
+	}
+}
+
+func TestFieldsValuesSQL(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Users{}); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nickname, born := "ann", time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+	in := Users{ID: 1, Email: "a@example.com", Nickname: &nickname, Avatar: []byte{1, 2}, BornAt: &born}
+	columns := in.Fields()
+	marks := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	if _, err := sqlDB.Exec("INSERT INTO users ("+strings.Join(columns, ", ")+") VALUES ("+marks+")", in.Values()...); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := sqlDB.Query("SELECT " + strings.Join(columns, ", ") + " FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal("no row")
+	}
+	var out Users
+	if err := rows.Scan(out.Values()...); err != nil {
+		t.Fatal(err)
+	}
+	if out.ID != 1 || out.Email != in.Email || *out.Nickname != "ann" || out.Age != nil ||
+		!reflect.DeepEqual(out.Avatar, in.Avatar) || !out.BornAt.Equal(born) {
+		t.Errorf("read back %+v, want %+v", out, in)
+	}
+}
+`
+
+func TestFieldsValues(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", fieldsValuesSchema)
+	if err := run("-gen-fieldsvalues", "-nullable-pointers", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	fv := gofmt(t, readFile(t, "model/users_fieldsvalues.go"))
+	want := "return []string{\n\t\t\"id\",\n\t\t\"email\",\n\t\t\"nickname\",\n\t\t\"age\",\n\t\t\"avatar\",\n\t\t\"born_at\",\n\t}"
+	if !strings.Contains(fv, want) {
+		t.Errorf("the columns are not in the DDL order:\n%s", fv)
+	}
+	writeFile(t, "model/fieldsvalues_test.go", fieldsValuesTest)
+	goTest(t, "./model")
+}
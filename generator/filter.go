@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const ignoreFile = ".dalgenignore"
+
+// readIgnoreFile returns the table name globs listed in .dalgenignore in the
+// working directory, one per line, skipping blank lines and # comments.
+func readIgnoreFile() ([]string, error) {
+	f, err := os.Open(ignoreFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// filterTables drops the tables matching -exclude-tables or .dalgenignore
+// and those the -config file skips.
+func filterTables(ddls []*sqlparser.DDL) ([]*sqlparser.DDL, error) {
+	patterns, err := readIgnoreFile()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range strings.Split(excludeTables, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	kept := ddls[:0]
+	for _, ddl := range ddls {
+		if name := ddl.NewName.Name.String(); !matchAny(patterns, name) && !configSkipped(name) {
+			kept = append(kept, ddl)
+		}
+	}
+	return kept, nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"os"
+	"testing"
+)
+
+const logSchema = `CREATE TABLE users (
+  id bigint NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE TABLE audit_log (
+  id bigint NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE TABLE login_log (
+  id bigint NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE TABLE logins (
+  id bigint NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+func checkGenerated(t *testing.T, tables map[string]bool) {
+	t.Helper()
+	for table, want := range tables {
+		_, err := os.Stat(modelPath(table))
+		if got := err == nil; got != want {
+			t.Errorf("%s generated: %v, want %v", table, got, want)
+		}
+	}
+}
+
+func TestIgnoreFile(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", logSchema)
+	writeFile(t, ignoreFile, "# append-only tables\n\n*_log\n")
+	if err := run("schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	checkGenerated(t, map[string]bool{"users": true, "logins": true, "audit_log": false, "login_log": false})
+}
+
+func TestIgnoreFileAndExcludeTables(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", logSchema)
+	writeFile(t, ignoreFile, "audit_log\n")
+	if err := run("-exclude-tables", "login*, users", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	checkGenerated(t, map[string]bool{"users": false, "logins": false, "audit_log": false, "login_log": false})
+}
+
+func TestIgnoreFileBadPattern(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", logSchema)
+	writeFile(t, ignoreFile, "[users\n")
+	if err := run("schema.sql"); err == nil {
+		t.Fatal("no error on a malformed pattern")
+	}
+	checkGenerated(t, map[string]bool{"users": false})
+}
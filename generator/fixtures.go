@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const fixturesTemplate = `
+package {{.Package}}
+
+import "gorm.io/gorm"
+
+// {{.TableName}}Fixtures are the {{.TableNameStr}} rows Seed{{.TableName}} inserts, for
+// integration tests to fill in.
+var {{.TableName}}Fixtures = []{{.TableName}}{}
+
+// Seed{{.TableName}} inserts {{.TableName}}Fixtures into db, filling in their
+// generated primary keys.
+func Seed{{.TableName}}(db *gorm.DB) error {
+	if len({{.TableName}}Fixtures) == 0 {
+		return nil
+	}
+	return db.Create(&{{.TableName}}Fixtures).Error
+}
+`
+
+func genFixtures(pkg string, ddl *sqlparser.DDL) string {
+	tableNameStr := ddl.NewName.Name.String()
+	params := struct {
+		Package      string
+		TableName    string
+		TableNameStr string
+	}{
+		Package:      pkg,
+		TableName:    modelName(tableNameStr),
+		TableNameStr: tableNameStr,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("fixtures").Parse(fixturesTemplate)).Execute(&buf, params)
+	return buf.String()
+}
@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const fixturesTest = `package model
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSeed(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&User{}); err != nil {
+		t.Fatal(err)
+	}
+	// nothing to insert
+	if err := SeedUser(db); err != nil {
+		t.Fatal(err)
+	}
+	UserFixtures = []User{{Email: "a@example.com"}, {Email: "b@example.com"}}
+	if err := SeedUser(db); err != nil {
+		t.Fatal(err)
+	}
+	if UserFixtures[0].ID != 1 || UserFixtures[1].ID != 2 {
+		t.Errorf("primary keys not filled in: %+v", UserFixtures)
+	}
+	var n int64
+	if err := db.Model(&User{}).Count(&n).Error; err != nil || n != 2 {
+		t.Errorf("got %d rows, %v, want 2", n, err)
+	}
+}
+`
+
+func TestFixtures(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", strings.Replace(usersSchema, "TABLE users", "TABLE t_users", 1))
+	if err := run("-gen-fixtures", "-trim-prefix", "t_", "-singularize", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	fixtures := gofmt(t, readFile(t, "model/users_fixtures.go"))
+	for _, want := range []string{
+		"var UserFixtures = []User{}",
+		"func SeedUser(db *gorm.DB) error {",
+		"return db.Create(&UserFixtures).Error",
+	} {
+		if !strings.Contains(fixtures, want) {
+			t.Errorf("users_fixtures.go lacks %s:\n%s", want, fixtures)
+		}
+	}
+	writeFile(t, "model/fixtures_test.go", fixturesTest)
+	goTest(t, "./model")
+}
@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+type foreignKey struct {
+	// Name is the constraint symbol, "" if unnamed.
+	Name       string
+	Columns    []string
+	RefSchema  string
+	RefTable   string
+	RefColumns []string
+}
+
+// tableForeignKeys holds the FOREIGN KEY constraints of every parsed table,
+// which the sql parser does not support and ParseSQLs strips beforehand.
+var tableForeignKeys = make(map[*sqlparser.DDL][]foreignKey)
+
+// sqlColumnList parses a parenthesized column list, e.g. "(`a`, b)".
+func sqlColumnList(s string) ([]string, string, error) {
+	s = strings.TrimSpace(s)
+	end := strings.IndexByte(s, ')')
+	if !strings.HasPrefix(s, "(") || end < 0 {
+		return nil, "", fmt.Errorf("expected column list at %q", s)
+	}
+	var cols []string
+	for _, c := range strings.Split(s[1:end], ",") {
+		name, _ := sqlWord(c)
+		cols = append(cols, name)
+	}
+	return cols, s[end+1:], nil
+}
+
+// parseForeignKey parses a [CONSTRAINT [symbol]] FOREIGN KEY clause. ok is
+// false when def is not a foreign key.
+func parseForeignKey(def string) (fk foreignKey, ok bool, err error) {
+	rest := def
+	if r, ok := sqlKeyword(rest, "constraint"); ok {
+		rest = r
+		if _, ok := sqlKeyword(rest, "foreign"); !ok {
+			fk.Name, rest = sqlWord(rest)
+		}
+	}
+	rest, ok = sqlKeyword(rest, "foreign", "key")
+	if !ok {
+		return fk, false, nil
+	}
+	if !strings.HasPrefix(strings.TrimSpace(rest), "(") {
+		// index name
+		_, rest = sqlWord(rest)
+	}
+	if fk.Columns, rest, err = sqlColumnList(rest); err != nil {
+		return fk, true, err
+	}
+	rest, ok = sqlKeyword(rest, "references")
+	if !ok {
+		return fk, true, fmt.Errorf("foreign key without REFERENCES: %q", strings.TrimSpace(def))
+	}
+	fk.RefTable, rest = sqlWord(rest)
+	if rest = strings.TrimSpace(rest); strings.HasPrefix(rest, ".") {
+		fk.RefSchema = fk.RefTable
+		fk.RefTable, rest = sqlWord(rest[1:])
+	} else if i := strings.IndexByte(fk.RefTable, '.'); i >= 0 {
+		fk.RefSchema, fk.RefTable = fk.RefTable[:i], fk.RefTable[i+1:]
+	}
+	if fk.RefColumns, _, err = sqlColumnList(rest); err != nil {
+		return fk, true, err
+	}
+	if len(fk.RefColumns) != len(fk.Columns) {
+		return fk, true, fmt.Errorf("foreign key %v references %v", fk.Columns, fk.RefColumns)
+	}
+	return fk, true, nil
+}
+
+// createTableBody returns the offsets of the parentheses around the
+// definitions of a CREATE TABLE statement, ok is false for other statements.
+func createTableBody(stmt string) (open int, end int, ok bool) {
+	if _, ok := sqlKeyword(stmt, "create"); !ok {
+		return 0, 0, false
+	}
+	open = strings.IndexByte(stmt, '(')
+	if open < 0 {
+		return 0, 0, false
+	}
+	depth := 0
+	var quote byte
+	for i := open; i < len(stmt); i++ {
+		c := stmt[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return open, i, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// stripForeignKeys removes the FOREIGN KEY clauses from a CREATE TABLE
+// statement and returns them.
+func stripForeignKeys(stmt string) (string, []foreignKey) {
+	open, end, ok := createTableBody(stmt)
+	if !ok {
+		return stmt, nil
+	}
+	var defs []string
+	var fks []foreignKey
+	stripped := false
+	for _, def := range splitTopLevel(stmt[open+1 : end]) {
+		fk, ok, err := parseForeignKey(def)
+		if !ok {
+			defs = append(defs, def)
+			continue
+		}
+		if err != nil {
+			// still strip it, the parser would reject the whole table
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			stripped = true
+			continue
+		}
+		fks = append(fks, fk)
+		stripped = true
+	}
+	if !stripped {
+		return stmt, nil
+	}
+	return stmt[:open+1] + strings.Join(defs, ",") + stmt[end:], fks
+}
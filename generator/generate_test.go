@@ -0,0 +1,393 @@
+package generator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGzippedInput(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(usersSchema))
+	w.Close()
+	want := generate(t, usersSchema, "users")
+	for _, name := range []string{"schema.sql.gz", "schema.dump"} {
+		chdir(t)
+		writeFile(t, name, gz.String())
+		if err := run(name); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := gofmt(t, readFile(t, modelPath("users"))); got != want {
+			t.Errorf("%s: got\n%s\nwant the output of the plain schema\n%s", name, got, want)
+		}
+	}
+}
+
+func TestCreateTableIfNotExists(t *testing.T) {
+	for _, d := range []string{"mysql", "postgres", "sqlite"} {
+		schema := strings.Replace(usersSchema, "CREATE TABLE users", "CREATE TABLE IF NOT EXISTS users", 1)
+		if d != "mysql" {
+			schema = strings.Replace(schema, " AUTO_INCREMENT", "", 1)
+		}
+		want := generate(t, strings.Replace(schema, " IF NOT EXISTS", "", 1), "users", "-dialect", d)
+		if got := generate(t, schema, "users", "-dialect", d); got != want {
+			t.Errorf("%s: got\n%s\nwant the output without IF NOT EXISTS\n%s", d, got, want)
+		}
+		if !strings.Contains(want, "type Users struct {") {
+			t.Errorf("%s: no Users model:\n%s", d, want)
+		}
+	}
+}
+
+func TestColumnSize(t *testing.T) {
+	for _, tt := range []struct {
+		def, want string
+	}{
+		{"name varchar(65535) NOT NULL", "Column:name;size:65535"},
+		{"name varchar(255) NOT NULL", "Column:name;size:255"},
+		{"code char(2) NOT NULL", "Column:code;size:2"},
+		{"body text NOT NULL", "Column:body"},
+		{"n bigint(20) NOT NULL", "Column:n"},
+	} {
+		reset()
+		c, err := parseColumnDef(tt.def)
+		if err != nil {
+			t.Errorf("%s: %v", tt.def, err)
+			continue
+		}
+		if got := newColumn(c).gormTag(); got != tt.want {
+			t.Errorf("%s: got gorm tag %q, want %q", tt.def, got, tt.want)
+		}
+	}
+}
+
+func TestFormatWithoutGo(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", usersSchema)
+	// formatting takes no go command
+	t.Setenv("PATH", "")
+	reset()
+	if err := flag.CommandLine.Parse([]string{"-dal", "schema.sql"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gen(flag.Args(), databaseName); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{modelPath("users"), "model/users_dal.go"} {
+		got := readFile(t, name)
+		if want := gofmt(t, got); got != want {
+			t.Errorf("%s is not formatted:\n%s", name, got)
+		}
+		if !strings.HasPrefix(got, "// Code generated by dalgen. DO NOT EDIT.\n") {
+			t.Errorf("%s lacks the generated code header:\n%s", name, got)
+		}
+	}
+}
+
+func TestSortedTables(t *testing.T) {
+	// in reverse order
+	const schema = "CREATE TABLE zeta (id bigint NOT NULL, PRIMARY KEY (id));\n" +
+		"CREATE TABLE mid (id bigint NOT NULL, PRIMARY KEY (id));\n" +
+		"CREATE TABLE alpha (id bigint NOT NULL, PRIMARY KEY (id));"
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	out, _, err := capture(t, func() error { return run("-dal", "schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var printed []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		printed = append(printed, filepath.Base(line))
+	}
+	want := []string{"alpha.go", "alpha_dal.go", "mid.go", "mid_dal.go", "zeta.go", "zeta_dal.go"}
+	if got := strings.Join(printed, " "); !strings.HasPrefix(got, strings.Join(want, " ")) {
+		t.Errorf("printed %s, want the tables in order: %s", got, want)
+	}
+
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	if err := run("-single-file", "models.go", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	models := readFile(t, "model/models.go")
+	alpha, mid, zeta := strings.Index(models, "type Alpha struct"), strings.Index(models, "type Mid struct"), strings.Index(models, "type Zeta struct")
+	if alpha < 0 || !(alpha < mid && mid < zeta) {
+		t.Errorf("the models are not in table order:\n%s", models)
+	}
+}
+
+const groupsTest = `package model
+
+import (
+	"testing"
+
+	"dalgentest/model/auth"
+	"dalgentest/model/billing"
+)
+
+func TestGroups(t *testing.T) {
+	for _, name := range []string{(auth.AuthUsers{}).TableName(), (billing.BillingInvoices{}).TableName(), (Orders{}).TableName()} {
+		if name == "" {
+			t.Error("empty table name")
+		}
+	}
+	var _ auth.AuthUsersDAOIface = auth.NewAuthUsersDAO(nil)
+}
+`
+
+func TestGroupByPrefix(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", "CREATE TABLE auth_users (id bigint NOT NULL, PRIMARY KEY (id));\n"+
+		"CREATE TABLE billing_invoices (id bigint NOT NULL, user_id bigint NOT NULL, PRIMARY KEY (id));\n"+
+		"CREATE TABLE orders (id bigint NOT NULL, PRIMARY KEY (id));")
+	if err := run("-group-by-prefix", "auth_, billing_", "-dal", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	for name, pkg := range map[string]string{
+		"model/auth/auth_users.go":              "auth",
+		"model/auth/auth_users_dal.go":          "auth",
+		"model/billing/billing_invoices.go":     "billing",
+		"model/billing/billing_invoices_dal.go": "billing",
+		"model/orders.go":                       "model",
+	} {
+		if got := readFile(t, name); !strings.Contains(got, "\npackage "+pkg+"\n") {
+			t.Errorf("%s is not in package %s:\n%s", name, pkg, got)
+		}
+	}
+	writeFile(t, "model/groups_test.go", groupsTest)
+	goTest(t, "./...")
+}
+
+func TestXormTags(t *testing.T) {
+	const schema = "CREATE TABLE users (\n" +
+		"  id bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  email varchar(255) NOT NULL,\n" +
+		"  state varchar(16) NOT NULL DEFAULT 'new',\n" +
+		"  note text,\n" +
+		"  PRIMARY KEY (id),\n" +
+		"  UNIQUE KEY email (email)\n" +
+		");"
+	users := generate(t, schema, "users", "-tags", "xorm")
+	for _, want := range []string{
+		"`xorm:\"'id' pk autoincr notnull\" json:\"id\"`",
+		"`xorm:\"'email' unique notnull\" json:\"email\"`",
+		"`xorm:\"'state' notnull default('new')\" json:\"state\"`",
+		"`xorm:\"'note'\" json:\"note\"`",
+	} {
+		if !strings.Contains(users, want) {
+			t.Errorf("no %s in:\n%s", want, users)
+		}
+	}
+	if strings.Contains(users, "gorm:") {
+		t.Errorf("gorm tags with -tags xorm:\n%s", users)
+	}
+
+	users = generate(t, schema, "users", "-tags", "gorm,xorm")
+	if want := "`gorm:\"Column:id;primaryKey;autoIncrement\" xorm:\"'id' pk autoincr notnull\" json:\"id\"`"; !strings.Contains(users, want) {
+		t.Errorf("no %s in:\n%s", want, users)
+	}
+}
+
+func TestNoPrimaryKey(t *testing.T) {
+	const schema = "CREATE TABLE logs (msg text);\n" +
+		"CREATE TABLE events (body text);\n" +
+		"CREATE TABLE users (id bigint NOT NULL, PRIMARY KEY (id));"
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	_, stderr, err := capture(t, func() error { return run("schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "warning: table events has no primary key\nwarning: table logs has no primary key\n"
+	if stderr != want {
+		t.Errorf("got warnings\n%s\nwant\n%s", stderr, want)
+	}
+	// the tables are still generated
+	readFile(t, modelPath("logs"))
+
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	_, _, err = capture(t, func() error { return run("-strict-pk", "schema.sql") })
+	if err == nil || err.Error() != "tables without primary key: events, logs" {
+		t.Errorf("got %v with -strict-pk, want the tables without primary key", err)
+	}
+	if _, err := os.Stat(modelPath("users")); err == nil {
+		t.Error("users.go written with -strict-pk failing")
+	}
+}
+
+func TestInlineKeys(t *testing.T) {
+	const schema = "CREATE TABLE users (\n" +
+		"  id bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,\n" +
+		"  email varchar(255) NOT NULL UNIQUE,\n" +
+		"  code varchar(8) UNIQUE KEY,\n" +
+		"  name varchar(64) NOT NULL\n" +
+		");\n" +
+		"CREATE TABLE tokens (\n" +
+		"  token varchar(64) PRIMARY KEY,\n" +
+		"  a int,\n" +
+		"  b int,\n" +
+		"  UNIQUE KEY ab (a, b)\n" +
+		");"
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	_, stderr, err := capture(t, func() error { return run("-tags", "gorm,xorm", "schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stderr != "" {
+		t.Errorf("got warnings:\n%s", stderr)
+	}
+	users := gofmt(t, readFile(t, modelPath("users")))
+	for _, want := range []string{
+		"`gorm:\"Column:id;primaryKey;autoIncrement\" xorm:\"'id' pk autoincr notnull\" json:\"id\"`",
+		"`gorm:\"Column:email;size:255;unique\" xorm:\"'email' unique notnull\" json:\"email\"`",
+		"`gorm:\"Column:code;size:8;unique\" xorm:\"'code' unique\" json:\"code\"`",
+		"`gorm:\"Column:name;size:64\" xorm:\"'name' notnull\" json:\"name\"`",
+	} {
+		if !strings.Contains(users, want) {
+			t.Errorf("no %s in:\n%s", want, users)
+		}
+	}
+	tokens := gofmt(t, readFile(t, modelPath("tokens")))
+	if want := "`gorm:\"Column:token;size:64;primaryKey\" xorm:\"'token' pk\" json:\"token\"`"; !strings.Contains(tokens, want) {
+		t.Errorf("no %s in:\n%s", want, tokens)
+	}
+	// a unique key of two columns makes neither of them unique
+	if strings.Contains(tokens, "unique") {
+		t.Errorf("a column of a composite unique key tagged unique:\n%s", tokens)
+	}
+}
+
+func TestDashFilename(t *testing.T) {
+	chdir(t)
+	writeFile(t, "-weird.sql", "CREATE TABLE weird (id bigint NOT NULL, PRIMARY KEY (id));")
+	stdin, err := os.Create("stdin.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	if _, err := stdin.WriteString("CREATE TABLE piped (id bigint NOT NULL, PRIMARY KEY (id));"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer func(f *os.File) { os.Stdin = f }(os.Stdin)
+	os.Stdin = stdin
+
+	// after -- a dash starts a file name, while a lone - is still stdin
+	if err := run("--", "-weird.sql", "-"); err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range []string{"weird", "piped"} {
+		if !strings.Contains(readFile(t, modelPath(table)), "return \""+table+"\"") {
+			t.Errorf("%s not generated", table)
+		}
+	}
+}
+
+func TestOutExt(t *testing.T) {
+	const schema = "CREATE TABLE users (\n" +
+		"  id bigint NOT NULL,\n" +
+		"  state enum('new','done') NOT NULL,\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"CREATE TABLE posts (id bigint NOT NULL, PRIMARY KEY (id));"
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	if err := run("-out-ext", ".gen.go", "-dal", "-gen-fuzz", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if got := getFilePath("", "users"); filepath.Base(got) != "users.gen.go" {
+		t.Errorf("got %s, want users.gen.go", got)
+	}
+	for _, fp := range []string{"model/users.gen.go", "model/users_dal.gen.go", "model/posts.gen.go", "model/users_fuzz_test.go"} {
+		if _, err := os.Stat(fp); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := os.Stat("model/users.go"); err == nil {
+		t.Error("users.go written with -out-ext .gen.go")
+	}
+
+	// -check compares the same paths
+	if err := run("-out-ext", ".gen.go", "-dal", "-gen-fuzz", "-check", "schema.sql"); err != nil {
+		t.Errorf("up to date: %v", err)
+	}
+	writeFile(t, "schema.sql", usersSchema)
+	err := run("-out-ext", ".gen.go", "-dal", "-check", "schema.sql")
+	for _, want := range []string{"out of date: model/users.gen.go", "orphaned: model/posts.gen.go", "orphaned: model/users_fuzz_test.go"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("got %v, want %s", err, want)
+		}
+	}
+
+	if err := run("-out-ext", ".gen", "schema.sql"); err == nil || err.Error() != `-out-ext ".gen" does not end in .go` {
+		t.Errorf("got %v, want the extension rejected", err)
+	}
+}
+
+const quotingSchema = "CREATE TABLE `notes` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'see `id` */ here',\n" +
+	"  `body` text NOT NULL COMMENT 'ends a block */ and has \"quotes\"',\n" +
+	"  `mood` varchar(16) NOT NULL DEFAULT 'it''s `ok` \"fine\"' COMMENT 'multi\nline',\n" +
+	"  `odd``col` int NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	") COMMENT='notes /* with */ comments';"
+
+const quotingTest = `package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuotedTags(t *testing.T) {
+	typ := reflect.TypeOf(Notes{})
+	for _, tt := range []struct {
+		field, key, want string
+	}{
+		{"Mood", "xorm", "'mood' notnull default('it''s ` + "`ok`" + ` \"fine\"')"},
+		{"Mood", "json", "mood"},
+		{"OddCol", "gorm", "Column:odd` + "`" + `col"},
+		{"OddCol", "json", "odd` + "`" + `col"},
+		{"Body", "gorm", "Column:body"},
+	} {
+		f, _ := typ.FieldByName(tt.field)
+		if got := f.Tag.Get(tt.key); got != tt.want {
+			t.Errorf("%s %s tag %q, want %q", tt.field, tt.key, got, tt.want)
+		}
+	}
+}
+`
+
+func TestCommentsAndTags(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", quotingSchema)
+	if err := run("-tags", "gorm,xorm", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	notes := gofmt(t, readFile(t, modelPath("notes")))
+	for _, want := range []string{
+		"// Notes is the notes /* with * / comments.\n",
+		"// see `id` * / here\n",
+		"// ends a block * / and has \"quotes\"\n",
+		// a raw string cannot hold the backtick
+		"Mood   string \"gorm:\\\"Column:mood;size:16\\\" xorm:\\\"'mood' notnull default('it''s `ok` \\\\\\\"fine\\\\\\\"')\\\" json:\\\"mood\\\"\" // multi line\n",
+	} {
+		if !strings.Contains(notes, want) {
+			t.Errorf("no %s in:\n%s", want, notes)
+		}
+	}
+	if strings.Contains(notes, "*/") {
+		t.Errorf("*/ left in a comment:\n%s", notes)
+	}
+	writeFile(t, "model/quoting_test.go", quotingTest)
+	goTest(t, "./model")
+}
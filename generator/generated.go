@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// generatedColumns holds the GENERATED ALWAYS AS columns, which the database
+// computes: their fields are read-only, or left out with
+// -skip-generated-columns.
+var generatedColumns = make(map[*sqlparser.ColumnDefinition]bool)
+
+var (
+	// generatedAs matches the start of a [GENERATED ALWAYS] AS (expr)
+	// clause, which the sql parser does not know.
+	generatedAs = regexp.MustCompile(`(?i)^(generated\s+always\s+)?as\s*\(`)
+	// generatedStorage matches the VIRTUAL or STORED after the expression.
+	generatedStorage = regexp.MustCompile(`(?i)^\s*(virtual|stored|persistent)\b`)
+)
+
+// stripGeneratedColumn removes the generation clause from a column
+// definition, returning its column name, or "" when def is not generated.
+func stripGeneratedColumn(def string) (string, string) {
+	name, _ := sqlWord(def)
+	start, open := findClause(def, generatedAs)
+	if start < 0 {
+		return def, ""
+	}
+	end := closingParen(def, open)
+	if end < 0 {
+		return def, ""
+	}
+	rest := def[end+1:]
+	if s := generatedStorage.FindStringIndex(rest); s != nil {
+		rest = rest[s[1]:]
+	}
+	return def[:start] + rest, name
+}
+
+// findClause returns the start of the first match of re, which must end
+// with an opening parenthesis, at a word start outside quotes in def, and
+// the index of that parenthesis; both are -1 if there is none.
+func findClause(def string, re *regexp.Regexp) (int, int) {
+	var quote byte
+	for i := 0; i < len(def); i++ {
+		c := def[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case i > 0 && isWordByte(def[i-1]):
+		default:
+			if m := re.FindStringIndex(def[i:]); m != nil {
+				return i, i + m[1] - 1
+			}
+		}
+	}
+	return -1, -1
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// closingParen returns the index of the parenthesis closing the one at
+// open, -1 if it is not closed.
+func closingParen(s string, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// stripGeneratedColumns removes the generation clauses of a CREATE TABLE
+// statement and returns the names of the generated columns.
+func stripGeneratedColumns(stmt string) (string, []string) {
+	open, end, ok := createTableBody(stmt)
+	if !ok {
+		return stmt, nil
+	}
+	defs := splitTopLevel(stmt[open+1 : end])
+	var names []string
+	for i, def := range defs {
+		if d, name := stripGeneratedColumn(def); name != "" {
+			defs[i] = d
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return stmt, nil
+	}
+	return stmt[:open+1] + strings.Join(defs, ",") + stmt[end:], names
+}
+
+// markGeneratedColumns records the named columns of ddl in
+// generatedColumns.
+func markGeneratedColumns(ddl *sqlparser.DDL, names []string) {
+	for _, name := range names {
+		if c := findColumn(ddl, name); c != nil {
+			generatedColumns[c] = true
+		}
+	}
+}
+
+// skipGeneratedColumns drops the generated columns of every table for
+// -skip-generated-columns.
+func skipGeneratedColumns(ddls []*sqlparser.DDL) {
+	for _, ddl := range ddls {
+		columns := ddl.TableSpec.Columns[:0]
+		for _, c := range ddl.TableSpec.Columns {
+			if !generatedColumns[c] {
+				columns = append(columns, c)
+			}
+		}
+		ddl.TableSpec.Columns = columns
+	}
+}
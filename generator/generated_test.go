@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const generatedSchema = "CREATE TABLE `people` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `first_name` varchar(64) NOT NULL,\n" +
+	"  `last_name` varchar(64) NOT NULL,\n" +
+	"  `full_name` varchar(200) GENERATED ALWAYS AS (concat(`first_name`,' ',`last_name`)) STORED,\n" +
+	"  `initial` char(1) AS (left(`first_name`, 1)) VIRTUAL NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");"
+
+const generatedTest = `package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestGeneratedColumns(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sqlite refuses writes to generated columns as the database would
+	err = db.Exec(` + "`" + `CREATE TABLE people (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		first_name text NOT NULL,
+		last_name text NOT NULL,
+		full_name text GENERATED ALWAYS AS (first_name || ' ' || last_name) STORED,
+		initial text GENERATED ALWAYS AS (substr(first_name, 1, 1)) VIRTUAL NOT NULL
+	)` + "`" + `).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := People{FirstName: "Ada", LastName: "Lovelace", FullName: "ignored", Initial: "x"}
+	if err := db.Create(&p).Error; err != nil {
+		t.Fatal(err)
+	}
+	p.LastName = "Byron"
+	if err := db.Save(&p).Error; err != nil {
+		t.Fatal(err)
+	}
+	var got People
+	if err := db.First(&got, p.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.FullName != "Ada Byron" || got.Initial != "A" {
+		t.Errorf("got %+v, want the computed columns", got)
+	}
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), ` + "`" + `"full_name":"Ada Byron","initial":"A"` + "`" + `) {
+		t.Errorf("generated columns not serialized: %s", b)
+	}
+}
+`
+
+func TestGeneratedColumns(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", generatedSchema)
+	if err := run("schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	people := gofmt(t, readFile(t, modelPath("people")))
+	for _, want := range []string{
+		"FullName  string `gorm:\"Column:full_name;size:200;->\" json:\"full_name\"`",
+		"Initial   string `gorm:\"Column:initial;size:1;->\" json:\"initial\"`",
+		"LastName  string `gorm:\"Column:last_name;size:64\" json:\"last_name\"`",
+	} {
+		if !strings.Contains(people, want) {
+			t.Errorf("no %s in:\n%s", want, people)
+		}
+	}
+	writeFile(t, "model/generated_test.go", generatedTest)
+	goTest(t, "./model")
+}
+
+func TestSkipGeneratedColumns(t *testing.T) {
+	people := generate(t, generatedSchema, "people", "-skip-generated-columns")
+	for _, unwanted := range []string{"FullName", "Initial"} {
+		if strings.Contains(people, unwanted) {
+			t.Errorf("%s generated with -skip-generated-columns:\n%s", unwanted, people)
+		}
+	}
+	if !strings.Contains(people, "LastName ") {
+		t.Errorf("last_name skipped:\n%s", people)
+	}
+}
@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const gettersTemplate = `
+package {{.Package}}
+
+{{.Imports}}
+{{- range .Getters}}
+func (m *{{$.TableName}}) {{.Name}}() (v {{.Type}}) {
+{{- if .Pointer}}
+	if m != nil && m.{{.Field}} != nil {
+		v = *m.{{.Field}}
+	}
+{{- else}}
+	if m != nil {
+		v = m.{{.Field}}
+	}
+{{- end}}
+	return v
+}
+{{end}}`
+
+type getter struct {
+	Name  string
+	Field string
+	Type  string
+	// Pointer fields are dereferenced, their getter returns the pointee.
+	Pointer bool
+}
+
+// modelMethods are the methods dalgen may generate on a model, which getters
+// must not shadow.
+var modelMethods = []string{"TableName", "ShardedTableName", "Equal", "IsZero", "ToDTO", "FromDTO", "Clone", "Fields", "Values"}
+
+func genGetters(pkg string, ddl *sqlparser.DDL) string {
+	used := make(map[string]bool)
+	for _, m := range modelMethods {
+		used[m] = true
+	}
+	cols := make([]dalColumn, 0, len(ddl.TableSpec.Columns))
+	for _, c := range ddl.TableSpec.Columns {
+		col := newDALColumn(c)
+		used[col.Field] = true
+		cols = append(cols, col)
+	}
+
+	var getters []getter
+	var types []string
+	for _, col := range cols {
+		name := "Get" + col.Field
+		// a column may camel-case into the getter of another, e.g. x and
+		// get_x; suffix until the name is free
+		for used[name] {
+			name += "Column"
+		}
+		used[name] = true
+		g := getter{Name: name, Field: col.Field, Type: col.Type}
+		if strings.HasPrefix(col.Type, "*") {
+			g.Type, g.Pointer = col.Type[1:], true
+		}
+		getters = append(getters, g)
+		types = append(types, col.Type)
+	}
+
+	params := struct {
+		Package   string
+		TableName string
+		Imports   string
+		Getters   []getter
+	}{
+		Package:   pkg,
+		TableName: modelName(ddl.NewName.Name.String()),
+		Imports:   renderImports(importsOf(types...)),
+		Getters:   getters,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("getters").Parse(gettersTemplate)).Execute(&buf, params)
+	return buf.String()
+}
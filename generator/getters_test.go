@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const gettersTest = `package model
+
+import "testing"
+
+func TestGetters(t *testing.T) {
+	var nilUser *Users
+	if nilUser.GetNickname() != "" || nilUser.GetAge() != 0 || nilUser.GetEmail() != "" {
+		t.Error("the getters of a nil receiver do not return the zero values")
+	}
+	u := &Users{Email: "a@example.com"}
+	if u.GetNickname() != "" || u.GetAge() != 0 {
+		t.Error("the getters of nil fields do not return the zero values")
+	}
+	nickname, age := "ann", 30
+	u.Nickname, u.Age = &nickname, &age
+	if u.GetNickname() != "ann" || u.GetAge() != 30 || u.GetEmail() != "a@example.com" {
+		t.Errorf("got %q, %d, %q", u.GetNickname(), u.GetAge(), u.GetEmail())
+	}
+}
+`
+
+func TestGettersNullable(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", nullableSchema)
+	if err := run("-nullable-pointers", "-gen-getters", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	getters := gofmt(t, readFile(t, "model/users_getters.go"))
+	for _, want := range []string{
+		"func (m *Users) GetNickname() (v string)",
+		"func (m *Users) GetAge() (v int)",
+		"func (m *Users) GetBornAt() (v time.Time)",
+		"func (m *Users) GetAvatar() (v []byte)",
+	} {
+		if !strings.Contains(getters, want) {
+			t.Errorf("no %q in:\n%s", want, getters)
+		}
+	}
+	writeFile(t, "model/getters_test.go", gettersTest)
+	goTest(t, "./model")
+}
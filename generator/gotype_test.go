@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoTypeRealNumeric(t *testing.T) {
+	for _, tt := range []struct {
+		dialect, def, want string
+	}{
+		{"mysql", "price real NOT NULL", "float64"},
+		{"postgres", "price real NOT NULL", "float32"},
+		{"sqlite", "price real NOT NULL", "float64"},
+		{"mysql", "price numeric(10,2) NOT NULL", "float64"},
+		{"postgres", "price numeric(10,2) NOT NULL", "float64"},
+		{"mysql", "price decimal(10,2) NOT NULL", "float64"},
+	} {
+		reset()
+		dialect = tt.dialect
+		c, err := parseColumnDef(tt.def)
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.dialect, tt.def, err)
+			continue
+		}
+		if got := GoType(c); got != tt.want {
+			t.Errorf("%s %s: got %s, want %s", tt.dialect, tt.def, got, tt.want)
+		}
+	}
+}
+
+func TestGoTypeStrings(t *testing.T) {
+	reset()
+	for _, tt := range []struct {
+		def, want string
+	}{
+		{"c char(2) NOT NULL", "string"},
+		{"c varchar(10) NOT NULL", "string"},
+		{"c tinytext NOT NULL", "string"},
+		{"c text NOT NULL", "string"},
+		{"c mediumtext NOT NULL", "string"},
+		{"c longtext NOT NULL", "string"},
+		{"c text CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL", "string"},
+		{"c tinytext", "string"},
+		{"c binary(16) NOT NULL", "[]byte"},
+		{"c varbinary(64) NOT NULL", "[]byte"},
+		{"c tinyblob", "[]byte"},
+		{"c blob", "[]byte"},
+		{"c mediumblob", "[]byte"},
+		{"c longblob", "[]byte"},
+	} {
+		c, err := parseColumnDef(tt.def)
+		if err != nil {
+			t.Errorf("%s: %v", tt.def, err)
+			continue
+		}
+		if got := safeGoType(c); got != tt.want {
+			t.Errorf("%s: got %q, want %s", tt.def, got, tt.want)
+		}
+	}
+}
+
+func TestTextTiers(t *testing.T) {
+	const schema = `CREATE TABLE posts (
+  id bigint NOT NULL AUTO_INCREMENT,
+  teaser tinytext NOT NULL,
+  summary text NOT NULL,
+  body mediumtext NOT NULL,
+  raw longtext,
+  PRIMARY KEY (id)
+);`
+	posts := generate(t, schema, "posts", "-strict")
+	for _, want := range []string{
+		"Teaser  string `gorm:\"Column:teaser\" json:\"teaser\"`",
+		"Summary string `gorm:\"Column:summary\" json:\"summary\"`",
+		"Body    string `gorm:\"Column:body\" json:\"body\"`",
+		"Raw     string `gorm:\"Column:raw\" json:\"raw\"`",
+	} {
+		if !strings.Contains(posts, want) {
+			t.Errorf("no %s in:\n%s", want, posts)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// gzipFixture returns the bytes of testdata/gzip/schema.sql.gz.
+func gzipFixture(t *testing.T) []byte {
+	t.Helper()
+	data, err := ioutil.ReadFile(testdataPath(t, "gzip/schema.sql.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "\x1f\x8b") {
+		t.Fatal("schema.sql.gz is not gzipped")
+	}
+	return data
+}
+
+func checkGzipModels(t *testing.T) {
+	t.Helper()
+	checkGenerated(t, map[string]bool{"users": true, "posts": true})
+}
+
+func TestGzipInput(t *testing.T) {
+	fixture, data := testdataPath(t, "gzip/schema.sql.gz"), gzipFixture(t)
+	chdir(t)
+	if err := run("-strict", fixture); err != nil {
+		t.Fatal(err)
+	}
+	checkGzipModels(t)
+
+	// by the magic bytes, without the extension
+	chdir(t)
+	writeFile(t, "schema.sql", string(data))
+	if err := run("-strict", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	checkGzipModels(t)
+}
+
+func TestGzipStdin(t *testing.T) {
+	data := gzipFixture(t)
+	chdir(t)
+	writeFile(t, "stdin", string(data))
+	stdin, err := os.Open("stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	defer func(f *os.File) { os.Stdin = f }(os.Stdin)
+	os.Stdin = stdin
+	if err := run("-strict", "-"); err != nil {
+		t.Fatal(err)
+	}
+	checkGzipModels(t)
+}
+
+func TestGzipURL(t *testing.T) {
+	data := gzipFixture(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer srv.Close()
+	chdir(t)
+	if err := run("-strict", srv.URL+"/schema.sql.gz"); err != nil {
+		t.Fatal(err)
+	}
+	checkGzipModels(t)
+}
+
+func TestCorruptGzip(t *testing.T) {
+	data := gzipFixture(t)
+	for _, tt := range []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"truncated", string(data[:len(data)/2]), "schema.sql.gz: corrupt gzip stream: unexpected EOF"},
+		{"no trailer", string(data[:len(data)-4]), "schema.sql.gz: corrupt gzip stream: unexpected EOF"},
+		{"bad checksum", string(data[:len(data)-8]) + "\x00\x00\x00\x00" + string(data[len(data)-4:]), "schema.sql.gz: corrupt gzip stream: gzip: invalid checksum"},
+		{"not gzip", "CREATE TABLE users (id bigint);", "schema.sql.gz: corrupt gzip stream: gzip: invalid header"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t)
+			writeFile(t, "schema.sql.gz", tt.content)
+			err := run("schema.sql.gz")
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got %v, want %s", err, tt.want)
+			}
+			if _, err := os.Stat("model"); err == nil {
+				t.Error("models written from a corrupt stream")
+			}
+		})
+	}
+}
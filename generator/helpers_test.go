@@ -0,0 +1,213 @@
+package generator
+
+import (
+	"flag"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// reset puts the flags back to their defaults and clears the state a
+// previous run left behind, including which flags were set, which the
+// config file looks at.
+func reset() {
+	flags := flag.NewFlagSet(flag.CommandLine.Name(), flag.ContinueOnError)
+	flag.VisitAll(func(f *flag.Flag) {
+		if !strings.HasPrefix(f.Name, "test.") && f.Name != "update" {
+			f.Value.Set(f.DefValue)
+		}
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	flag.CommandLine = flags
+	typeMap = TypeMap{}
+	configSchema = nil
+	configColumns = make(map[string]TypeOverride)
+	configColumnLines = make(map[string]string)
+	configTables = make(map[string]*configTable)
+	configTableNames = nil
+	customTemplate = nil
+	fileHooks = nil
+	checkedFiles = make(map[string]bool)
+	staleFiles = nil
+	missingFiles = nil
+	inputSpans = nil
+	pgEnumColumns = make(map[*sqlparser.ColumnDefinition]string)
+	columnEnumTypes = make(map[*sqlparser.ColumnDefinition]string)
+	pointerColumns = make(map[*sqlparser.ColumnDefinition]bool)
+}
+
+// chdir moves the test into a directory of its own, returning it.
+func chdir(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+// writeFile writes content to name in the current directory.
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readFile returns the content of name in the current directory.
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// run runs dalgen on the command line arguments in the current directory,
+// as Main does, without go fmt.
+func run(args ...string) error {
+	reset()
+	files, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	return gen(files, databaseName)
+}
+
+// parseArgs sets the flags of the arguments, without go fmt, and loads the
+// config file, returning the schema files.
+func parseArgs(args []string) ([]string, error) {
+	if err := flag.CommandLine.Parse(append([]string{"-no-fmt"}, args...)); err != nil {
+		return nil, err
+	}
+	if configFile != "" {
+		if err := loadConfig(configFile); err != nil {
+			return nil, err
+		}
+	}
+	if flag.NArg() == 0 {
+		return configSchema, nil
+	}
+	return flag.Args(), nil
+}
+
+// capture returns what f writes to stdout and stderr.
+func capture(t *testing.T, f func() error) (string, string, error) {
+	t.Helper()
+	stdout, stderr := os.Stdout, os.Stderr
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// drain the pipes as f writes, so that it cannot fill them up
+	outC, errC := make(chan []byte), make(chan []byte)
+	go func() { b, _ := ioutil.ReadAll(outR); outC <- b }()
+	go func() { b, _ := ioutil.ReadAll(errR); errC <- b }()
+	os.Stdout, os.Stderr = outW, errW
+	err = f()
+	os.Stdout, os.Stderr = stdout, stderr
+	outW.Close()
+	errW.Close()
+	return string(<-outC), string(<-errC), err
+}
+
+// generate writes the schema to schema.sql in a directory of its own and
+// runs dalgen on it with the flags given, returning the model file of the
+// table as go fmt leaves it.
+func generate(t *testing.T, schema, table string, args ...string) string {
+	t.Helper()
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	if err := run(append(args, "schema.sql")...); err != nil {
+		t.Fatal(err)
+	}
+	return gofmt(t, readFile(t, modelPath(table)))
+}
+
+// gofmt formats src as go fmt would.
+func gofmt(t *testing.T, src string) string {
+	t.Helper()
+	b, err := format.Source([]byte(src))
+	if err != nil {
+		t.Fatalf("%v:\n%s", err, src)
+	}
+	return string(b)
+}
+
+// modelPath is where the model of the table is written by default.
+func modelPath(table string) string {
+	return filepath.Join(outputDir, databaseName, table+outExt)
+}
+
+// harness is the module the generated code is tested in, whose go.mod
+// requires the packages the generated files import.
+var harness, _ = filepath.Abs(filepath.Join("testdata", "harness"))
+
+// goTest runs the tests the test wrote next to the generated code in the
+// current directory, in the harness module, with cgo for sqlite. It is
+// skipped with -short or without a go command.
+func goTest(t *testing.T, pkgs ...string) {
+	t.Helper()
+	goTestFlags(t, nil, pkgs...)
+}
+
+// goTestFlags is goTest with extra go test flags.
+func goTestFlags(t *testing.T, flags []string, pkgs ...string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping the generated code tests in short mode")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go command to test the generated code")
+	}
+	for _, name := range []string{"go.mod", "go.sum"} {
+		writeFile(t, name, readFile(t, filepath.Join(harness, name)))
+	}
+	if len(pkgs) == 0 {
+		pkgs = []string{"./..."}
+	}
+	cmd := exec.Command(goCmd, append(append([]string{"test", "-count=1"}, flags...), pkgs...)...)
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "CGO_ENABLED=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test: %v\n%s", err, out)
+	}
+}
+
+// checkGolden compares got with the golden file fp, which go test -update
+// rewrites.
+func checkGolden(t *testing.T, fp, got string) {
+	t.Helper()
+	if *update {
+		if err := ioutil.WriteFile(fp, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs:\n%s", fp, got)
+	}
+}
@@ -0,0 +1,37 @@
+package generator
+
+import "fmt"
+
+// GeneratedFile is a file dalgen is about to write: the models, DAOs and
+// helpers, with their build header and before go fmt, and the -openapi
+// document.
+type GeneratedFile struct {
+	// Path is where the file is written, for the hooks to tell the files
+	// apart; changing it has no effect.
+	Path    string
+	Content string
+}
+
+// fileHooks are the RegisterHook hooks, in registration order.
+var fileHooks []func(*GeneratedFile) error
+
+// RegisterHook adds a hook post-processing every generated file before it
+// is written, or compared under -check, to inject methods, rewrite the
+// imports or run another formatter. Hooks run in the order they are
+// registered, by a command of your own before it calls Main; the first
+// error aborts the generation.
+func RegisterHook(hook func(file *GeneratedFile) error) {
+	fileHooks = append(fileHooks, hook)
+}
+
+// runHooks passes the content of the file at fp through the hooks,
+// returning it as they leave it.
+func runHooks(fp, content string) (string, error) {
+	file := &GeneratedFile{Path: fp, Content: content}
+	for _, hook := range fileHooks {
+		if err := hook(file); err != nil {
+			return "", fmt.Errorf("%s: %v", fp, err)
+		}
+	}
+	return file.Content, nil
+}
@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const usersSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+func TestRegisterHook(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", usersSchema)
+	var paths []string
+	hook := func(file *GeneratedFile) error {
+		paths = append(paths, file.Path)
+		file.Content += "\nfunc (u *Users) Masked() string { return \"***\" }\n"
+		return nil
+	}
+	if err := runWithHooks([]func(*GeneratedFile) error{hook}, "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || !strings.HasSuffix(paths[0], "/model/users.go") {
+		t.Errorf("hook ran on %q, want model/users.go only", paths)
+	}
+	if got := readFile(t, modelPath("users")); !strings.Contains(got, "func (u *Users) Masked() string") {
+		t.Errorf("users.go lacks the method the hook added:\n%s", got)
+	}
+}
+
+func TestRegisterHookError(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", usersSchema)
+	hook := func(file *GeneratedFile) error { return errors.New("rejected") }
+	err := runWithHooks([]func(*GeneratedFile) error{hook}, "schema.sql")
+	if err == nil || !strings.HasSuffix(err.Error(), "users.go: rejected") {
+		t.Errorf("got error %v, want the hook error for users.go", err)
+	}
+}
+
+// runWithHooks runs dalgen with the hooks registered, as a command of
+// your own calling RegisterHook before Main does.
+func runWithHooks(hooks []func(*GeneratedFile) error, args ...string) error {
+	reset()
+	for _, hook := range hooks {
+		RegisterHook(hook)
+	}
+	defer func() { fileHooks = nil }()
+	files, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	return gen(files, databaseName)
+}
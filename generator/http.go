@@ -0,0 +1,189 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const ginTemplate = `
+package {{.Package}}
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	{{- if .VersionJSON}}
+	"github.com/gin-gonic/gin/binding"
+	{{- end}}
+	"gorm.io/gorm"
+)
+
+func Register{{.TableName}}Routes(r gin.IRouter, dao *{{.DAO}}) {
+	g := r.Group("/{{.TableNameStr}}")
+
+	g.GET("/:id", func(c *gin.Context) {
+		{{parse .PK}}
+		m, err := dao.GetByID(c.Request.Context(), id)
+		if err != nil {
+			c.JSON({{.TableName}}HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, m)
+	})
+
+	g.GET("", func(c *gin.Context) {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ms, err := dao.List(c.Request.Context(), limit, offset)
+		if err != nil {
+			c.JSON({{.TableName}}HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, ms)
+	})
+
+	g.POST("", func(c *gin.Context) {
+		var m {{.TableName}}
+		if err := c.ShouldBindJSON(&m); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := dao.Create(c.Request.Context(), &m); err != nil {
+			c.JSON({{.TableName}}HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, &m)
+	})
+
+	g.PUT("/:id", func(c *gin.Context) {
+		{{parse .PK}}
+		{{- if and .Version (not .VersionJSON)}}
+		cur, err := dao.GetByID(c.Request.Context(), id)
+		if err != nil {
+		{{- else}}
+		if _, err := dao.GetByID(c.Request.Context(), id); err != nil {
+		{{- end}}
+			c.JSON({{.TableName}}HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		var m {{.TableName}}
+		{{- if .VersionJSON}}
+		// the version the client read is the one the update must match
+		var fields map[string]interface{}
+		if err := c.ShouldBindBodyWith(&fields, binding.JSON); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := fields[{{printf "%q" .VersionJSON}}]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": {{printf "%q" (printf "%s is required" .VersionJSON)}}})
+			return
+		}
+		if err := c.ShouldBindBodyWith(&m, binding.JSON); err != nil {
+		{{- else}}
+		if err := c.ShouldBindJSON(&m); err != nil {
+		{{- end}}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		m.{{(index .PK 0).Field}} = id
+		{{- if and .Version (not .VersionJSON)}}
+		// the version is left out of the JSON: update the row just read
+		m.{{.Version.Field}} = cur.{{.Version.Field}}
+		{{- end}}
+		if err := dao.Update(c.Request.Context(), &m); err != nil {
+			c.JSON({{.TableName}}HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, &m)
+	})
+
+	g.DELETE("/:id", func(c *gin.Context) {
+		{{parse .PK}}
+		if _, err := dao.GetByID(c.Request.Context(), id); err != nil {
+			c.JSON({{.TableName}}HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		if err := dao.Delete(c.Request.Context(), id); err != nil {
+			c.JSON({{.TableName}}HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// {{.TableName}}HTTPStatus maps the errors of the DAO to HTTP status codes:
+// 404 for a missing row{{if .Version}}, 409 for a stale version{{end}} and 500 for the rest.
+func {{.TableName}}HTTPStatus(err error) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	{{- if .Version}}
+	var stale *ErrStaleVersion
+	if errors.As(err, &stale) {
+		return http.StatusConflict
+	}
+	{{- end}}
+	return http.StatusInternalServerError
+}
+`
+
+var ginFuncs = template.FuncMap{
+	"parse": func(pk []dalColumn) string {
+		const bad = "\n\t\tif err != nil {\n\t\t\tc.JSON(http.StatusBadRequest, gin.H{\"error\": err.Error()})\n\t\t\treturn\n\t\t}"
+		switch pk[0].Type {
+		case "string":
+			return `id := c.Param("id")`
+		case "int":
+			return `id, err := strconv.Atoi(c.Param("id"))` + bad
+		case "uint64":
+			return `id, err := strconv.ParseUint(c.Param("id"), 10, 64)` + bad
+		default:
+			return fmt.Sprintf(`v, err := strconv.ParseInt(c.Param("id"), 10, 64)`+bad+"\n\t\tid := %s(v)", pk[0].Type)
+		}
+	},
+}
+
+// httpRoutable reports whether the table has a primary key that can be
+// addressed by a single path parameter.
+func httpRoutable(ddl *sqlparser.DDL) bool {
+	pk := primaryKeyColumns(ddl)
+	if len(pk) != 1 {
+		return false
+	}
+	switch pk[0].Type {
+	case "string", "int", "int64", "uint64":
+		return true
+	}
+	return false
+}
+
+// httpParams adds to the DAO parameters the JSON name of the version
+// column, which a PUT body must carry, empty if -json-exclude leaves the
+// version out of the JSON.
+type httpParams struct {
+	dalParams
+	VersionJSON string
+}
+
+func genHTTP(pkg string, ddl *sqlparser.DDL) string {
+	p := httpParams{dalParams: newDALParams(pkg, ddl)}
+	if p.Version != nil && !jsonExcluded(p.TableNameStr, p.Version.Name) {
+		p.VersionJSON = jsonName(p.Version.Name)
+	}
+	var buf bytes.Buffer
+	_ = template.Must(template.New("gin").Funcs(ginFuncs).Parse(ginTemplate)).Execute(&buf, p)
+	return buf.String()
+}
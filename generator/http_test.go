@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const httpSchema = "CREATE TABLE `items` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `name` varchar(64) NOT NULL,\n" +
+	"  `version` bigint NOT NULL DEFAULT 0,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");\n" +
+	"CREATE TABLE `tags` (\n" +
+	"  `name` varchar(32) NOT NULL,\n" +
+	"  `note` varchar(255) NOT NULL,\n" +
+	"  PRIMARY KEY (`name`)\n" +
+	");"
+
+const httpTest = `package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Items{}, &Tags{}); err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	RegisterItemsRoutes(r, NewItemsDAO(db))
+	RegisterTagsRoutes(r, NewTagsDAO(db))
+	return r
+}
+
+func do(t *testing.T, r http.Handler, method, path, body string, want int) []byte {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != want {
+		t.Errorf("%s %s %s: got %d %s, want %d", method, path, body, w.Code, w.Body, want)
+	}
+	return w.Body.Bytes()
+}
+
+func TestPost(t *testing.T) {
+	r := newRouter(t)
+	var m Items
+	if err := json.Unmarshal(do(t, r, "POST", "/items", ` + "`" + `{"name":"a"}` + "`" + `, http.StatusCreated), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.ID != 1 || m.Name != "a" {
+		t.Errorf("created %+v", m)
+	}
+	do(t, r, "POST", "/items", "{", http.StatusBadRequest)
+	do(t, r, "POST", "/tags", ` + "`" + `{"name":"go","note":"gopher"}` + "`" + `, http.StatusCreated)
+	// the primary key is taken
+	do(t, r, "POST", "/tags", ` + "`" + `{"name":"go","note":"again"}` + "`" + `, http.StatusInternalServerError)
+}
+
+func TestGet(t *testing.T) {
+	r := newRouter(t)
+	do(t, r, "POST", "/items", ` + "`" + `{"name":"a"}` + "`" + `, http.StatusCreated)
+	var m Items
+	if err := json.Unmarshal(do(t, r, "GET", "/items/1", "", http.StatusOK), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "a" {
+		t.Errorf("got %+v", m)
+	}
+	do(t, r, "GET", "/items/9", "", http.StatusNotFound)
+	do(t, r, "GET", "/items/x", "", http.StatusBadRequest)
+
+	do(t, r, "POST", "/tags", ` + "`" + `{"name":"go","note":"gopher"}` + "`" + `, http.StatusCreated)
+	var tag Tags
+	if err := json.Unmarshal(do(t, r, "GET", "/tags/go", "", http.StatusOK), &tag); err != nil {
+		t.Fatal(err)
+	}
+	if tag.Note != "gopher" {
+		t.Errorf("got %+v", tag)
+	}
+	do(t, r, "GET", "/tags/rust", "", http.StatusNotFound)
+}
+
+func TestList(t *testing.T) {
+	r := newRouter(t)
+	for _, name := range []string{"a", "b", "c"} {
+		do(t, r, "POST", "/items", ` + "`" + `{"name":"` + "`" + `+name+` + "`" + `"}` + "`" + `, http.StatusCreated)
+	}
+	var ms []Items
+	if err := json.Unmarshal(do(t, r, "GET", "/items?limit=2&offset=1", "", http.StatusOK), &ms); err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 2 || ms[0].Name != "b" || ms[1].Name != "c" {
+		t.Errorf("got %+v, want b and c", ms)
+	}
+	do(t, r, "GET", "/items?limit=x", "", http.StatusBadRequest)
+	do(t, r, "GET", "/items?offset=x", "", http.StatusBadRequest)
+}
+
+func TestPut(t *testing.T) {
+	r := newRouter(t)
+	do(t, r, "POST", "/items", ` + "`" + `{"name":"a"}` + "`" + `, http.StatusCreated)
+	var m Items
+	if err := json.Unmarshal(do(t, r, "PUT", "/items/1", ` + "`" + `{"name":"b","version":0}` + "`" + `, http.StatusOK), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.ID != 1 || m.Name != "b" || m.Version != 1 {
+		t.Errorf("updated %+v, want b at version 1", m)
+	}
+	// the row moved on to version 1
+	do(t, r, "PUT", "/items/1", ` + "`" + `{"name":"c","version":0}` + "`" + `, http.StatusConflict)
+	do(t, r, "PUT", "/items/1", ` + "`" + `{"name":"c"}` + "`" + `, http.StatusBadRequest)
+	do(t, r, "PUT", "/items/1", "{", http.StatusBadRequest)
+	do(t, r, "PUT", "/items/9", ` + "`" + `{"name":"c","version":0}` + "`" + `, http.StatusNotFound)
+	do(t, r, "PUT", "/items/x", ` + "`" + `{"name":"c","version":0}` + "`" + `, http.StatusBadRequest)
+	if err := json.Unmarshal(do(t, r, "GET", "/items/1", "", http.StatusOK), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "b" || m.Version != 1 {
+		t.Errorf("got %+v, want b at version 1", m)
+	}
+
+	do(t, r, "POST", "/tags", ` + "`" + `{"name":"go","note":"gopher"}` + "`" + `, http.StatusCreated)
+	do(t, r, "PUT", "/tags/go", ` + "`" + `{"note":"mascot"}` + "`" + `, http.StatusOK)
+	var tag Tags
+	if err := json.Unmarshal(do(t, r, "GET", "/tags/go", "", http.StatusOK), &tag); err != nil {
+		t.Fatal(err)
+	}
+	if tag.Note != "mascot" {
+		t.Errorf("got %+v", tag)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	r := newRouter(t)
+	do(t, r, "POST", "/items", ` + "`" + `{"name":"a"}` + "`" + `, http.StatusCreated)
+	do(t, r, "DELETE", "/items/1", "", http.StatusNoContent)
+	do(t, r, "GET", "/items/1", "", http.StatusNotFound)
+	do(t, r, "DELETE", "/items/1", "", http.StatusNotFound)
+	do(t, r, "DELETE", "/items/x", "", http.StatusBadRequest)
+}
+`
+
+func TestHTTPGin(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", httpSchema)
+	if err := run("-gen-http", "gin", "-version-column", "version", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	items := gofmt(t, readFile(t, "model/items_http.go"))
+	for _, want := range []string{
+		"v, err := strconv.ParseInt(c.Param(\"id\"), 10, 64)",
+		"if errors.As(err, &stale) {\n\t\treturn http.StatusConflict",
+		`if _, ok := fields["version"]; !ok {`,
+	} {
+		if !strings.Contains(items, want) {
+			t.Errorf("items_http.go lacks %s:\n%s", want, items)
+		}
+	}
+	tags := gofmt(t, readFile(t, "model/tags_http.go"))
+	if !strings.Contains(tags, `id := c.Param("id")`) {
+		t.Errorf("tags_http.go does not take the name as is:\n%s", tags)
+	}
+	if strings.Contains(tags, "ErrStaleVersion") {
+		t.Errorf("tags_http.go checks the version of an unversioned table:\n%s", tags)
+	}
+	writeFile(t, "model/http_test.go", httpTest)
+	goTest(t, "./model")
+}
+
+func TestHTTPGinVersionExcluded(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", httpSchema)
+	if err := run("-gen-http", "gin", "-version-column", "version", "-json-exclude", "version", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	items := gofmt(t, readFile(t, "model/items_http.go"))
+	if strings.Contains(items, "binding.JSON") {
+		t.Errorf("items_http.go requires the excluded version:\n%s", items)
+	}
+	if !strings.Contains(items, "m.Version = cur.Version") {
+		t.Errorf("items_http.go does not update the row it read:\n%s", items)
+	}
+	goTest(t, "./model")
+}
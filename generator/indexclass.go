@@ -0,0 +1,162 @@
+package generator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// fulltextIndexes holds the FULLTEXT indexes, which the sql parser does not
+// know and is handed as plain KEY definitions.
+var fulltextIndexes = make(map[*sqlparser.IndexDefinition]bool)
+
+// unnamedIndex is the name unnamed indexes are given for the sql parser,
+// which requires one, until markIndexes clears it.
+const unnamedIndex = "dalgen_unnamed_"
+
+// indexRewrite is an index definition rewritten for the sql parser.
+type indexRewrite struct {
+	name     string
+	fulltext bool
+	unnamed  bool
+}
+
+// rewriteIndexDef rewrites a FULLTEXT index definition to a KEY, since the
+// sql parser does not know them, and gives unnamed KEY, INDEX, UNIQUE and
+// SPATIAL definitions the placeholder name n. The name of the rewrite is ""
+// when def is neither.
+func rewriteIndexDef(def string, n int) (string, indexRewrite) {
+	if strings.HasPrefix(strings.TrimSpace(def), "`") {
+		// a column named after a keyword
+		return def, indexRewrite{}
+	}
+	kind, rest := sqlWord(def)
+	kind = strings.ToLower(kind)
+	switch kind {
+	case "fulltext", "spatial", "unique":
+		if r, ok := sqlKeyword(rest, "key"); ok {
+			rest = r
+		} else if r, ok := sqlKeyword(rest, "index"); ok {
+			rest = r
+		}
+	case "key", "index":
+	default:
+		return def, indexRewrite{}
+	}
+	var rw indexRewrite
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "(") {
+		rw.name, rw.unnamed = unnamedIndex+strconv.Itoa(n), true
+	} else {
+		rw.name, rest = sqlWord(rest)
+		rest = strings.TrimSpace(rest)
+	}
+	end := closingParen(rest, 0)
+	if !strings.HasPrefix(rest, "(") || end < 0 {
+		return def, indexRewrite{}
+	}
+	columns := rest[:end+1]
+	switch kind {
+	case "fulltext":
+		// WITH PARSER and the other options are dropped along
+		rw.fulltext = true
+		return " KEY `" + rw.name + "` " + columns, rw
+	case "spatial":
+		kind = "SPATIAL KEY"
+	case "unique":
+		kind = "UNIQUE KEY"
+	default:
+		kind = "KEY"
+	}
+	if !rw.unnamed {
+		return def, indexRewrite{}
+	}
+	return " " + kind + " `" + rw.name + "` " + columns + rest[end+1:], rw
+}
+
+// rewriteIndexes rewrites the FULLTEXT and unnamed indexes of a CREATE
+// TABLE statement for the sql parser.
+func rewriteIndexes(stmt string) (string, []indexRewrite) {
+	open, end, ok := createTableBody(stmt)
+	if !ok {
+		return stmt, nil
+	}
+	defs := splitTopLevel(stmt[open+1 : end])
+	var rewrites []indexRewrite
+	for i, def := range defs {
+		d, rw := rewriteIndexDef(def, i)
+		if rw.name == "" {
+			continue
+		}
+		defs[i] = d
+		rewrites = append(rewrites, rw)
+	}
+	if rewrites == nil {
+		return stmt, nil
+	}
+	return stmt[:open+1] + strings.Join(defs, ",") + stmt[end:], rewrites
+}
+
+// markIndexes records the FULLTEXT indexes rewritten in the statement of
+// ddl, and clears the placeholder names of the unnamed ones.
+func markIndexes(ddl *sqlparser.DDL, rewrites []indexRewrite) {
+	for _, rw := range rewrites {
+		for _, idx := range ddl.TableSpec.Indexes {
+			if idx.Info.Name.String() != rw.name {
+				continue
+			}
+			if rw.fulltext {
+				fulltextIndexes[idx] = true
+			}
+			if rw.unnamed {
+				idx.Info.Name = sqlparser.NewColIdent("")
+			}
+		}
+	}
+}
+
+// indexName returns the name of idx, or for an unnamed index one derived
+// from the table and its columns, e.g. idx_users_a_b, so that gorm
+// groups the columns of the index.
+func indexName(ddl *sqlparser.DDL, idx *sqlparser.IndexDefinition) string {
+	if name := idx.Info.Name.String(); name != "" {
+		return name
+	}
+	parts := []string{"idx", ddl.NewName.Name.String()}
+	for _, c := range idx.Columns {
+		parts = append(parts, c.Column.String())
+	}
+	return strings.Join(parts, "_")
+}
+
+// indexClass returns the gorm index class of idx, FULLTEXT or SPATIAL, ""
+// for the other indexes.
+func indexClass(idx *sqlparser.IndexDefinition) string {
+	switch {
+	case fulltextIndexes[idx]:
+		return "FULLTEXT"
+	case idx.Info.Spatial:
+		return "SPATIAL"
+	}
+	return ""
+}
+
+// indexClassTags returns the gorm index tags, e.g. index:ft_body,class:FULLTEXT,
+// of the columns of ddl covered by FULLTEXT and SPATIAL indexes, so that
+// AutoMigrate recreates them. The other non-unique indexes have none.
+func indexClassTags(ddl *sqlparser.DDL) map[string][]string {
+	tags := make(map[string][]string)
+	for _, idx := range ddl.TableSpec.Indexes {
+		class := indexClass(idx)
+		if class == "" {
+			continue
+		}
+		index := indexName(ddl, idx)
+		for _, ic := range idx.Columns {
+			name := ic.Column.String()
+			tags[name] = append(tags[name], "index:"+index+",class:"+class)
+		}
+	}
+	return tags
+}
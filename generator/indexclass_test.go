@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const indexClassTest = `package model
+
+import (
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestIndexClasses(t *testing.T) {
+	s, err := schema.Parse(&Posts{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, idx := range s.ParseIndexes() {
+		got[idx.Name] = idx.Class
+		for _, f := range idx.Fields {
+			got[idx.Name] += " " + f.DBName
+		}
+	}
+	want := map[string]string{
+		"ft_body":       "FULLTEXT body",
+		"ft_title_body": "FULLTEXT title body",
+		"sp_loc":        "SPATIAL location",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got indexes %v", got)
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("index %s = %q, want %q", name, got[name], w)
+		}
+	}
+}
+`
+
+func TestIndexClasses(t *testing.T) {
+	schema := testdataPath(t, "indexclass.sql")
+	chdir(t)
+	if err := run("-strict", "-dal", schema); err != nil {
+		t.Fatal(err)
+	}
+	posts := gofmt(t, readFile(t, modelPath("posts")))
+	for _, want := range []string{
+		"`gorm:\"Column:title;size:255;index:ft_title_body,class:FULLTEXT\" json:\"title\"`",
+		"`gorm:\"Column:body;index:ft_body,class:FULLTEXT;index:ft_title_body,class:FULLTEXT\" json:\"body\"`",
+		"`gorm:\"Column:location;type:point;index:sp_loc,class:SPATIAL\" json:\"location\"`",
+	} {
+		if !strings.Contains(posts, want) {
+			t.Errorf("no %s in:\n%s", want, posts)
+		}
+	}
+	// the finders are those of the unique indexes alone
+	dal := readFile(t, "model/posts_dal.go")
+	if !strings.Contains(dal, "GetBySlug(") {
+		t.Errorf("no GetBySlug in:\n%s", dal)
+	}
+	for _, unwanted := range []string{"GetByBody", "GetByTitle", "GetByLocation"} {
+		if strings.Contains(dal, unwanted) {
+			t.Errorf("%s generated for a FULLTEXT or SPATIAL index:\n%s", unwanted, dal)
+		}
+	}
+	writeFile(t, "model/indexclass_test.go", indexClassTest)
+	goTest(t, "./model")
+}
+
+const unnamedIndexSchema = "CREATE TABLE `tags` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `a` int NOT NULL,\n" +
+	"  `b` int NOT NULL,\n" +
+	"  `name` text NOT NULL,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  UNIQUE (`a`,`b`),\n" +
+	"  FULLTEXT (`b`, `name`)\n" +
+	");"
+
+const unnamedIndexTest = `package model
+
+import (
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestUnnamedIndex(t *testing.T) {
+	s, err := schema.Parse(&Tags{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := s.LookIndex("idx_tags_b_name")
+	if idx == nil || idx.Class != "FULLTEXT" || len(idx.Fields) != 2 {
+		t.Fatalf("got %+v", idx)
+	}
+	if idx.Fields[0].DBName != "b" || idx.Fields[1].DBName != "name" {
+		t.Errorf("index columns %s and %s", idx.Fields[0].DBName, idx.Fields[1].DBName)
+	}
+}
+`
+
+func TestUnnamedIndexName(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", unnamedIndexSchema)
+	if err := run("-strict", "-dal", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	tags := gofmt(t, readFile(t, modelPath("tags")))
+	// both columns share the derived name, so gorm makes one index of them
+	for _, want := range []string{
+		"`gorm:\"Column:b;index:idx_tags_b_name,class:FULLTEXT\" json:\"b\"`",
+		"`gorm:\"Column:name;index:idx_tags_b_name,class:FULLTEXT\" json:\"name\"`",
+	} {
+		if !strings.Contains(tags, want) {
+			t.Errorf("no %s in:\n%s", want, tags)
+		}
+	}
+	if dal := readFile(t, "model/tags_dal.go"); !strings.Contains(dal, "GetByAAndB(") {
+		t.Errorf("no GetByAAndB for the unnamed unique index:\n%s", dal)
+	}
+	writeFile(t, "model/unnamed_test.go", unnamedIndexTest)
+	goTest(t, "./model")
+}
+
+func TestIndexName(t *testing.T) {
+	reset()
+	ddls, err := ParseSQLs(unnamedIndexSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddl := ddls[0]
+	var got []string
+	for _, idx := range ddl.TableSpec.Indexes {
+		got = append(got, indexName(ddl, idx))
+	}
+	if want := "PRIMARY idx_tags_a_b idx_tags_b_name"; strings.Join(got, " ") != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,361 @@
+package generator
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The information_schema rows a schema is introspected from.
+type (
+	infoTable struct {
+		Name    string
+		Comment string
+	}
+	infoColumn struct {
+		Table      string
+		Name       string
+		Type       string // COLUMN_TYPE, e.g. int(10) unsigned or enum('a','b')
+		Nullable   bool
+		Default    sql.NullString
+		Extra      string // e.g. auto_increment, on update CURRENT_TIMESTAMP
+		Generation string // GENERATION_EXPRESSION of the generated columns
+		Comment    string
+	}
+	infoIndex struct {
+		Table     string
+		Name      string
+		NonUnique bool
+		Column    string
+		SubPart   sql.NullInt64
+		Type      string // INDEX_TYPE, e.g. BTREE or FULLTEXT
+	}
+	infoView struct {
+		Name       string
+		Definition string // VIEW_DEFINITION, the SELECT of the view
+	}
+	infoForeignKey struct {
+		Table     string
+		Name      string
+		Column    string
+		RefSchema string
+		RefTable  string
+		RefColumn string
+	}
+)
+
+// infoSchema holds the information_schema rows of a database, in their
+// ORDINAL_POSITION and SEQ_IN_INDEX order.
+type infoSchema struct {
+	Schema      string
+	Tables      []infoTable
+	Columns     []infoColumn
+	Indexes     []infoIndex
+	ForeignKeys []infoForeignKey
+	Views       []infoView
+}
+
+// introspectMySQL reads the schema of the database of dsn from its
+// information_schema and returns it as the CREATE TABLE statements the
+// sql parser reads. The DSN, TLS and timeout parameters included, is
+// passed to the driver as is.
+func introspectMySQL(dsn string) ([]byte, error) {
+	if !driverRegistered("mysql") {
+		return nil, fmt.Errorf("-dsn: dalgen was built without the MySQL driver, build it with -tags mysql")
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("-dsn: %v", err)
+	}
+	defer db.Close()
+	schema, err := queryInfoSchema(db, introspectTables)
+	if err != nil {
+		return nil, fmt.Errorf("-dsn: %v", err)
+	}
+	if len(schema.Tables) == 0 {
+		return nil, fmt.Errorf("-dsn: no tables in database %s", schema.Schema)
+	}
+	inputSpans = []inputSpan{{"information_schema." + schema.Schema, 1}}
+	return []byte(schema.SQL()), nil
+}
+
+func driverRegistered(name string) bool {
+	for _, d := range sql.Drivers() {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tableFilter returns the condition limiting an information_schema query
+// to the tables matching the comma separated -introspect-tables globs, ""
+// when there are none, and its arguments.
+func tableFilter(globs string) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	like := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "*", "%", "?", "_")
+	for _, g := range strings.Split(globs, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			conds = append(conds, "TABLE_NAME LIKE ?")
+			args = append(args, like.Replace(g))
+		}
+	}
+	if conds == nil {
+		return "", nil
+	}
+	return " AND (" + strings.Join(conds, " OR ") + ")", args
+}
+
+// queryInfoSchema reads the tables of the current database of db, limited
+// to the -introspect-tables globs.
+func queryInfoSchema(db *sql.DB, globs string) (*infoSchema, error) {
+	s := &infoSchema{}
+	if err := db.QueryRow("SELECT DATABASE()").Scan(&s.Schema); err != nil {
+		return nil, err
+	}
+	if s.Schema == "" {
+		return nil, fmt.Errorf("the DSN names no database")
+	}
+	filter, filterArgs := tableFilter(globs)
+	args := append([]interface{}{s.Schema}, filterArgs...)
+	query := func(q string, scan func(*sql.Rows) error) error {
+		rows, err := db.Query(q, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	}
+	err := query("SELECT TABLE_NAME, TABLE_COMMENT FROM information_schema.TABLES"+
+		" WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'"+filter+" ORDER BY TABLE_NAME",
+		func(rows *sql.Rows) error {
+			var t infoTable
+			err := rows.Scan(&t.Name, &t.Comment)
+			s.Tables = append(s.Tables, t)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	err = query("SELECT TABLE_NAME, COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, EXTRA,"+
+		" COALESCE(GENERATION_EXPRESSION, ''), COLUMN_COMMENT FROM information_schema.COLUMNS"+
+		" WHERE TABLE_SCHEMA = ?"+filter+" ORDER BY TABLE_NAME, ORDINAL_POSITION",
+		func(rows *sql.Rows) error {
+			var c infoColumn
+			var nullable string
+			err := rows.Scan(&c.Table, &c.Name, &c.Type, &nullable, &c.Default, &c.Extra, &c.Generation, &c.Comment)
+			c.Nullable = nullable == "YES"
+			s.Columns = append(s.Columns, c)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	err = query("SELECT TABLE_NAME, INDEX_NAME, NON_UNIQUE, COLUMN_NAME, SUB_PART, INDEX_TYPE"+
+		" FROM information_schema.STATISTICS"+
+		" WHERE TABLE_SCHEMA = ?"+filter+" ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX",
+		func(rows *sql.Rows) error {
+			var idx infoIndex
+			err := rows.Scan(&idx.Table, &idx.Name, &idx.NonUnique, &idx.Column, &idx.SubPart, &idx.Type)
+			s.Indexes = append(s.Indexes, idx)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	err = query("SELECT TABLE_NAME, CONSTRAINT_NAME, COLUMN_NAME,"+
+		" REFERENCED_TABLE_SCHEMA, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME"+
+		" FROM information_schema.KEY_COLUMN_USAGE"+
+		" WHERE TABLE_SCHEMA = ? AND REFERENCED_TABLE_NAME IS NOT NULL"+filter+
+		" ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION",
+		func(rows *sql.Rows) error {
+			var fk infoForeignKey
+			err := rows.Scan(&fk.Table, &fk.Name, &fk.Column, &fk.RefSchema, &fk.RefTable, &fk.RefColumn)
+			s.ForeignKeys = append(s.ForeignKeys, fk)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	if !viewsFlag {
+		return s, nil
+	}
+	err = query("SELECT TABLE_NAME, VIEW_DEFINITION FROM information_schema.VIEWS"+
+		" WHERE TABLE_SCHEMA = ?"+filter+" ORDER BY TABLE_NAME",
+		func(rows *sql.Rows) error {
+			var v infoView
+			err := rows.Scan(&v.Name, &v.Definition)
+			s.Views = append(s.Views, v)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// sqlString quotes s as a MySQL string literal.
+func sqlString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+func quoteIdent(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+// SQL renders the tables of s as CREATE TABLE statements, followed by the
+// CREATE VIEW statements of its views.
+func (s *infoSchema) SQL() string {
+	columns := make(map[string][]infoColumn)
+	for _, c := range s.Columns {
+		columns[c.Table] = append(columns[c.Table], c)
+	}
+	indexes := make(map[string][][]infoIndex)
+	for _, idx := range s.Indexes {
+		list := indexes[idx.Table]
+		if n := len(list); n > 0 && list[n-1][0].Name == idx.Name {
+			list[n-1] = append(list[n-1], idx)
+		} else {
+			list = append(list, []infoIndex{idx})
+		}
+		indexes[idx.Table] = list
+	}
+	fks := make(map[string][][]infoForeignKey)
+	for _, fk := range s.ForeignKeys {
+		list := fks[fk.Table]
+		if n := len(list); n > 0 && list[n-1][0].Name == fk.Name {
+			list[n-1] = append(list[n-1], fk)
+		} else {
+			list = append(list, []infoForeignKey{fk})
+		}
+		fks[fk.Table] = list
+	}
+
+	var b strings.Builder
+	for _, t := range s.Tables {
+		var defs []string
+		for _, c := range columns[t.Name] {
+			defs = append(defs, c.definition())
+		}
+		list := indexes[t.Name]
+		// the primary key first, as SHOW CREATE TABLE does
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i][0].Name == "PRIMARY" && list[j][0].Name != "PRIMARY"
+		})
+		for _, idx := range list {
+			defs = append(defs, indexDefinition(idx))
+		}
+		for _, fk := range fks[t.Name] {
+			defs = append(defs, s.foreignKeyDefinition(fk))
+		}
+		b.WriteString("CREATE TABLE " + quoteIdent(t.Name) + " (\n  " + strings.Join(defs, ",\n  ") + "\n)")
+		if t.Comment != "" {
+			b.WriteString(" COMMENT=" + sqlString(t.Comment))
+		}
+		b.WriteString(";\n\n")
+	}
+	for _, v := range s.Views {
+		b.WriteString("CREATE VIEW " + quoteIdent(v.Name) + " AS " + v.Definition + ";\n\n")
+	}
+	return b.String()
+}
+
+// definition renders the column as in a CREATE TABLE statement.
+func (c infoColumn) definition() string {
+	def := quoteIdent(c.Name) + " " + c.Type
+	extra := strings.ToLower(c.Extra)
+	if c.Generation != "" {
+		kind := "VIRTUAL"
+		if strings.Contains(extra, "stored") {
+			kind = "STORED"
+		}
+		def += " GENERATED ALWAYS AS (" + c.Generation + ") " + kind
+	}
+	if !c.Nullable {
+		def += " NOT NULL"
+	}
+	if c.Default.Valid && c.Generation == "" {
+		def += " DEFAULT " + c.defaultLiteral()
+	}
+	if strings.Contains(extra, "auto_increment") {
+		def += " AUTO_INCREMENT"
+	}
+	if i := strings.Index(extra, "on update "); i >= 0 {
+		def += " ON UPDATE " + strings.Fields(c.Extra[i+len("on update "):])[0]
+	}
+	if c.Comment != "" {
+		def += " COMMENT " + sqlString(c.Comment)
+	}
+	return def
+}
+
+// defaultLiteral returns the COLUMN_DEFAULT of the column as a SQL
+// expression. MySQL reports string defaults unquoted, MariaDB quoted, and
+// both report expressions like CURRENT_TIMESTAMP as is.
+func (c infoColumn) defaultLiteral() string {
+	v := c.Default.String
+	switch {
+	case strings.HasPrefix(v, "'"), strings.HasPrefix(v, "b'"), strings.EqualFold(v, "NULL"):
+		return v
+	case strings.Contains(strings.ToUpper(c.Extra), "DEFAULT_GENERATED"), currentTimestamp.MatchString(v):
+		return v
+	}
+	switch t := strings.ToLower(c.Type); {
+	case strings.Contains(t, "char"), strings.Contains(t, "text"), strings.HasPrefix(t, "enum"), strings.HasPrefix(t, "set"):
+	default:
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return v
+		}
+	}
+	return sqlString(v)
+}
+
+// indexDefinition renders the rows of an index as in a CREATE TABLE
+// statement.
+func indexDefinition(rows []infoIndex) string {
+	cols := make([]string, 0, len(rows))
+	for _, r := range rows {
+		col := quoteIdent(r.Column)
+		if r.SubPart.Valid {
+			col += fmt.Sprintf("(%d)", r.SubPart.Int64)
+		}
+		cols = append(cols, col)
+	}
+	first := rows[0]
+	kind := "KEY " + quoteIdent(first.Name)
+	switch {
+	case first.Name == "PRIMARY":
+		kind = "PRIMARY KEY"
+	case strings.EqualFold(first.Type, "FULLTEXT"), strings.EqualFold(first.Type, "SPATIAL"):
+		kind = strings.ToUpper(first.Type) + " " + kind
+	case !first.NonUnique:
+		kind = "UNIQUE " + kind
+	}
+	return kind + " (" + strings.Join(cols, ", ") + ")"
+}
+
+// foreignKeyDefinition renders the rows of a foreign key as in a CREATE
+// TABLE statement, qualifying the referenced table of another schema.
+func (s *infoSchema) foreignKeyDefinition(rows []infoForeignKey) string {
+	cols := make([]string, 0, len(rows))
+	refs := make([]string, 0, len(rows))
+	for _, r := range rows {
+		cols = append(cols, quoteIdent(r.Column))
+		refs = append(refs, quoteIdent(r.RefColumn))
+	}
+	first := rows[0]
+	ref := quoteIdent(first.RefTable)
+	if first.RefSchema != s.Schema {
+		ref = quoteIdent(first.RefSchema) + "." + ref
+	}
+	return "CONSTRAINT " + quoteIdent(first.Name) + " FOREIGN KEY (" + strings.Join(cols, ", ") +
+		") REFERENCES " + ref + " (" + strings.Join(refs, ", ") + ")"
+}
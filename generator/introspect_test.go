@@ -0,0 +1,199 @@
+package generator
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeInfo is a database whose information_schema holds the rows of the
+// table its queries select from, for queryInfoSchema.
+type fakeInfo struct {
+	rows    map[string][][]driver.Value
+	queries []string
+	args    [][]driver.Value
+}
+
+func (f *fakeInfo) Connect(context.Context) (driver.Conn, error) { return fakeInfoConn{f}, nil }
+func (f *fakeInfo) Driver() driver.Driver                        { return nil }
+
+type fakeInfoConn struct{ f *fakeInfo }
+
+func (c fakeInfoConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeInfoStmt{c.f, query}, nil
+}
+func (c fakeInfoConn) Close() error              { return nil }
+func (c fakeInfoConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type fakeInfoStmt struct {
+	f     *fakeInfo
+	query string
+}
+
+func (s fakeInfoStmt) Close() error  { return nil }
+func (s fakeInfoStmt) NumInput() int { return -1 }
+func (s fakeInfoStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s fakeInfoStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.f.queries = append(s.f.queries, s.query)
+	s.f.args = append(s.f.args, args)
+	if s.query == "SELECT DATABASE()" {
+		return &fakeInfoRows{rows: [][]driver.Value{{"shop"}}}, nil
+	}
+	for table, rows := range s.f.rows {
+		if strings.Contains(s.query, "FROM information_schema."+table+" ") {
+			return &fakeInfoRows{rows: rows}, nil
+		}
+	}
+	return &fakeInfoRows{}, nil
+}
+
+type fakeInfoRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeInfoRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *fakeInfoRows) Close() error { return nil }
+
+func (r *fakeInfoRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// shopInfo are the information_schema rows of a users and an orders table.
+func shopInfo() *fakeInfo {
+	return &fakeInfo{rows: map[string][][]driver.Value{
+		"TABLES": {
+			{"orders", ""},
+			{"users", "registered users"},
+		},
+		"COLUMNS": {
+			{"orders", "id", "bigint unsigned", "NO", nil, "auto_increment", "", ""},
+			{"orders", "user_id", "bigint unsigned", "NO", nil, "", "", ""},
+			{"orders", "total", "decimal(10,2)", "NO", "0.00", "", "", ""},
+			{"users", "id", "bigint unsigned", "NO", nil, "auto_increment", "", ""},
+			{"users", "email", "varchar(255)", "NO", nil, "", "", "login e-mail"},
+			{"users", "status", "enum('active','banned')", "NO", "active", "", "", ""},
+			{"users", "bio", "text", "YES", nil, "", "", ""},
+			{"users", "created_at", "datetime", "NO", "CURRENT_TIMESTAMP", "DEFAULT_GENERATED", "", ""},
+		},
+		"STATISTICS": {
+			{"orders", "PRIMARY", int64(0), "id", nil, "BTREE"},
+			{"orders", "idx_user", int64(1), "user_id", nil, "BTREE"},
+			{"users", "PRIMARY", int64(0), "id", nil, "BTREE"},
+			{"users", "ft_bio", int64(1), "bio", nil, "FULLTEXT"},
+			{"users", "uk_email", int64(0), "email", int64(64), "BTREE"},
+		},
+		"KEY_COLUMN_USAGE": {
+			{"orders", "fk_orders_user", "user_id", "shop", "users", "id"},
+		},
+	}}
+}
+
+func TestQueryInfoSchema(t *testing.T) {
+	reset()
+	f := shopInfo()
+	db := sql.OpenDB(f)
+	defer db.Close()
+	s, err := queryInfoSchema(db, "users, ord*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the tables of the current database matching the globs
+	if len(f.queries) != 5 {
+		t.Fatalf("%d queries:\n%s", len(f.queries), strings.Join(f.queries, "\n"))
+	}
+	for i, q := range f.queries[1:] {
+		if !strings.Contains(q, "WHERE TABLE_SCHEMA = ?") || !strings.Contains(q, " AND (TABLE_NAME LIKE ? OR TABLE_NAME LIKE ?)") {
+			t.Errorf("query %s is not limited to the tables", q)
+		}
+		if want := []driver.Value{"shop", "users", "ord%"}; !reflect.DeepEqual(f.args[i+1], want) {
+			t.Errorf("query %s has arguments %v, want %v", q, f.args[i+1], want)
+		}
+	}
+	if s.Schema != "shop" || len(s.Tables) != 2 || len(s.Columns) != 8 || len(s.Indexes) != 5 || len(s.ForeignKeys) != 1 {
+		t.Fatalf("got %+v", s)
+	}
+	if c := s.Columns[6]; !c.Nullable || c.Default.Valid {
+		t.Errorf("bio is %+v, want a nullable column without a default", c)
+	}
+	if idx := s.Indexes[4]; idx.NonUnique || !idx.SubPart.Valid || idx.SubPart.Int64 != 64 {
+		t.Errorf("uk_email is %+v", idx)
+	}
+}
+
+func TestIntrospectedModels(t *testing.T) {
+	reset()
+	db := sql.OpenDB(shopInfo())
+	defer db.Close()
+	s, err := queryInfoSchema(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "CREATE TABLE `users` (\n" +
+		"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `email` varchar(255) NOT NULL COMMENT 'login e-mail',\n" +
+		"  `status` enum('active','banned') NOT NULL DEFAULT 'active',\n" +
+		"  `bio` text,\n" +
+		"  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  FULLTEXT KEY `ft_bio` (`bio`),\n" +
+		"  UNIQUE KEY `uk_email` (`email`(64))\n" +
+		") COMMENT='registered users';\n"
+	sql := s.SQL()
+	if !strings.Contains(sql, want) {
+		t.Errorf("SQL() lacks\n%s\nin:\n%s", want, sql)
+	}
+	if want := "  CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)\n"; !strings.Contains(sql, want) {
+		t.Errorf("SQL() lacks\n%s\nin:\n%s", want, sql)
+	}
+
+	// the generators take the introspected schema like a schema file
+	chdir(t)
+	writeFile(t, "schema.sql", sql)
+	if err := run("-strict", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	users := gofmt(t, readFile(t, modelPath("users")))
+	for _, want := range []string{
+		"// Users is the registered users.\n",
+		"ID        int64       `gorm:\"Column:id;primaryKey;autoIncrement\" json:\"id\"`",
+		"Email     string      `gorm:\"Column:email;size:255;unique\" json:\"email\"` // login e-mail\n",
+		"Status    UsersStatus `gorm:\"Column:status\" json:\"status\"`",
+		"Bio       string      `gorm:\"Column:bio;index:ft_bio,class:FULLTEXT\" json:\"bio\"`",
+		"CreatedAt time.Time   `gorm:\"Column:created_at;autoCreateTime\" json:\"created_at\"`",
+		"UsersStatusBanned UsersStatus = \"banned\"",
+	} {
+		if !strings.Contains(users, want) {
+			t.Errorf("no %s in:\n%s", want, users)
+		}
+	}
+	goTest(t, "./model")
+}
+
+func TestIntrospectWithoutDriver(t *testing.T) {
+	if driverRegistered("mysql") {
+		t.Skip("built with the MySQL driver")
+	}
+	chdir(t)
+	err := run("-dsn", "user:pass@tcp(localhost:3306)/shop?tls=true&timeout=5s")
+	if want := "-dsn: dalgen was built without the MySQL driver, build it with -tags mysql"; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %s", err, want)
+	}
+}
@@ -0,0 +1,409 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// irVersion is the version of the -emit ir document. It is bumped when a
+// field changes meaning or goes away; new fields leave it alone.
+const irVersion = 1
+
+// irSchema is the -emit ir document: the parsed schema as JSON, for other
+// tools to read without parsing SQL, and for -from-ir to generate from.
+type irSchema struct {
+	IRVersion int       `json:"ir_version"`
+	Dialect   string    `json:"dialect"`
+	Tables    []irTable `json:"tables"`
+}
+
+// irTable is a table or -views view. Comment and PrimaryKey are derived
+// from the options, indexes and columns, and ignored by -from-ir.
+type irTable struct {
+	Name        string         `json:"name"`
+	View        bool           `json:"view,omitempty"`
+	Dialect     string         `json:"dialect"`
+	Comment     string         `json:"comment,omitempty"`
+	Options     string         `json:"options,omitempty"`
+	PrimaryKey  []string       `json:"primary_key"`
+	Columns     []irColumn     `json:"columns"`
+	Indexes     []irIndex      `json:"indexes"`
+	ForeignKeys []irForeignKey `json:"foreign_keys"`
+	Checks      []irCheck      `json:"checks"`
+}
+
+// irColumn is a column. Type is its SQL type as parsed, e.g. varchar(64)
+// or int unsigned, Default and OnUpdate SQL literals or expressions and
+// Key its inline key, e.g. primary key. GoType is the resolved Go type,
+// ignored by -from-ir.
+type irColumn struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	GoType        string   `json:"go_type"`
+	Nullable      bool     `json:"nullable"`
+	Default       *string  `json:"default"`
+	OnUpdate      *string  `json:"on_update,omitempty"`
+	AutoIncrement bool     `json:"auto_increment,omitempty"`
+	Key           string   `json:"key,omitempty"`
+	Comment       *string  `json:"comment,omitempty"`
+	Bool          bool     `json:"bool,omitempty"`
+	Generated     bool     `json:"generated,omitempty"`
+	AutoRandom    string   `json:"auto_random,omitempty"`
+	PgEnum        string   `json:"pg_enum,omitempty"`
+	Check         *irCheck `json:"check,omitempty"`
+}
+
+type irIndex struct {
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	Primary  bool            `json:"primary,omitempty"`
+	Unique   bool            `json:"unique,omitempty"`
+	Spatial  bool            `json:"spatial,omitempty"`
+	Fulltext bool            `json:"fulltext,omitempty"`
+	Columns  []irIndexColumn `json:"columns"`
+	Options  []irIndexOption `json:"options,omitempty"`
+}
+
+type irIndexColumn struct {
+	Name   string `json:"name"`
+	Length *int   `json:"length,omitempty"`
+}
+
+type irIndexOption struct {
+	Name  string  `json:"name"`
+	Value *string `json:"value,omitempty"`
+	Using string  `json:"using,omitempty"`
+}
+
+type irForeignKey struct {
+	Name       string   `json:"name,omitempty"`
+	Columns    []string `json:"columns"`
+	RefSchema  string   `json:"ref_schema,omitempty"`
+	RefTable   string   `json:"ref_table"`
+	RefColumns []string `json:"ref_columns"`
+}
+
+type irCheck struct {
+	Name string `json:"name,omitempty"`
+	Expr string `json:"expr"`
+}
+
+// checkEmit validates -emit.
+func checkEmit() error {
+	switch emit {
+	case "go", "ir":
+		return nil
+	}
+	return fmt.Errorf("-emit %q, expected go or ir", emit)
+}
+
+// writeIR writes the -emit ir document of ddls to w.
+func writeIR(w io.Writer, ddls []*sqlparser.DDL) error {
+	s := irSchema{IRVersion: irVersion, Dialect: dialect, Tables: []irTable{}}
+	for _, ddl := range ddls {
+		s.Tables = append(s.Tables, newIRTable(ddl))
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+func newIRTable(ddl *sqlparser.DDL) irTable {
+	t := irTable{
+		Name:        ddl.NewName.Name.String(),
+		View:        viewTables[ddl],
+		Dialect:     dialect,
+		Comment:     tableComment(ddl),
+		Options:     strings.TrimSpace(ddl.TableSpec.Options),
+		PrimaryKey:  []string{},
+		Columns:     []irColumn{},
+		Indexes:     []irIndex{},
+		ForeignKeys: []irForeignKey{},
+		Checks:      []irCheck{},
+	}
+	if len(ddl.TableSpec.Columns) > 0 {
+		t.Dialect = columnDialect(ddl.TableSpec.Columns[0])
+	}
+	for _, idx := range ddl.TableSpec.Indexes {
+		if idx.Info.Primary {
+			for _, c := range idx.Columns {
+				t.PrimaryKey = append(t.PrimaryKey, c.Column.String())
+			}
+		}
+	}
+	for _, c := range ddl.TableSpec.Columns {
+		col := newIRColumn(c)
+		if len(t.PrimaryKey) == 0 && col.Key == "primary key" {
+			t.PrimaryKey = []string{col.Name}
+		}
+		t.Columns = append(t.Columns, col)
+	}
+	for _, idx := range ddl.TableSpec.Indexes {
+		t.Indexes = append(t.Indexes, newIRIndex(idx))
+	}
+	for _, fk := range tableForeignKeys[ddl] {
+		t.ForeignKeys = append(t.ForeignKeys, irForeignKey{fk.Name, fk.Columns, fk.RefSchema, fk.RefTable, fk.RefColumns})
+	}
+	for _, check := range tableChecks[ddl] {
+		t.Checks = append(t.Checks, irCheck{check.Name, check.Expr})
+	}
+	return t
+}
+
+func newIRColumn(c *sqlparser.ColumnDefinition) irColumn {
+	t := c.Type
+	t.NotNull, t.Autoincrement, t.KeyOpt = false, false, 0
+	t.Default, t.OnUpdate, t.Comment = nil, nil, nil
+	col := irColumn{
+		Name:          c.Name.String(),
+		Type:          sqlparser.String(&t),
+		GoType:        safeGoType(c),
+		Nullable:      !bool(c.Type.NotNull),
+		Default:       irValue(c.Type.Default),
+		OnUpdate:      irValue(c.Type.OnUpdate),
+		AutoIncrement: bool(c.Type.Autoincrement),
+		Key:           columnKey(c.Type.KeyOpt),
+		Bool:          boolColumns[c],
+		Generated:     generatedColumns[c],
+		AutoRandom:    autoRandomColumns[c],
+		PgEnum:        pgEnumColumns[c],
+	}
+	if c.Type.Comment != nil {
+		comment := string(c.Type.Comment.Val)
+		col.Comment = &comment
+	}
+	if check, ok := columnChecks[c]; ok {
+		col.Check = &irCheck{check.Name, check.Expr}
+	}
+	return col
+}
+
+func newIRIndex(idx *sqlparser.IndexDefinition) irIndex {
+	i := irIndex{
+		Name:     idx.Info.Name.String(),
+		Type:     idx.Info.Type,
+		Primary:  idx.Info.Primary,
+		Unique:   idx.Info.Unique,
+		Spatial:  idx.Info.Spatial,
+		Fulltext: fulltextIndexes[idx],
+		Columns:  []irIndexColumn{},
+	}
+	for _, c := range idx.Columns {
+		col := irIndexColumn{Name: c.Column.String()}
+		if c.Length != nil {
+			if n, err := strconv.Atoi(string(c.Length.Val)); err == nil {
+				col.Length = &n
+			}
+		}
+		i.Columns = append(i.Columns, col)
+	}
+	for _, o := range idx.Options {
+		i.Options = append(i.Options, irIndexOption{o.Name, irValue(o.Value), o.Using})
+	}
+	return i
+}
+
+func irValue(v *sqlparser.SQLVal) *string {
+	if v == nil {
+		return nil
+	}
+	s := sqlparser.String(v)
+	return &s
+}
+
+// columnKey returns the inline key of a column type, e.g. "primary key",
+// "" if none.
+func columnKey(opt sqlparser.ColumnKeyOption) string {
+	return strings.TrimSpace(strings.TrimPrefix(sqlparser.String(&sqlparser.ColumnType{Type: "int", KeyOpt: opt}), "int"))
+}
+
+// columnKeyOptions maps the inline keys back to the key options of the sql
+// parser, which keeps their names to itself; its options are numbered from
+// none, 0, on.
+var columnKeyOptions = func() map[string]sqlparser.ColumnKeyOption {
+	opts := make(map[string]sqlparser.ColumnKeyOption)
+	for opt := sqlparser.ColumnKeyOption(0); opt == 0 || columnKey(opt) != ""; opt++ {
+		opts[columnKey(opt)] = opt
+	}
+	return opts
+}()
+
+// readIR reads the tables of a -from-ir document, restoring what the
+// preprocessing of the SQL would have recorded about them, and returns
+// them along with the document.
+func readIR(file string) ([]*sqlparser.DDL, []byte, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("-from-ir: %v", err)
+	}
+	var s irSchema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, nil, fmt.Errorf("-from-ir: %s: %v", file, err)
+	}
+	if s.IRVersion != irVersion {
+		return nil, nil, fmt.Errorf("-from-ir: %s has ir_version %d, this dalgen reads ir_version %d", file, s.IRVersion, irVersion)
+	}
+	if s.Dialect != "" {
+		dialect = s.Dialect
+	}
+	var ddls []*sqlparser.DDL
+	for _, t := range s.Tables {
+		ddl, err := t.ddl()
+		if err != nil {
+			return nil, nil, fmt.Errorf("-from-ir: %s: table %s: %v", file, t.Name, err)
+		}
+		ddls = append(ddls, ddl)
+	}
+	return ddls, data, nil
+}
+
+func (t irTable) ddl() (*sqlparser.DDL, error) {
+	spec := &sqlparser.TableSpec{Options: t.Options}
+	if spec.Options != "" {
+		spec.Options = " " + spec.Options
+	}
+	ddl := &sqlparser.DDL{Action: "create", NewName: sqlparser.TableName{Name: sqlparser.NewTableIdent(t.Name)}, TableSpec: spec}
+	for _, col := range t.Columns {
+		c, err := col.definition()
+		if err != nil {
+			return nil, err
+		}
+		if t.Dialect != "" {
+			columnDialects[c] = t.Dialect
+		}
+		spec.Columns = append(spec.Columns, c)
+	}
+	for _, i := range t.Indexes {
+		idx := &sqlparser.IndexDefinition{Info: &sqlparser.IndexInfo{
+			Type:    i.Type,
+			Name:    sqlparser.NewColIdent(i.Name),
+			Primary: i.Primary,
+			Spatial: i.Spatial,
+			Unique:  i.Unique,
+		}}
+		for _, c := range i.Columns {
+			ic := &sqlparser.IndexColumn{Column: sqlparser.NewColIdent(c.Name)}
+			if c.Length != nil {
+				ic.Length = sqlparser.NewIntVal([]byte(strconv.Itoa(*c.Length)))
+			}
+			idx.Columns = append(idx.Columns, ic)
+		}
+		for _, o := range i.Options {
+			idx.Options = append(idx.Options, &sqlparser.IndexOption{Name: o.Name, Value: sqlValue(o.Value), Using: o.Using})
+		}
+		if i.Fulltext {
+			fulltextIndexes[idx] = true
+		}
+		spec.Indexes = append(spec.Indexes, idx)
+	}
+	for _, fk := range t.ForeignKeys {
+		tableForeignKeys[ddl] = append(tableForeignKeys[ddl], foreignKey{fk.Name, fk.Columns, fk.RefSchema, fk.RefTable, fk.RefColumns})
+	}
+	for _, check := range t.Checks {
+		tableChecks[ddl] = append(tableChecks[ddl], checkConstraint{check.Name, check.Expr})
+	}
+	if t.View {
+		viewTables[ddl] = true
+	}
+	return ddl, nil
+}
+
+func (col irColumn) definition() (*sqlparser.ColumnDefinition, error) {
+	ct, err := parseIRType(col.Type)
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %v", col.Name, err)
+	}
+	opt, ok := columnKeyOptions[col.Key]
+	if !ok {
+		return nil, fmt.Errorf("column %s: unknown key %q", col.Name, col.Key)
+	}
+	ct.NotNull = sqlparser.BoolVal(!col.Nullable)
+	ct.Autoincrement = sqlparser.BoolVal(col.AutoIncrement)
+	ct.KeyOpt = opt
+	ct.Default = sqlValue(col.Default)
+	ct.OnUpdate = sqlValue(col.OnUpdate)
+	if col.Comment != nil {
+		ct.Comment = sqlparser.NewStrVal([]byte(*col.Comment))
+	}
+	c := &sqlparser.ColumnDefinition{Name: sqlparser.NewColIdent(col.Name), Type: ct}
+	if col.Bool {
+		boolColumns[c] = true
+	}
+	if col.Generated {
+		generatedColumns[c] = true
+	}
+	if col.AutoRandom != "" {
+		autoRandomColumns[c] = col.AutoRandom
+	}
+	if col.PgEnum != "" {
+		pgEnumColumns[c] = col.PgEnum
+	}
+	if col.Check != nil {
+		columnChecks[c] = checkConstraint{col.Check.Name, col.Check.Expr}
+	}
+	return c, nil
+}
+
+// parseIRType parses the SQL type of an IR column as the sql parser does,
+// by hand for the types it does not know, such as the Postgres ones.
+func parseIRType(typ string) (sqlparser.ColumnType, error) {
+	if stmt, err := sqlparser.ParseStrictDDL("create table t (a " + typ + ")"); err == nil {
+		if ddl, ok := stmt.(*sqlparser.DDL); ok && ddl.TableSpec != nil && len(ddl.TableSpec.Columns) == 1 {
+			return ddl.TableSpec.Columns[0].Type, nil
+		}
+	}
+	var ct sqlparser.ColumnType
+	open := strings.IndexByte(typ, '(')
+	if open < 0 {
+		ct.Type = typ
+		return ct, nil
+	}
+	end := closingParen(typ, open)
+	if end < 0 {
+		return ct, fmt.Errorf("cannot parse type %q", typ)
+	}
+	ct.Type = typ[:open]
+	args := splitTopLevel(typ[open+1 : end])
+	for i := range args {
+		args[i] = strings.TrimSpace(args[i])
+	}
+	if ct.Type == "enum" || ct.Type == "set" {
+		ct.EnumValues = args
+	} else {
+		ct.Length = sqlparser.NewIntVal([]byte(args[0]))
+		if len(args) > 1 {
+			ct.Scale = sqlparser.NewIntVal([]byte(args[1]))
+		}
+	}
+	// the [] of the Postgres arrays follows the length
+	ct.Type += strings.TrimSpace(typ[end+1:])
+	return ct, nil
+}
+
+// sqlValue parses an IR SQL literal as the sql parser does. What is not a
+// literal, such as CURRENT_TIMESTAMP or a Postgres expression, is kept as
+// is.
+func sqlValue(s *string) *sqlparser.SQLVal {
+	if s == nil {
+		return nil
+	}
+	if stmt, err := sqlparser.Parse("select " + *s); err == nil {
+		if sel, ok := stmt.(*sqlparser.Select); ok && len(sel.SelectExprs) == 1 {
+			if e, ok := sel.SelectExprs[0].(*sqlparser.AliasedExpr); ok {
+				if v, ok := e.Expr.(*sqlparser.SQLVal); ok {
+					return v
+				}
+			}
+		}
+	}
+	return sqlparser.NewValArg([]byte(*s))
+}
@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// emitIR writes the -emit ir document of the schema files, under the
+// flags already set.
+func emitIR(t *testing.T, files ...string) []byte {
+	t.Helper()
+	parts, err := readInputs(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddls, err := parseSchema(parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := writeIR(&buf, ddls); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestIRGolden compares the -emit ir document of testdata/ir.sql with
+// testdata/ir.json, which go test -update rewrites.
+func TestIRGolden(t *testing.T) {
+	reset()
+	golden := filepath.Join("testdata", "ir.json")
+	got := emitIR(t, filepath.Join("testdata", "ir.sql"))
+	if *update {
+		if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("-emit ir differs from %s:\n%s", golden, got)
+	}
+}
+
+// TestIRRoundTrip checks that -from-ir generates the same files as the
+// schema the document was emitted from.
+func TestIRRoundTrip(t *testing.T) {
+	schema, err := ioutil.ReadFile(filepath.Join("testdata", "ir.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"-dal", "-gen-where", "-gen-compare"}
+	chdir(t)
+	writeFile(t, "schema.sql", string(schema))
+	if err := run(append(args, "schema.sql")...); err != nil {
+		t.Fatal(err)
+	}
+	want := readTree(t, "model")
+
+	reset()
+	writeFile(t, "schema.json", string(emitIR(t, "schema.sql")))
+	if err := os.RemoveAll("model"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(append(args, "-from-ir", "schema.json")...); err != nil {
+		t.Fatal(err)
+	}
+	got := readTree(t, "model")
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("-from-ir %s differs:\n%s\nwant\n%s", name, got[name], content)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("-from-ir wrote %s, not generated from the schema", name)
+		}
+	}
+}
+
+// readTree returns the content of the files under dir by path.
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(fp string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		files[fp] = readFile(t, fp)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
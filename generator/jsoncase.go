@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkJSONCase validates -json-case.
+func checkJSONCase() error {
+	switch jsonCase {
+	case "raw", "snake", "camel":
+		return nil
+	}
+	return fmt.Errorf("-json-case %q is not camel, snake or raw", jsonCase)
+}
+
+// jsonName is the json tag name of a column under -json-case: the column
+// name as is, in snake case, or in camel case with the initialisms of the
+// field names, so created_at becomes createdAt and user_id userID.
+func jsonName(column string) string {
+	pieces := strings.FieldsFunc(column, notIdentRune)
+	switch jsonCase {
+	case "snake":
+		for i, piece := range pieces {
+			pieces[i] = strings.ToLower(snakeCase(piece))
+		}
+		return strings.Join(pieces, "_")
+	case "camel":
+		for i, piece := range pieces {
+			pieces[i] = camelWord(piece)
+		}
+		if len(pieces) > 0 {
+			pieces[0] = lowerFirst(pieces[0])
+		}
+		return strings.Join(pieces, "")
+	}
+	return column
+}
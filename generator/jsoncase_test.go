@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const jsonCaseSchema = `CREATE TABLE users (
+  id bigint NOT NULL,
+  created_at datetime NOT NULL,
+  user_id bigint NOT NULL,
+  api_url varchar(64) NOT NULL,
+  ipAddress varchar(64) NOT NULL,
+  HTTPStatus int NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+const jsonCaseTest = `package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONCase(t *testing.T) {
+	b, err := json.Marshal(Users{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, k := range []string{"id", "createdAt", "userID", "apiURL", "ipAddress", "httpStatus"} {
+		if _, ok := m[k]; !ok {
+			t.Errorf("no %s in %s", k, b)
+		}
+		keys = append(keys, k)
+	}
+	if len(m) != len(keys) {
+		t.Errorf("got %s, want the keys %s", b, strings.Join(keys, " "))
+	}
+}
+`
+
+func TestJSONCase(t *testing.T) {
+	for _, tt := range []struct {
+		jsonCase string
+		want     []string
+	}{
+		{"camel", []string{
+			"`gorm:\"Column:created_at\" json:\"createdAt\"`",
+			"`gorm:\"Column:user_id\" json:\"userID\"`",
+			"`gorm:\"Column:api_url;size:64\" json:\"apiURL\"`",
+			"`gorm:\"Column:ipAddress;size:64\" json:\"ipAddress\"`",
+			"`gorm:\"Column:HTTPStatus\" json:\"httpStatus\"`",
+		}},
+		{"snake", []string{
+			"`gorm:\"Column:created_at\" json:\"created_at\"`",
+			"`gorm:\"Column:ipAddress;size:64\" json:\"ip_address\"`",
+			"`gorm:\"Column:HTTPStatus\" json:\"http_status\"`",
+		}},
+		{"raw", []string{
+			"`gorm:\"Column:created_at\" json:\"created_at\"`",
+			"`gorm:\"Column:ipAddress;size:64\" json:\"ipAddress\"`",
+			"`gorm:\"Column:HTTPStatus\" json:\"HTTPStatus\"`",
+		}},
+	} {
+		users := generate(t, jsonCaseSchema, "users", "-json-case", tt.jsonCase)
+		for _, want := range tt.want {
+			if !strings.Contains(users, want) {
+				t.Errorf("-json-case %s: no %s in:\n%s", tt.jsonCase, want, users)
+			}
+		}
+	}
+}
+
+func TestJSONCaseCamel(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", jsonCaseSchema)
+	if err := run("-json-case", "camel", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, "model/jsoncase_test.go", jsonCaseTest)
+	goTest(t, "./model")
+}
+
+func TestJSONCaseBad(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", jsonCaseSchema)
+	err := run("-json-case", "kebab", "schema.sql")
+	if want := `-json-case "kebab" is not camel, snake or raw`; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %s", err, want)
+	}
+}
@@ -0,0 +1,154 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// lockFile is the -lock file, fingerprinting what the code was last
+// generated from: the schema input, the dalgen build, the flags and the
+// definition of every table.
+type lockFile struct {
+	InputSHA256 string            `json:"input_sha256"`
+	Version     string            `json:"dalgen_version"`
+	Flags       []string          `json:"flags"`
+	Tables      map[string]string `json:"tables"`
+}
+
+// lockFlags are the flags left out of the lock file, those not changing
+// the generated code.
+var lockFlags = map[string]bool{
+	"lock": true, "frozen": true, "update-lock": true, "check": true, "verbose": true, "incremental": true,
+	"config": true,
+}
+
+// newLockFile fingerprints the schema parts and the tables generated from
+// them.
+func newLockFile(parts []schemaPart, ddls []*sqlparser.DDL) *lockFile {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part.content)
+	}
+	l := &lockFile{
+		InputSHA256: hex.EncodeToString(h.Sum(nil)),
+		Version:     dalgenVersion(),
+		Flags:       []string{},
+		Tables:      make(map[string]string),
+	}
+	flag.Visit(func(f *flag.Flag) {
+		if !lockFlags[f.Name] {
+			l.Flags = append(l.Flags, "-"+f.Name+"="+f.Value.String())
+		}
+	})
+	sort.Strings(l.Flags)
+	for _, ddl := range ddls {
+		sum := sha256.Sum256([]byte(tableDefinition(ddl)))
+		l.Tables[ddl.NewName.Name.String()] = hex.EncodeToString(sum[:])
+	}
+	return l
+}
+
+// readLockFile reads the -lock file, nil if there is none yet.
+func readLockFile(fp string) (*lockFile, error) {
+	data, err := ioutil.ReadFile(fp)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	l := &lockFile{}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("%s: %v", fp, err)
+	}
+	return l, nil
+}
+
+func (l *lockFile) write(fp string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fp, append(data, '\n'), 0644)
+}
+
+// checkLock compares the current run with the -lock file of the last one.
+// Different flags or dalgen builds are warned about; a changed schema
+// fails under -frozen, unless -update-lock, naming the tables that
+// changed. It reports whether the lock file is to be rewritten.
+func checkLock(last, next *lockFile) (bool, error) {
+	if last == nil {
+		return true, nil
+	}
+	if added, removed := stringsDiff(last.Flags, next.Flags); len(added)+len(removed) > 0 {
+		var changes []string
+		for _, f := range removed {
+			changes = append(changes, "was "+f)
+		}
+		for _, f := range added {
+			changes = append(changes, "now "+f)
+		}
+		fmt.Fprintf(os.Stderr, "warning: -lock: %s was written with other flags: %s\n", lockPath, strings.Join(changes, ", "))
+	}
+	if last.Version != next.Version {
+		fmt.Fprintf(os.Stderr, "warning: -lock: %s was written by dalgen %s, this is %s\n", lockPath, last.Version, next.Version)
+	}
+	if last.InputSHA256 == next.InputSHA256 || updateLock {
+		return !frozen || updateLock, nil
+	}
+	if !frozen {
+		return true, nil
+	}
+	var changes []string
+	for name, hash := range next.Tables {
+		switch old, ok := last.Tables[name]; {
+		case !ok:
+			changes = append(changes, "added "+name)
+		case old != hash:
+			changes = append(changes, "changed "+name)
+		}
+	}
+	for name := range last.Tables {
+		if _, ok := next.Tables[name]; !ok {
+			changes = append(changes, "removed "+name)
+		}
+	}
+	sort.Strings(changes)
+	msg := fmt.Sprintf("-frozen: the schema input changed since %s was written (sha256 %s, now %s)", lockPath, last.InputSHA256, next.InputSHA256)
+	if len(changes) > 0 {
+		msg += ": " + strings.Join(changes, ", ")
+	}
+	return false, fmt.Errorf("%s; rerun with -update-lock to regenerate from it", msg)
+}
+
+// stringsDiff returns the strings of b not in a, and of a not in b.
+func stringsDiff(a, b []string) (added, removed []string) {
+	in := func(list []string, s string) bool {
+		for _, x := range list {
+			if x == s {
+				return true
+			}
+		}
+		return false
+	}
+	for _, s := range b {
+		if !in(a, s) {
+			added = append(added, s)
+		}
+	}
+	for _, s := range a {
+		if !in(b, s) {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const lockSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (id)
+);
+CREATE TABLE orders (
+  id bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (id)
+);`
+
+func TestLock(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", lockSchema)
+	if err := run("-lock", "dalgen.lock", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	first := readFile(t, "dalgen.lock")
+	var l lockFile
+	if err := json.Unmarshal([]byte(first), &l); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.InputSHA256) != 64 || l.Version == "" || len(l.Tables) != 2 || l.Tables["users"] == "" || l.Tables["orders"] == "" {
+		t.Errorf("got lock file:\n%s", first)
+	}
+	if strings.Join(l.Flags, " ") != "-no-fmt=true" {
+		t.Errorf("got flags %q", l.Flags)
+	}
+
+	// a matching rerun, frozen or not, leaves the lock file alone
+	for _, args := range [][]string{{"-lock", "dalgen.lock"}, {"-lock", "dalgen.lock", "-frozen"}} {
+		_, stderr, err := capture(t, func() error { return run(append(args, "schema.sql")...) })
+		if err != nil {
+			t.Fatalf("%s: %v", args, err)
+		}
+		if stderr != "" {
+			t.Errorf("%s: got stderr:\n%s", args, stderr)
+		}
+		if got := readFile(t, "dalgen.lock"); got != first {
+			t.Errorf("%s: the lock file changed:\n%s", args, got)
+		}
+	}
+
+	// other flags are only warned about
+	_, stderr, err := capture(t, func() error { return run("-lock", "dalgen.lock", "-frozen", "-strict", "schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "was written with other flags: now -strict=true"; !strings.Contains(stderr, want) {
+		t.Errorf("no %s in:\n%s", want, stderr)
+	}
+}
+
+func TestLockFrozen(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", lockSchema)
+	if err := run("-lock", "dalgen.lock", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	first := readFile(t, "dalgen.lock")
+	users := readFile(t, modelPath("users"))
+
+	writeFile(t, "schema.sql", strings.Replace(lockSchema, "PRIMARY KEY (id)\n);\nCREATE TABLE orders", "name varchar(64),\n  PRIMARY KEY (id)\n);\nCREATE TABLE orders", 1)+
+		"\nCREATE TABLE tags (id bigint NOT NULL, PRIMARY KEY (id));")
+	err := run("-lock", "dalgen.lock", "-frozen", "schema.sql")
+	if err == nil || !strings.Contains(err.Error(), "-frozen: the schema input changed") ||
+		!strings.Contains(err.Error(), ": added tags, changed users; rerun with -update-lock") {
+		t.Fatalf("got %v", err)
+	}
+	if readFile(t, "dalgen.lock") != first || readFile(t, modelPath("users")) != users {
+		t.Error("-frozen regenerated from the changed schema")
+	}
+
+	if err := run("-lock", "dalgen.lock", "-frozen", "-update-lock", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if readFile(t, "dalgen.lock") == first || !strings.Contains(readFile(t, modelPath("users")), "Name") {
+		t.Error("-update-lock did not regenerate")
+	}
+	if err := run("-lock", "dalgen.lock", "-frozen", "schema.sql"); err != nil {
+		t.Errorf("frozen after -update-lock: %v", err)
+	}
+}
+
+func TestLockErrors(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", lockSchema)
+	for _, args := range [][]string{{"-frozen"}, {"-update-lock"}} {
+		if err := run(append(args, "schema.sql")...); err == nil || err.Error() != "-frozen and -update-lock need a -lock file" {
+			t.Errorf("%s: got %v", args, err)
+		}
+	}
+	writeFile(t, "dalgen.lock", "{")
+	if err := run("-lock", "dalgen.lock", "schema.sql"); err == nil || !strings.HasPrefix(err.Error(), "dalgen.lock: ") {
+		t.Errorf("got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// manifestFile is the -incremental manifest, written into the output
+// directory.
+const manifestFile = ".dalgen-manifest.json"
+
+// manifestEntry records the hash a table was last generated from and the
+// files generated for it, relative to the output directory.
+type manifestEntry struct {
+	Hash  string   `json:"hash"`
+	Files []string `json:"files"`
+}
+
+type manifest struct {
+	Tables map[string]manifestEntry `json:"tables"`
+}
+
+func manifestPath() string {
+	return path.Join(packageDir(""), manifestFile)
+}
+
+// readManifest reads the manifest of the last -incremental run, an empty
+// one if there was none.
+func readManifest() (*manifest, error) {
+	m := &manifest{Tables: make(map[string]manifestEntry)}
+	data, err := ioutil.ReadFile(manifestPath())
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("%s: %v", manifestPath(), err)
+	}
+	if m.Tables == nil {
+		m.Tables = make(map[string]manifestEntry)
+	}
+	return m, nil
+}
+
+// write replaces the manifest through a rename, so that a run killed
+// midway leaves the previous manifest. An unchanged manifest is left
+// alone.
+func (m *manifest) write() error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	fp := manifestPath()
+	if old, err := ioutil.ReadFile(fp); err == nil && bytes.Equal(old, data) {
+		return nil
+	}
+	if err := os.MkdirAll(path.Dir(fp), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := fp + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fp)
+}
+
+// unchanged reports whether a table was last generated from hash and all
+// its files are still there.
+func (m *manifest) unchanged(table, hash string) bool {
+	e, ok := m.Tables[table]
+	if !ok || e.Hash != hash {
+		return false
+	}
+	for _, f := range e.Files {
+		if _, err := os.Stat(path.Join(packageDir(""), f)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// lockManifest keeps two -incremental runs from generating into the same
+// directory at once. The returned function releases the lock.
+func lockManifest() (func(), error) {
+	lock := manifestPath() + ".lock"
+	if err := os.MkdirAll(path.Dir(lock), os.ModePerm); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		return nil, fmt.Errorf("%s exists, another dalgen run is generating into %s; remove it if that run died", lock, packageDir(""))
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	return func() { os.Remove(lock) }, nil
+}
+
+// generationOptions fingerprints what besides the table definitions the
+// generated code depends on: the flags set, the -type-map and -config
+// files and the dalgen build.
+func generationOptions() (string, error) {
+	var opts []string
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name != "incremental" {
+			opts = append(opts, "-"+f.Name+"="+f.Value.String())
+		}
+	})
+	sort.Strings(opts)
+	if typeMapFile != "" {
+		data, err := ioutil.ReadFile(typeMapFile)
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(data)
+		opts = append(opts, "type map "+hex.EncodeToString(sum[:]))
+	}
+	if configFile != "" {
+		data, err := ioutil.ReadFile(configFile)
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(data)
+		opts = append(opts, "config "+hex.EncodeToString(sum[:]))
+	}
+	if version := dalgenVersion(); version != "" {
+		opts = append(opts, "dalgen "+version)
+	}
+	return strings.Join(opts, "\n"), nil
+}
+
+// dalgenVersion returns the module version and checksum of the dalgen
+// build, "" when unknown.
+func dalgenVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return strings.TrimSpace(info.Main.Version + " " + info.Main.Sum)
+	}
+	return ""
+}
+
+// tableHash hashes the definition of a table along with those of the
+// tables its associations are generated from, and the options.
+func tableHash(ddls []*sqlparser.DDL, ddl *sqlparser.DDL, options string) string {
+	defs := []string{options, tableDefinition(ddl)}
+	if associationsFlag {
+		name := ddl.NewName.Name.String()
+		for _, other := range ddls {
+			if other == ddl {
+				continue
+			}
+			for _, fk := range tableForeignKeys[other] {
+				if fk.RefTable == name {
+					defs = append(defs, tableDefinition(other))
+					break
+				}
+			}
+			for _, fk := range tableForeignKeys[ddl] {
+				if fk.RefTable == other.NewName.Name.String() {
+					defs = append(defs, tableDefinition(other))
+					break
+				}
+			}
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(defs, "\n\n")))
+	return hex.EncodeToString(sum[:])
+}
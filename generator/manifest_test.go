@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const manifestSchema = `CREATE TABLE users (id bigint NOT NULL, PRIMARY KEY (id));
+CREATE TABLE posts (id bigint NOT NULL, PRIMARY KEY (id));`
+
+// modTimes backdates the files of model and returns their times.
+func modTimes(t *testing.T) map[string]time.Time {
+	t.Helper()
+	files, err := filepath.Glob("model/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, "model/"+manifestFile)
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	times := make(map[string]time.Time)
+	for _, f := range files {
+		if err := os.Chtimes(f, old, old); err != nil {
+			t.Fatal(err)
+		}
+		times[f] = old
+	}
+	return times
+}
+
+// written returns the base names of the files a run printed.
+func written(stdout string) []string {
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if line != "" {
+			files = append(files, filepath.Base(line))
+		}
+	}
+	return files
+}
+
+func TestIncremental(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", manifestSchema)
+	stdout, _, err := capture(t, func() error { return run("-incremental", "-dal", "schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(written(stdout), " "), "posts.go posts_dal.go users.go users_dal.go"; got != want {
+		t.Errorf("first run wrote %s, want %s", got, want)
+	}
+	first := readFile(t, "model/"+manifestFile)
+
+	// unchanged, nothing is written, the manifest included
+	times := modTimes(t)
+	stdout, stderr, err := capture(t, func() error { return run("-incremental", "-dal", "schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout != "" {
+		t.Errorf("second run wrote\n%s", stdout)
+	}
+	if want := "2 of 2 tables unchanged since the last run\n"; stderr != want {
+		t.Errorf("got %q, want %q", stderr, want)
+	}
+	if got := readFile(t, "model/"+manifestFile); got != first {
+		t.Errorf("manifest changed from\n%s\nto\n%s", first, got)
+	}
+	for f, mtime := range times {
+		if fi, err := os.Stat(f); err != nil || !fi.ModTime().Equal(mtime) {
+			t.Errorf("%s rewritten", f)
+		}
+	}
+
+	// one table changed, only its files are regenerated
+	writeFile(t, "schema.sql", strings.Replace(manifestSchema, "posts (id bigint NOT NULL,", "posts (id bigint NOT NULL, title text,", 1))
+	stdout, stderr, err = capture(t, func() error { return run("-incremental", "-dal", "schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(written(stdout), " "), "posts.go posts_dal.go"; got != want {
+		t.Errorf("third run wrote %s, want %s", got, want)
+	}
+	if want := "1 of 2 tables unchanged since the last run\n"; stderr != want {
+		t.Errorf("got %q, want %q", stderr, want)
+	}
+	if posts := readFile(t, modelPath("posts")); !strings.Contains(posts, "Title") {
+		t.Errorf("posts not regenerated:\n%s", posts)
+	}
+	if got := readFile(t, "model/"+manifestFile); got == first {
+		t.Error("manifest not updated")
+	}
+
+	// other options regenerate every table
+	stdout, _, err = capture(t, func() error { return run("-incremental", "-dal", "-no-column-maps", "schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(written(stdout), " "), "posts.go posts_dal.go users.go users_dal.go"; got != want {
+		t.Errorf("run with new flags wrote %s, want %s", got, want)
+	}
+}
+
+func TestIncrementalDeletedFile(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", manifestSchema)
+	if _, _, err := capture(t, func() error { return run("-incremental", "schema.sql") }); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(modelPath("users")); err != nil {
+		t.Fatal(err)
+	}
+	stdout, _, err := capture(t, func() error { return run("-incremental", "schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(written(stdout), " "); got != "users.go" {
+		t.Errorf("wrote %s, want users.go", got)
+	}
+}
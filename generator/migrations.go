@@ -0,0 +1,96 @@
+package generator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationVersion is the numeric prefix of a migration file name, such as
+// 0002 in 0002_add_index.up.sql or a goose timestamp.
+var migrationVersion = regexp.MustCompile(`^(\d+)`)
+
+// gooseAnnotation matches the goose section markers.
+var gooseAnnotation = regexp.MustCompile(`(?m)^\s*--\s*\+goose\s+(Up|Down)\b`)
+
+type migration struct {
+	file    string
+	version uint64
+}
+
+// readMigrations concatenates the up migrations of a -migrations
+// directory in version order, for the DDL statements to be replayed into
+// the final schema: the golang-migrate .up.sql files, and the Up sections
+// of the goose annotated .sql files. Down migrations are left out.
+func readMigrations(dir string) ([]byte, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("-migrations: %v", err)
+	}
+	var migrations []migration
+	versions := make(map[uint64]string)
+	for _, fi := range infos {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasSuffix(name, ".sql") || strings.HasSuffix(name, ".down.sql") {
+			continue
+		}
+		m := migrationVersion.FindString(name)
+		if m == "" {
+			fmt.Fprintf(os.Stderr, "warning: -migrations: %s has no version prefix, skipping it\n", name)
+			continue
+		}
+		version, err := strconv.ParseUint(m, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("-migrations: %s: %v", name, err)
+		}
+		if other, ok := versions[version]; ok {
+			return nil, fmt.Errorf("-migrations: %s and %s have the same version %d", other, name, version)
+		}
+		versions[version] = name
+		migrations = append(migrations, migration{filepath.Join(dir, name), version})
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("-migrations: no migration in %s", dir)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	var all []byte
+	inputSpans = nil
+	for _, m := range migrations {
+		content, err := readInput(m.file)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasSuffix(m.file, ".up.sql") {
+			if !gooseAnnotation.Match(content) {
+				fmt.Fprintf(os.Stderr, "warning: -migrations: %s is neither an .up.sql file nor goose annotated, skipping it\n", m.file)
+				continue
+			}
+			content = []byte(gooseUp(string(content)))
+		}
+		all = appendInput(all, m.file, content)
+	}
+	return all, nil
+}
+
+// gooseUp blanks the lines of a goose migration outside of its Up
+// sections, keeping the line numbers of those for the error positions.
+func gooseUp(content string) string {
+	lines := strings.Split(content, "\n")
+	up := false
+	for i, line := range lines {
+		if m := gooseAnnotation.FindStringSubmatch(line); m != nil {
+			up = m[1] == "Up"
+			lines[i] = ""
+			continue
+		}
+		if !up {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
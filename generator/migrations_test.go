@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMigrations replays the three migrations of testdata/migrations/migrate,
+// whose 10_ one sorts before the others by name, not by version.
+func TestMigrations(t *testing.T) {
+	dir := testdataPath(t, "migrations/migrate")
+	chdir(t)
+	if err := run("-strict", "-migrations", dir); err != nil {
+		t.Fatal(err)
+	}
+	// users was renamed and sessions dropped after the first migration
+	checkGenerated(t, map[string]bool{"users": false, "sessions": false, "accounts": true, "audit": true})
+	accounts := structFields(gofmt(t, readFile(t, modelPath("accounts"))))
+	if got, want := strings.Join(accounts, " "), "ID Name Email"; got != want {
+		t.Errorf("accounts has fields %s, want %s", got, want)
+	}
+	code := gofmt(t, readFile(t, modelPath("accounts")))
+	for _, want := range []string{"gorm:\"Column:name;size:128\"", "gorm:\"Column:email;size:255;unique\""} {
+		if !strings.Contains(code, want) {
+			t.Errorf("no %s in:\n%s", want, code)
+		}
+	}
+}
+
+func TestGooseMigrations(t *testing.T) {
+	dir := testdataPath(t, "migrations/goose")
+	chdir(t)
+	if err := run("-strict", "-migrations", dir); err != nil {
+		t.Fatal(err)
+	}
+	// the Down sections are left out
+	users := structFields(gofmt(t, readFile(t, modelPath("users"))))
+	if got, want := strings.Join(users, " "), "ID Email"; got != want {
+		t.Errorf("users has fields %s, want %s", got, want)
+	}
+}
+
+func TestMigrationsErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"same version", map[string]string{"1_a.up.sql": "", "01_b.up.sql": ""}, "have the same version 1"},
+		{"down only", map[string]string{"1_a.down.sql": ""}, "-migrations: no migration in migrations"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t)
+			for name, content := range tt.files {
+				writeFile(t, "migrations/"+name, content)
+			}
+			err := run("-migrations", "migrations")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build mysql
+// +build mysql
+
+package generator
+
+// The MySQL driver -dsn connects with, left out of the default build so
+// that dalgen depends on the sql parser only.
+import _ "github.com/go-sql-driver/mysql"
@@ -0,0 +1,175 @@
+package generator
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// commonInitialisms are the words written in upper case in Go identifiers,
+// from the golint list; -initialisms adds to them.
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "LHS": true,
+	"QPS": true, "RAM": true, "RHS": true, "RPC": true, "SLA": true,
+	"SMTP": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true,
+	"TTL": true, "UDP": true, "UI": true, "UID": true, "UUID": true,
+	"URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true,
+	"XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// addInitialisms adds the comma separated -initialisms words to
+// commonInitialisms.
+func addInitialisms(s string) {
+	for _, w := range strings.Split(s, ",") {
+		if w = strings.TrimSpace(w); w != "" {
+			commonInitialisms[strings.ToUpper(w)] = true
+		}
+	}
+}
+
+// titleWord upper cases the first letter of every word of s, as the
+// deprecated strings.Title did.
+func titleWord(s string) string {
+	prev := ' '
+	var b strings.Builder
+	for _, r := range s {
+		if isSeparator(prev) {
+			b.WriteRune(unicode.ToTitle(r))
+		} else {
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
+
+// isSeparator reports whether r separates words for titleWord.
+func isSeparator(r rune) bool {
+	if r <= 0x7F {
+		switch {
+		case '0' <= r && r <= '9', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', r == '_':
+			return false
+		}
+		return true
+	}
+	if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return false
+	}
+	return unicode.IsSpace(r)
+}
+
+// camelWord converts an underscore separated piece of a name, writing the
+// initialisms, and their plurals, in upper case unless -legacy-naming. The
+// humps of a camel cased piece are converted one by one, so userId becomes
+// UserID, and an upper case piece as a lower case one, USER_ID UserID.
+func camelWord(s string) string {
+	if legacyNaming {
+		return titleWord(s)
+	}
+	var b strings.Builder
+	start := 0
+	runes := []rune(s)
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && !(unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i])) {
+			continue
+		}
+		hump := string(runes[start:i])
+		upper := strings.ToUpper(hump)
+		switch n := len(upper) - 1; {
+		case commonInitialisms[upper]:
+			b.WriteString(upper)
+		case n > 0 && upper[n] == 'S' && commonInitialisms[upper[:n]]:
+			b.WriteString(upper[:n] + "s")
+		case upper == hump:
+			// an upper case word, as in USER_NAME
+			b.WriteString(titleWord(strings.ToLower(hump)))
+		default:
+			b.WriteString(titleWord(hump))
+		}
+		start = i
+	}
+	return b.String()
+}
+
+// notIdentRune reports whether r separates the words of a name, being
+// invalid in Go identifiers or an underscore.
+func notIdentRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// ToCamelFirstUpper converts a snake_case name to an exported Go
+// identifier: user_id becomes UserID and api_url APIURL. Spaces, dashes and
+// the other runes invalid in identifiers separate words like underscores.
+func ToCamelFirstUpper(str string) string {
+	pieces := strings.FieldsFunc(str, notIdentRune)
+	newPieces := make([]string, 0, len(pieces))
+	for _, piece := range pieces {
+		newPieces = append(newPieces, camelWord(piece))
+	}
+	return strings.Join(newPieces, "")
+}
+
+// digitNames spell the leading digits of identifiers under -spell-digits.
+var digitNames = [...]string{"Zero", "One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine"}
+
+// exportedIdent makes a camel cased name a valid exported identifier: a
+// leading digit is prefixed with N, or spelled under -spell-digits, so
+// 2fa_tokens becomes N2faTokens or TwoFaTokens, and a name without a
+// leading upper case letter, such as one in CJK, is prefixed with X.
+func exportedIdent(s string) string {
+	if s == "" {
+		return "X"
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	switch {
+	case unicode.IsDigit(r) && spellDigits:
+		var b strings.Builder
+		i := 0
+		for ; i < len(s) && '0' <= s[i] && s[i] <= '9'; i++ {
+			b.WriteString(digitNames[s[i]-'0'])
+		}
+		if i < len(s) {
+			rest, _ := utf8.DecodeRuneInString(s[i:])
+			b.WriteRune(unicode.ToUpper(rest))
+			i += utf8.RuneLen(rest)
+		}
+		b.WriteString(s[i:])
+		return b.String()
+	case unicode.IsDigit(r):
+		return "N" + s
+	case !unicode.IsUpper(r):
+		return "X" + s
+	}
+	return s
+}
+
+// lowerFirst converts an exported identifier to an unexported one, lower
+// casing a leading initialism as a whole: ID becomes id and APIKey apiKey.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	if legacyNaming {
+		return strings.ToLower(s[:1]) + s[1:]
+	}
+	rs := []rune(s)
+	n := 0
+	for n < len(rs) && unicode.IsUpper(rs[n]) {
+		n++
+	}
+	if n > 1 && n < len(rs) && unicode.IsLower(rs[n]) && !plural(rs[n:]) {
+		// the last upper case letter starts the next word
+		n--
+	}
+	if n == 0 {
+		return s
+	}
+	return strings.ToLower(string(rs[:n])) + string(rs[n:])
+}
+
+// plural reports whether rs, following an initialism, is its plural s.
+func plural(rs []rune) bool {
+	return rs[0] == 's' && (len(rs) == 1 || unicode.IsUpper(rs[1]))
+}
@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToCamelFirstUpperInitialisms(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"id", "ID"},
+		{"user_id", "UserID"},
+		{"user_ids", "UserIDs"},
+		{"url", "URL"},
+		{"avatar_url", "AvatarURL"},
+		{"api_url", "APIURL"},
+		{"http_status", "HTTPStatus"},
+		{"uuid", "UUID"},
+		{"order_uuid", "OrderUUID"},
+		{"Id", "ID"},
+		{"USER_ID", "UserID"},
+		{"ORDER_2FA", "Order2fa"},
+		{"HTTPS_URL", "HTTPSURL"},
+		{"userId", "UserID"},
+		{"avatarUrl", "AvatarURL"},
+		{"createdAt", "CreatedAt"},
+		{"HTTPStatus", "HTTPStatus"},
+		{"httpsUrl", "HTTPSURL"},
+		{"ids", "IDs"},
+		{"identity", "Identity"},
+		{"urls_json", "URLsJSON"},
+		{"sku", "Sku"},
+	} {
+		reset()
+		if got := ToCamelFirstUpper(tt.in); got != tt.want {
+			t.Errorf("ToCamelFirstUpper(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestToCamelFirstUpperFlags(t *testing.T) {
+	reset()
+	legacyNaming = true
+	if got := ToCamelFirstUpper("user_id"); got != "UserId" {
+		t.Errorf("-legacy-naming: got %q, want UserId", got)
+	}
+	reset()
+	addInitialisms("sku")
+	defer delete(commonInitialisms, "SKU")
+	if got := ToCamelFirstUpper("product_sku"); got != "ProductSKU" {
+		t.Errorf("-initialisms sku: got %q, want ProductSKU", got)
+	}
+}
+
+const oddNamesSchema = "CREATE TABLE `2fa_tokens` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `3d_secure_status` varchar(16) NOT NULL,\n" +
+	"  `card-number` varchar(32) NOT NULL,\n" +
+	"  `card_number` varchar(32) NOT NULL,\n" +
+	"  `display name` varchar(64) NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");"
+
+const oddNamesTest = `package model
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestOddNames(t *testing.T) {
+	if got := (N2faTokens{}).TableName(); got != "2fa_tokens" {
+		t.Errorf("TableName() = %s, want 2fa_tokens", got)
+	}
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&N2faTokens{}); err != nil {
+		t.Fatal(err)
+	}
+	in := N2faTokens{N3dSecureStatus: "ok", CardNumber: "4111", CardNumber2: "5500", DisplayName: "Ann"}
+	if err := db.Create(&in).Error; err != nil {
+		t.Fatal(err)
+	}
+	var dashed, spaced string
+	if err := db.Raw("SELECT ` + "`card-number`, `display name`" + ` FROM ` + "`2fa_tokens`" + `").Row().Scan(&dashed, &spaced); err != nil {
+		t.Fatal(err)
+	}
+	if dashed != "4111" || spaced != "Ann" {
+		t.Errorf("got %s and %s", dashed, spaced)
+	}
+	var got N2faTokens
+	if err := db.First(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got != in {
+		t.Errorf("got %+v, want %+v", got, in)
+	}
+}
+`
+
+func TestOddNames(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", oddNamesSchema)
+	_, stderr, err := capture(t, func() error { return run("schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "warning: table 2fa_tokens: columns card-number and card_number both camel case to CardNumber, using CardNumber2 for card_number\n"; stderr != want {
+		t.Errorf("got warnings\n%s\nwant\n%s", stderr, want)
+	}
+	tokens := gofmt(t, readFile(t, modelPath("2fa_tokens")))
+	for _, want := range []string{
+		"type N2faTokens struct {",
+		"N3dSecureStatus string `gorm:\"Column:3d_secure_status;size:16\" json:\"3d_secure_status\"`",
+		"CardNumber      string `gorm:\"Column:card-number;size:32\" json:\"card-number\"`",
+		"CardNumber2     string `gorm:\"Column:card_number;size:32\" json:\"card_number\"`",
+		"DisplayName     string `gorm:\"Column:display name;size:64\" json:\"display name\"`",
+	} {
+		if !strings.Contains(tokens, want) {
+			t.Errorf("no %s in:\n%s", want, tokens)
+		}
+	}
+	writeFile(t, "model/names_test.go", oddNamesTest)
+	goTest(t, "./model")
+
+	tokens = generate(t, oddNamesSchema, "2fa_tokens", "-spell-digits")
+	for _, want := range []string{"type TwoFaTokens struct {", "ThreeDSecureStatus string "} {
+		if !strings.Contains(tokens, want) {
+			t.Errorf("no %s with -spell-digits in:\n%s", want, tokens)
+		}
+	}
+}
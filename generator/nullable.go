@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// pointerColumns holds the columns -nullable-pointers maps to a pointer, so
+// that nil reads and writes NULL.
+var pointerColumns = make(map[*sqlparser.ColumnDefinition]bool)
+
+// nullableGoType returns the Go type of a column of goType that may be
+// NULL: goType itself, or with -nullable-pointers a pointer to it unless
+// nil already stands for NULL, as for slices and pointers.
+func nullableGoType(goType string) string {
+	if !nullablePointers {
+		return goType
+	}
+	switch {
+	case strings.HasPrefix(goType, "*"), strings.HasPrefix(goType, "[]"), strings.HasPrefix(goType, "map["),
+		goType == "json.RawMessage", goType == "interface{}", goType == "IP", goType == "MAC":
+		return goType
+	}
+	return "*" + goType
+}
+
+// applyNullablePointers records in pointerColumns the columns of ddls that
+// may be NULL and are not part of the primary key, when -nullable-pointers
+// is set. The -type-map overrides keep their type.
+func applyNullablePointers(ddls []*sqlparser.DDL) {
+	if !nullablePointers {
+		return
+	}
+	for _, ddl := range ddls {
+		pk := make(map[string]bool)
+		for _, c := range primaryKeyColumns(ddl) {
+			pk[c.Name] = true
+		}
+		for _, c := range ddl.TableSpec.Columns {
+			if bool(c.Type.NotNull) || pk[c.Name.String()] || columnOverrides[c].Type != "" {
+				continue
+			}
+			// sets are slices too
+			if t := safeGoType(c); t != "" && nullableGoType(t) != t && nullableGoType(underlyingType(c)) != underlyingType(c) {
+				pointerColumns[c] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// openAPIType returns the JSON Schema type and format of the value
+// encoding/json writes for a column, both empty when the Go type has no
+// JSON Schema equivalent. Go ints are 64-bit, and dates are time.Time,
+// written in RFC 3339 like the timestamps.
+func openAPIType(c *sqlparser.ColumnDefinition) (string, string) {
+	switch strings.TrimPrefix(underlyingType(c), "*") {
+	case "int64", "uint64", "int":
+		return "integer", "int64"
+	case "Duration":
+		// time.Duration nanoseconds
+		return "integer", "int64"
+	case "float64":
+		return "number", "double"
+	case "float32":
+		return "number", "float"
+	case "bool":
+		return "boolean", ""
+	case "string", "IP", "IPNet", "MAC":
+		return "string", ""
+	case "uuid.UUID":
+		return "string", "uuid"
+	case "[]byte":
+		return "string", "byte"
+	case "[]string", "pq.StringArray", "pq.Int64Array", "pq.Float64Array", "pq.BoolArray", "pq.ByteaArray":
+		return "array", ""
+	case "time.Time":
+		return "string", "date-time"
+	}
+	return "", ""
+}
+
+// openAPIItems returns the JSON Schema type and format of the items of an
+// array column.
+func openAPIItems(c *sqlparser.ColumnDefinition) (string, string) {
+	switch GoType(c) {
+	case "pq.Int64Array":
+		return "integer", "int64"
+	case "pq.Float64Array":
+		return "number", "double"
+	case "pq.BoolArray":
+		return "boolean", ""
+	case "pq.ByteaArray":
+		return "string", "byte"
+	}
+	return "string", ""
+}
+
+// openAPINullable reports whether encoding/json may write null for a
+// column: nil pointers, slices, maps and interfaces are null, while the
+// zero values of the other types, those of nullable columns included, are
+// written as they are, e.g. "" or 0.
+func openAPINullable(c *sqlparser.ColumnDefinition) bool {
+	t := GoType(c)
+	if strings.HasPrefix(t, "*") || strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[") {
+		return true
+	}
+	switch t {
+	case "json.RawMessage", "interface{}":
+		return true
+	}
+	return strings.HasPrefix(t, "pq.") || underlyingType(c) == "[]string"
+}
+
+// genOpenAPI renders an OpenAPI components/schemas section with a schema per
+// table. Properties are named after the json tags of the models, NOT NULL
+// columns are required and those encoding/json may write as null nullable.
+func genOpenAPI(ddls []*sqlparser.DDL) string {
+	var b strings.Builder
+	b.WriteString("components:\n  schemas:\n")
+	for _, ddl := range ddls {
+		table := ddl.NewName.Name.String()
+		fmt.Fprintf(&b, "    %s:\n      type: object\n", modelName(table))
+		var required []string
+		var props strings.Builder
+		rules := checkRules(ddl)
+		for _, c := range ddl.TableSpec.Columns {
+			name := c.Name.String()
+			if jsonExcluded(table, name) {
+				continue
+			}
+			name = jsonName(name)
+			fmt.Fprintf(&props, "        %s:\n", name)
+			typ, format := openAPIType(c)
+			if typ != "" {
+				fmt.Fprintf(&props, "          type: %s\n", typ)
+			}
+			if format != "" {
+				fmt.Fprintf(&props, "          format: %s\n", format)
+			}
+			if typ == "array" {
+				items, itemsFormat := openAPIItems(c)
+				fmt.Fprintf(&props, "          items:\n            type: %s\n", items)
+				if itemsFormat != "" {
+					fmt.Fprintf(&props, "            format: %s\n", itemsFormat)
+				}
+			}
+			if isEnumColumn(c) && columnOverrides[c].Type == "" {
+				indent := "          "
+				if typ == "array" {
+					indent += "  "
+				}
+				props.WriteString(indent + "enum:\n")
+				for _, v := range enumValues(c) {
+					fmt.Fprintf(&props, "%s  - %s\n", indent, strconv.Quote(v))
+				}
+			}
+			if rule := rules[c]; rule != nil {
+				writeOpenAPIRule(&props, rule, typ, isEnumColumn(c))
+			}
+			if comment := columnComment(c); comment != "" {
+				fmt.Fprintf(&props, "          description: %s\n", strconv.Quote(comment))
+			}
+			if c.Type.NotNull {
+				required = append(required, name)
+			}
+			if openAPINullable(c) {
+				props.WriteString("          nullable: true\n")
+			}
+		}
+		if len(required) > 0 {
+			b.WriteString("      required:\n")
+			for _, name := range required {
+				fmt.Fprintf(&b, "        - %s\n", name)
+			}
+		}
+		if props.Len() > 0 {
+			b.WriteString("      properties:\n")
+			b.WriteString(props.String())
+		}
+	}
+	return b.String()
+}
+
+// writeOpenAPIRule renders the bounds and allowed values CHECK constraints
+// impose on a property of type typ. Bounds only apply to numbers; enum
+// columns already list their values.
+func writeOpenAPIRule(b *strings.Builder, rule *checkRule, typ string, enum bool) {
+	numeric := typ == "integer" || typ == "number"
+	if numeric && rule.Min != "" {
+		fmt.Fprintf(b, "          minimum: %s\n", rule.Min)
+		if rule.ExclusiveMin {
+			b.WriteString("          exclusiveMinimum: true\n")
+		}
+	}
+	if numeric && rule.Max != "" {
+		fmt.Fprintf(b, "          maximum: %s\n", rule.Max)
+		if rule.ExclusiveMax {
+			b.WriteString("          exclusiveMaximum: true\n")
+		}
+	}
+	if len(rule.OneOf) > 0 && !enum {
+		b.WriteString("          enum:\n")
+		for _, v := range rule.OneOf {
+			if !numeric {
+				v = strconv.Quote(v)
+			}
+			fmt.Fprintf(b, "            - %s\n", v)
+		}
+	}
+}
+
+func writeOpenAPI(file string, ddls []*sqlparser.DDL) error {
+	content, err := runHooks(file, genOpenAPI(ddls))
+	if err != nil {
+		return err
+	}
+	if checkMode {
+		checkFile(file, []byte(content))
+		return nil
+	}
+	fmt.Println(file)
+	return ioutil.WriteFile(file, []byte(content), 0644)
+}
@@ -0,0 +1,220 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const openAPISchema = "CREATE TABLE `users` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `name` varchar(64) NOT NULL DEFAULT '',\n" +
+	"  `age` int DEFAULT NULL,\n" +
+	"  `score` double NOT NULL DEFAULT 0,\n" +
+	"  `active` boolean NOT NULL DEFAULT false,\n" +
+	"  `born_on` date DEFAULT NULL,\n" +
+	"  `created_at` datetime NOT NULL,\n" +
+	"  `avatar` blob,\n" +
+	"  `state` enum('new','done') NOT NULL DEFAULT 'new',\n" +
+	"  `tags` set('a','b'),\n" +
+	"  `meta` json,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");"
+
+const openAPIUsers = `components:
+  schemas:
+    Users:
+      type: object
+      required:
+        - id
+        - name
+        - score
+        - active
+        - created_at
+        - state
+      properties:
+        id:
+          type: integer
+          format: int64
+        name:
+          type: string
+        age:
+          type: integer
+          format: int64
+        score:
+          type: number
+          format: double
+        active:
+          type: boolean
+        born_on:
+          type: string
+          format: date-time
+        created_at:
+          type: string
+          format: date-time
+        avatar:
+          type: string
+          format: byte
+          nullable: true
+        state:
+          type: string
+          enum:
+            - "new"
+            - "done"
+        tags:
+          type: array
+          items:
+            type: string
+            enum:
+              - "a"
+              - "b"
+          nullable: true
+        meta:
+          nullable: true
+`
+
+// openAPITest checks the JSON encoding/json writes for a zero and a filled
+// model against the schema of openapi.yaml.
+const openAPITest = `package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type property struct {
+	Type     string
+	Format   string
+	Nullable bool
+}
+
+func checkJSON(t *testing.T, props map[string]property, m Users) {
+	t.Helper()
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(b, &values); err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != len(props) {
+		t.Errorf("%s has %d fields, the schema %d properties", b, len(values), len(props))
+	}
+	for name, p := range props {
+		v, ok := values[name]
+		if !ok {
+			t.Errorf("%s: not in %s", name, b)
+			continue
+		}
+		if v == nil {
+			if !p.Nullable {
+				t.Errorf("%s: null, but not nullable", name)
+			}
+			continue
+		}
+		switch p.Type {
+		case "integer":
+			if f, ok := v.(float64); !ok || f != float64(int64(f)) {
+				t.Errorf("%s: %v is not an integer", name, v)
+			}
+		case "number":
+			if _, ok := v.(float64); !ok {
+				t.Errorf("%s: %v is not a number", name, v)
+			}
+		case "boolean":
+			if _, ok := v.(bool); !ok {
+				t.Errorf("%s: %v is not a boolean", name, v)
+			}
+		case "array":
+			if _, ok := v.([]interface{}); !ok {
+				t.Errorf("%s: %v is not an array", name, v)
+			}
+		case "string":
+			s, ok := v.(string)
+			if !ok {
+				t.Errorf("%s: %v is not a string", name, v)
+				continue
+			}
+			switch p.Format {
+			case "date-time":
+				if _, err := time.Parse(time.RFC3339, s); err != nil {
+					t.Errorf("%s: %v", name, err)
+				}
+			case "byte":
+				if _, err := base64.StdEncoding.DecodeString(s); err != nil {
+					t.Errorf("%s: %v", name, err)
+				}
+			}
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	b, err := ioutil.ReadFile("../openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]property
+			}
+		}
+	}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	props := doc.Components.Schemas["Users"].Properties
+	checkJSON(t, props, Users{})
+	checkJSON(t, props, Users{
+		ID:        1,
+		Name:      "ann",
+		Age:       30,
+		Score:     1.5,
+		Active:    true,
+		BornOn:    time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC),
+		CreatedAt: time.Now(),
+		Avatar:    []byte{1, 2},
+		State:     UsersStateNew,
+		Tags:      UsersTags{"a"},
+		Meta:      json.RawMessage(` + "`" + `{"k":1}` + "`" + `),
+	})
+}
+`
+
+func TestOpenAPIOut(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", openAPISchema)
+	if err := run("-openapi-out", "openapi.yaml", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, "openapi.yaml"); got != openAPIUsers {
+		t.Errorf("got:\n%s\nwant:\n%s", got, openAPIUsers)
+	}
+	writeFile(t, "model/openapi_test.go", openAPITest)
+	goTest(t, "./model")
+}
+
+func TestOpenAPINullablePointers(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", openAPISchema)
+	if err := run("-openapi-out", "openapi.yaml", "-nullable-pointers", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	got := readFile(t, "openapi.yaml")
+	for _, want := range []string{
+		"        age:\n          type: integer\n          format: int64\n          nullable: true\n",
+		"        born_on:\n          type: string\n          format: date-time\n          nullable: true\n",
+		// NOT NULL columns keep their values
+		"        name:\n          type: string\n        age:",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("no %q in:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// declaredColumns returns the column names of a CREATE TABLE statement in
+// the order they appear in its text, lower cased.
+func declaredColumns(stmt string) []string {
+	open, end, ok := createTableBody(stmt)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, def := range splitTopLevel(stmt[open+1 : end]) {
+		def = strings.TrimSpace(def)
+		name, _ := sqlWord(def)
+		name = strings.ToLower(name)
+		quoted := strings.HasPrefix(def, "`") || strings.HasPrefix(def, `"`)
+		switch name {
+		case "primary", "unique", "key", "index", "fulltext", "spatial", "constraint", "check", "foreign":
+			if !quoted {
+				continue
+			}
+		case "":
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// retainColumnOrder sorts the columns of a freshly parsed table into the
+// order they are declared in, so that generated fields always follow the
+// DDL. Columns missing from order keep their relative order at the end.
+func retainColumnOrder(ddl *sqlparser.DDL, order []string) {
+	pos := make(map[string]int, len(order))
+	for i, name := range order {
+		if _, ok := pos[name]; !ok {
+			pos[name] = i
+		}
+	}
+	at := func(c *sqlparser.ColumnDefinition) int {
+		if i, ok := pos[c.Name.Lowered()]; ok {
+			return i
+		}
+		return len(order)
+	}
+	cols := ddl.TableSpec.Columns
+	sort.SliceStable(cols, func(i, j int) bool {
+		return at(cols[i]) < at(cols[j])
+	})
+}
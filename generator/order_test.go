@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// structFields returns the field names of the first struct in src, in
+// order.
+func structFields(src string) []string {
+	var fields []string
+	in := false
+	for _, line := range strings.Split(src, "\n") {
+		switch {
+		case strings.HasPrefix(line, "type ") && strings.HasSuffix(line, " struct {"):
+			in = true
+		case in && line == "}":
+			return fields
+		case in:
+			if f := strings.Fields(line); len(f) > 1 && !strings.HasPrefix(f[0], "//") {
+				fields = append(fields, f[0])
+			}
+		}
+	}
+	return fields
+}
+
+func TestColumnOrder(t *testing.T) {
+	columns := []struct{ def, field string }{
+		{"`zeta` varchar(16) NOT NULL", "Zeta"},
+		{"`key` varchar(32) NOT NULL", "Key"},
+		{"`id` bigint NOT NULL AUTO_INCREMENT", "ID"},
+		{"`amount` decimal(10,2) NOT NULL", "Amount"},
+		{"`created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP", "CreatedAt"},
+		{"`primary` tinyint NOT NULL", "Primary"},
+		{"`alpha` int DEFAULT NULL", "Alpha"},
+		{"`payload` json", "Payload"},
+		{"`state` enum('new','done') NOT NULL", "State"},
+		{"`user_id` bigint NOT NULL", "UserID"},
+		{"`unique` varchar(8) NOT NULL", "Unique"},
+		{"`avatar` blob", "Avatar"},
+		{"`updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP", "UpdatedAt"},
+		{"`beta` double NOT NULL", "Beta"},
+		{"`tenant_id` bigint NOT NULL", "TenantID"},
+		{"`note` text", "Note"},
+	}
+	var defs, want []string
+	for _, c := range columns {
+		defs = append(defs, "  "+c.def)
+		want = append(want, c.field)
+	}
+	defs = append(defs,
+		"  PRIMARY KEY (`tenant_id`, `id`)",
+		"  UNIQUE KEY `uk_key` (`key`)",
+		"  KEY `idx_user` (`user_id`, `created_at`)",
+	)
+	schema := fmt.Sprintf("CREATE TABLE `ledger` (\n%s\n);", strings.Join(defs, ",\n"))
+	got := structFields(generate(t, schema, "ledger", "-no-column-maps"))
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got fields\n%v\nwant the DDL order\n%v", got, want)
+	}
+}
+
+func TestDeclaredColumns(t *testing.T) {
+	got := declaredColumns("CREATE TABLE t (\n" +
+		"  `B` int,\n" +
+		"  a varchar(255) COMMENT 'x, y',\n" +
+		"  `key` int,\n" +
+		"  c decimal(10,2),\n" +
+		"  PRIMARY KEY (a),\n" +
+		"  KEY k (c),\n" +
+		"  CONSTRAINT ck CHECK (c > 0)\n" +
+		")")
+	if want := "b a key c"; strings.Join(got, " ") != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const otelTemplate = `
+package {{.Package}}
+
+import (
+	"context"
+{{- range std .Imports}}
+	"{{.}}"
+{{- end}}
+{{range thirdParty .Imports}}
+	"{{.}}"
+{{- end}}
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+type Traced{{.DAO}} struct {
+	next   {{.DAO}}Iface
+	tracer trace.Tracer
+}
+
+var _ {{.DAO}}Iface = (*Traced{{.DAO}})(nil)
+
+// NewTraced{{.DAO}} wraps next with a span per call. A nil tp defaults to
+// the global otel.GetTracerProvider().
+func NewTraced{{.DAO}}(next {{.DAO}}Iface, tp trace.TracerProvider) *Traced{{.DAO}} {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &Traced{{.DAO}}{next: next, tracer: tp.Tracer("dalgen/{{.Database}}")}
+}
+
+func (d *Traced{{.DAO}}) start(ctx context.Context, method string) (context.Context, trace.Span) {
+	return d.tracer.Start(ctx, "dal.{{.TableNameStr}}."+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", "{{.System}}"),
+		attribute.String("db.sql.table", "{{.TableNameStr}}"),
+	))
+}
+
+func (d *Traced{{.DAO}}) end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+{{range .Methods}}
+func (d *Traced{{$.DAO}}) {{.Name}}({{.Params}}) {{.Results}} {
+	ctx, span := d.start(ctx, "{{.Name}}")
+{{- if eq .Results "error"}}
+	err := d.next.{{.Name}}({{.Args}})
+	d.end(span, err)
+	return err
+{{- else}}
+	res, err := d.next.{{.Name}}({{.Args}})
+	d.end(span, err)
+	return res, err
+{{- end}}
+}
+{{end}}`
+
+func genOtel(pkg string, ddl *sqlparser.DDL) string {
+	params := struct {
+		dalParams
+		System string
+	}{
+		dalParams: newDALParams(pkg, ddl),
+		System:    dialect,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("otel").Funcs(importFuncs).Parse(otelTemplate)).Execute(&buf, params)
+	return buf.String()
+}
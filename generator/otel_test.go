@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const otelTest = `package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTraced(t *testing.T, tp trace.TracerProvider) *TracedUsersDAO {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Users{}); err != nil {
+		t.Fatal(err)
+	}
+	return NewTracedUsersDAO(NewUsersDAO(db), tp)
+}
+
+func attrs(s sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	m := make(map[attribute.Key]string)
+	for _, kv := range s.Attributes() {
+		m[kv.Key] = kv.Value.Emit()
+	}
+	return m
+}
+
+func TestTracedSpans(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	dao := newTraced(t, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	ctx := context.Background()
+	if err := dao.Create(ctx, &Users{Email: "a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dao.GetByID(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	_, err := dao.GetByID(ctx, 9)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("got %v, want gorm.ErrRecordNotFound through the decorator", err)
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+	for i, want := range []string{"dal.users.Create", "dal.users.GetByID", "dal.users.GetByID"} {
+		s := spans[i]
+		if s.Name() != want {
+			t.Errorf("span %d is %s, want %s", i, s.Name(), want)
+		}
+		if s.SpanKind() != trace.SpanKindClient {
+			t.Errorf("%s: kind %v, want client", s.Name(), s.SpanKind())
+		}
+		a := attrs(s)
+		if a["db.system"] != "mysql" || a["db.sql.table"] != "users" {
+			t.Errorf("%s: attributes %v", s.Name(), a)
+		}
+	}
+	for _, s := range spans[:2] {
+		if s.Status().Code != codes.Unset || len(s.Events()) != 0 {
+			t.Errorf("%s: status %v and events %v on success", s.Name(), s.Status(), s.Events())
+		}
+	}
+	failed := spans[2]
+	if failed.Status().Code != codes.Error || failed.Status().Description != err.Error() {
+		t.Errorf("status %+v, want the error", failed.Status())
+	}
+	if ev := failed.Events(); len(ev) != 1 || ev[0].Name != "exception" {
+		t.Errorf("events %+v, want the recorded error", ev)
+	}
+}
+
+func TestTracedDefaultProvider(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	dao := newTraced(t, nil)
+	if _, err := dao.List(context.Background(), 10, 0); err != nil {
+		t.Fatal(err)
+	}
+	if spans := sr.Ended(); len(spans) != 1 || spans[0].Name() != "dal.users.List" {
+		t.Errorf("got %v, want a dal.users.List span from the global provider", spans)
+	}
+}
+`
+
+func TestOtel(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", usersSchema)
+	if err := run("-gen-otel", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	otel := gofmt(t, readFile(t, "model/users_otel.go"))
+	if !strings.Contains(otel, "var _ UsersDAOIface = (*TracedUsersDAO)(nil)") {
+		t.Errorf("TracedUsersDAO is not asserted to implement the interface:\n%s", otel)
+	}
+	writeFile(t, "model/otel_test.go", otelTest)
+	goTest(t, "./model")
+}
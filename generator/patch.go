@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const patchTemplate = `
+package {{.Package}}
+
+{{.Imports}}
+// {{.TableName}}Patch holds a partial update of {{.TableName}}. A nil field is
+// left untouched; nullable columns use a double pointer so that a non-nil
+// pointer to a nil pointer sets the column to NULL.
+type {{.TableName}}Patch struct {
+{{- range .Fields}}
+	{{.Field}} {{if .Nullable}}**{{else}}*{{end}}{{.Type}}
+{{- end}}
+}
+
+func (p {{.TableName}}Patch) ApplyTo(m *{{.TableName}}) {
+{{- range .Fields}}
+	if p.{{.Field}} != nil {
+	{{- if .Nullable}}
+		if *p.{{.Field}} == nil {
+			var zero {{.Type}}
+			m.{{.Field}} = zero
+		} else {
+			m.{{.Field}} = **p.{{.Field}}
+		}
+	{{- else}}
+		m.{{.Field}} = *p.{{.Field}}
+	{{- end}}
+	}
+{{- end}}
+}
+
+func (p {{.TableName}}Patch) Updates() map[string]interface{} {
+	updates := make(map[string]interface{})
+{{- range .Fields}}
+	if p.{{.Field}} != nil {
+	{{- if .Nullable}}
+		if *p.{{.Field}} == nil {
+			updates["{{.Name}}"] = nil
+		} else {
+			updates["{{.Name}}"] = **p.{{.Field}}
+		}
+	{{- else}}
+		updates["{{.Name}}"] = *p.{{.Field}}
+	{{- end}}
+	}
+{{- end}}
+	return updates
+}
+`
+
+type patchField struct {
+	dalColumn
+	Nullable bool
+}
+
+// isNullable reports whether a column may be NULL and its model field
+// cannot tell, so that the DTO and patch add a pointer to it.
+func isNullable(c *sqlparser.ColumnDefinition) bool {
+	return !bool(c.Type.NotNull) && !pointerColumns[c]
+}
+
+func genPatch(pkg string, ddl *sqlparser.DDL) string {
+	pk := make(map[string]bool)
+	for _, c := range primaryKeyColumns(ddl) {
+		pk[c.Name] = true
+	}
+	var fields []patchField
+	var types []string
+	for _, c := range ddl.TableSpec.Columns {
+		if pk[c.Name.String()] || generatedColumns[c] {
+			continue
+		}
+		f := patchField{dalColumn: newDALColumn(c), Nullable: isNullable(c)}
+		types = append(types, f.Type)
+		fields = append(fields, f)
+	}
+
+	params := struct {
+		Package   string
+		TableName string
+		Imports   string
+		Fields    []patchField
+	}{
+		Package:   pkg,
+		TableName: modelName(ddl.NewName.Name.String()),
+		Imports:   renderImports(importsOf(types...)),
+		Fields:    fields,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("patch").Parse(patchTemplate)).Execute(&buf, params)
+	return buf.String()
+}
@@ -0,0 +1,149 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const patchSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  score double NOT NULL,
+  active bool NOT NULL,
+  nickname varchar(64) DEFAULT NULL,
+  age int,
+  avatar blob,
+  born_at datetime NULL,
+  PRIMARY KEY (id)
+);`
+
+const patchTest = `package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var born = time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+
+func filled() Users {
+	return Users{ID: 1, Email: "a@example.com", Score: 1.5, Active: true, Nickname: "ann", Age: 30, Avatar: []byte{1}, BornAt: born}
+}
+
+func TestPatchUnset(t *testing.T) {
+	m := filled()
+	var p UsersPatch
+	p.ApplyTo(&m)
+	if !reflect.DeepEqual(m, filled()) {
+		t.Errorf("the empty patch changed %+v", m)
+	}
+	if u := p.Updates(); len(u) != 0 {
+		t.Errorf("the empty patch updates %v", u)
+	}
+}
+
+func TestPatchSet(t *testing.T) {
+	email, score, active := "b@example.com", 2.5, false
+	nickname, age, avatar, bornAt := "bob", 40, []byte{2}, born.AddDate(1, 0, 0)
+	pNickname, pAge, pAvatar, pBornAt := &nickname, &age, &avatar, &bornAt
+	p := UsersPatch{Email: &email, Score: &score, Active: &active, Nickname: &pNickname, Age: &pAge, Avatar: &pAvatar, BornAt: &pBornAt}
+	m := filled()
+	p.ApplyTo(&m)
+	want := Users{ID: 1, Email: email, Score: score, Active: active, Nickname: nickname, Age: age, Avatar: avatar, BornAt: bornAt}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("got %+v, want %+v", m, want)
+	}
+	wantUpdates := map[string]interface{}{
+		"email": email, "score": score, "active": active,
+		"nickname": nickname, "age": age, "avatar": avatar, "born_at": bornAt,
+	}
+	if u := p.Updates(); !reflect.DeepEqual(u, wantUpdates) {
+		t.Errorf("got %v, want %v", u, wantUpdates)
+	}
+}
+
+func TestPatchNull(t *testing.T) {
+	var nickname *string
+	var age *int
+	var avatar *[]byte
+	var bornAt *time.Time
+	p := UsersPatch{Nickname: &nickname, Age: &age, Avatar: &avatar, BornAt: &bornAt}
+	m := filled()
+	p.ApplyTo(&m)
+	want := Users{ID: 1, Email: "a@example.com", Score: 1.5, Active: true}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("got %+v, want %+v", m, want)
+	}
+	wantUpdates := map[string]interface{}{"nickname": nil, "age": nil, "avatar": nil, "born_at": nil}
+	if u := p.Updates(); !reflect.DeepEqual(u, wantUpdates) {
+		t.Errorf("got %v, want %v", u, wantUpdates)
+	}
+}
+
+func TestPatchSQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Users{}); err != nil {
+		t.Fatal(err)
+	}
+	m := filled()
+	if err := db.Create(&m).Error; err != nil {
+		t.Fatal(err)
+	}
+	email := "b@example.com"
+	var nickname *string
+	p := UsersPatch{Email: &email, Nickname: &nickname}
+	if err := db.Model(&Users{ID: 1}).Updates(p.Updates()).Error; err != nil {
+		t.Fatal(err)
+	}
+	var row struct {
+		Email    string
+		Nickname *string
+		Age      *int
+	}
+	if err := db.Table("users").Select("email, nickname, age").Where("id = 1").Scan(&row).Error; err != nil {
+		t.Fatal(err)
+	}
+	if row.Email != email || row.Nickname != nil || row.Age == nil || *row.Age != 30 {
+		t.Errorf("got %+v, want the email set, the nickname NULL and the age untouched", row)
+	}
+}
+`
+
+func TestPatch(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", patchSchema)
+	if err := run("-gen-patch", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	patch := gofmt(t, readFile(t, "model/users_patch.go"))
+	for _, want := range []string{
+		"Email    *string\n",
+		"Nickname **string\n",
+		"BornAt   **time.Time\n",
+	} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("no %q in:\n%s", want, patch)
+		}
+	}
+	if strings.Contains(patch, "ID ") {
+		t.Errorf("the primary key is patched:\n%s", patch)
+	}
+	writeFile(t, "model/patch_test.go", patchTest)
+	goTest(t, "./model")
+}
+
+func TestPatchNullablePointers(t *testing.T) {
+	generate(t, patchSchema, "users", "-gen-patch", "-nullable-pointers")
+	patch := gofmt(t, readFile(t, "model/users_patch.go"))
+	// the model field is a pointer already: nil sets NULL
+	if !strings.Contains(patch, "Nickname **string\n") || !strings.Contains(patch, "m.Nickname = *p.Nickname") {
+		t.Errorf("nickname is not patched through its pointer:\n%s", patch)
+	}
+}
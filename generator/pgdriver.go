@@ -0,0 +1,8 @@
+//go:build postgres
+// +build postgres
+
+package generator
+
+// The Postgres driver a postgres:// -dsn connects with, left out of the
+// default build like the MySQL one.
+import _ "github.com/lib/pq"
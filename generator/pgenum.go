@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// pgEnumTypes holds the labels of the CREATE TYPE ... AS ENUM types of a
+// Postgres schema by type name.
+var pgEnumTypes = make(map[string][]string)
+
+// pgEnumColumns holds the Postgres enum type of the columns declared with
+// one, whose Go type is shared by all of them.
+var pgEnumColumns = make(map[*sqlparser.ColumnDefinition]string)
+
+// parsePgCreateType parses CREATE TYPE [schema.]name AS ENUM ('a', ...),
+// after CREATE TYPE. Composite and range types are ignored.
+func parsePgCreateType(p *pgParser) error {
+	_, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.accept("as", "enum") {
+		return nil
+	}
+	if err := p.expect("("); err != nil {
+		return fmt.Errorf("type %s: %v", name, err)
+	}
+	var labels []string
+	for !p.accept(")") {
+		t := p.next()
+		switch {
+		case t.Kind == pgString:
+			labels = append(labels, t.Text)
+		case !t.is(","):
+			return fmt.Errorf("type %s: unexpected %q in the enum labels", name, t.Text)
+		}
+		if p.done() {
+			return fmt.Errorf("type %s: unterminated enum labels", name)
+		}
+	}
+	pgEnumTypes[name] = labels
+	return nil
+}
+
+// markPgEnumColumn turns a column of a Postgres enum type into an enum
+// column, as if declared ENUM('a', ...) in MySQL.
+func markPgEnumColumn(def *sqlparser.ColumnDefinition) {
+	labels, ok := pgEnumTypes[def.Type.Type]
+	if !ok {
+		return
+	}
+	pgEnumColumns[def] = def.Type.Type
+	def.Type.Type = "enum"
+	def.Type.EnumValues = make([]string, 0, len(labels))
+	for _, l := range labels {
+		def.Type.EnumValues = append(def.Type.EnumValues, "'"+strings.Replace(l, "'", "''", -1)+"'")
+	}
+}
@@ -0,0 +1,308 @@
+package generator
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The pg_catalog rows a Postgres schema is introspected from.
+type (
+	pgInfoEnum struct {
+		Schema string
+		Name   string
+		Label  string
+	}
+	pgInfoTable struct {
+		Schema  string
+		Name    string
+		Comment string
+	}
+	pgInfoColumn struct {
+		Schema  string
+		Table   string
+		Name    string
+		Type    string // format_type of the column, or of the base type of its domain
+		NotNull bool
+		Default string
+		// Identity is the attidentity of the column: a for GENERATED ALWAYS,
+		// d for BY DEFAULT, "" for none.
+		Identity string
+		// Generated is the expression of a GENERATED ALWAYS AS column.
+		Generated string
+		Comment   string
+	}
+	pgInfoConstraint struct {
+		Schema     string
+		Table      string
+		Name       string
+		Definition string // pg_get_constraintdef, e.g. PRIMARY KEY (id)
+	}
+	pgInfoIndex struct {
+		Definition string // pg_get_indexdef, e.g. CREATE INDEX ... ON ... USING btree (a)
+	}
+	pgInfoView struct {
+		Schema     string
+		Name       string
+		Definition string // pg_get_viewdef, the SELECT of the view
+	}
+)
+
+// pgInfoSchema holds the pg_catalog rows of the -pg-schema namespaces of a
+// database, in attnum and label order.
+type pgInfoSchema struct {
+	Enums       []pgInfoEnum
+	Tables      []pgInfoTable
+	Columns     []pgInfoColumn
+	Constraints []pgInfoConstraint
+	Indexes     []pgInfoIndex
+	Views       []pgInfoView
+}
+
+func isPostgresDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
+
+// introspectPostgres reads the -pg-schema namespaces of the database of
+// dsn from its catalog and returns them as the pg_dump style statements
+// the Postgres parser reads.
+func introspectPostgres(dsn string) ([]byte, error) {
+	if !driverRegistered("postgres") {
+		return nil, fmt.Errorf("-dsn: dalgen was built without the Postgres driver, build it with -tags postgres")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("-dsn: %v", err)
+	}
+	defer db.Close()
+	var schemas []string
+	for _, s := range strings.Split(pgSchemas, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			schemas = append(schemas, s)
+		}
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("-pg-schema names no schema")
+	}
+	schema, err := queryPgInfoSchema(db, schemas, introspectTables)
+	if err != nil {
+		return nil, fmt.Errorf("-dsn: %v", err)
+	}
+	if len(schema.Tables) == 0 {
+		return nil, fmt.Errorf("-dsn: no tables in schema %s", strings.Join(schemas, ", "))
+	}
+	inputSpans = []inputSpan{{"pg_catalog", 1}}
+	return []byte(schema.SQL()), nil
+}
+
+// queryPgInfoSchema reads the tables of the given namespaces of db, limited
+// to the -introspect-tables globs.
+func queryPgInfoSchema(db *sql.DB, schemas []string, globs string) (*pgInfoSchema, error) {
+	var args []interface{}
+	placeholders := make([]string, 0, len(schemas))
+	for _, s := range schemas {
+		args = append(args, s)
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+	}
+	inSchemas := "n.nspname IN (" + strings.Join(placeholders, ", ") + ")"
+	filter, filterArgs := tableFilter(globs)
+	for _, a := range filterArgs {
+		args = append(args, a)
+		filter = strings.Replace(filter, "TABLE_NAME LIKE ?", "c.relname LIKE $"+strconv.Itoa(len(args)), 1)
+	}
+	query := func(q string, args []interface{}, scan func(*sql.Rows) error) error {
+		rows, err := db.Query(q, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	}
+	s := &pgInfoSchema{}
+	err := query("SELECT n.nspname, t.typname, e.enumlabel FROM pg_type t"+
+		" JOIN pg_enum e ON e.enumtypid = t.oid JOIN pg_namespace n ON n.oid = t.typnamespace"+
+		" WHERE "+inSchemas+" ORDER BY n.nspname, t.typname, e.enumsortorder",
+		args[:len(schemas)],
+		func(rows *sql.Rows) error {
+			var e pgInfoEnum
+			err := rows.Scan(&e.Schema, &e.Name, &e.Label)
+			s.Enums = append(s.Enums, e)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	// the tables and partitioned tables, leaving out their partitions
+	tables := " FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace" +
+		" WHERE c.relkind IN ('r', 'p') AND NOT c.relispartition AND " + inSchemas + filter
+	err = query("SELECT n.nspname, c.relname, COALESCE(obj_description(c.oid, 'pg_class'), '')"+
+		tables+" ORDER BY n.nspname, c.relname",
+		args,
+		func(rows *sql.Rows) error {
+			var t pgInfoTable
+			err := rows.Scan(&t.Schema, &t.Name, &t.Comment)
+			s.Tables = append(s.Tables, t)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	err = query("SELECT n.nspname, c.relname, a.attname,"+
+		" format_type(CASE WHEN t.typtype = 'd' THEN t.typbasetype ELSE a.atttypid END,"+
+		" CASE WHEN t.typtype = 'd' THEN t.typtypmod ELSE a.atttypmod END),"+
+		" a.attnotnull OR t.typtype = 'd' AND t.typnotnull,"+
+		" COALESCE(CASE WHEN a.attgenerated = '' THEN pg_get_expr(d.adbin, d.adrelid) END, ''),"+
+		" a.attidentity::text,"+
+		" COALESCE(CASE WHEN a.attgenerated <> '' THEN pg_get_expr(d.adbin, d.adrelid) END, ''),"+
+		" COALESCE(col_description(c.oid, a.attnum), '')"+
+		" FROM pg_attribute a JOIN pg_class c ON c.oid = a.attrelid"+
+		" JOIN pg_namespace n ON n.oid = c.relnamespace JOIN pg_type t ON t.oid = a.atttypid"+
+		" LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum"+
+		" WHERE a.attnum > 0 AND NOT a.attisdropped AND c.relkind IN ('r', 'p') AND NOT c.relispartition"+
+		" AND "+inSchemas+filter+" ORDER BY n.nspname, c.relname, a.attnum",
+		args,
+		func(rows *sql.Rows) error {
+			var c pgInfoColumn
+			err := rows.Scan(&c.Schema, &c.Table, &c.Name, &c.Type, &c.NotNull, &c.Default, &c.Identity, &c.Generated, &c.Comment)
+			s.Columns = append(s.Columns, c)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	err = query("SELECT n.nspname, c.relname, con.conname, pg_get_constraintdef(con.oid)"+
+		" FROM pg_constraint con JOIN pg_class c ON c.oid = con.conrelid"+
+		" JOIN pg_namespace n ON n.oid = c.relnamespace"+
+		" WHERE con.contype IN ('p', 'u', 'f', 'c') AND "+inSchemas+filter+
+		" ORDER BY n.nspname, c.relname, con.contype <> 'p', con.conname",
+		args,
+		func(rows *sql.Rows) error {
+			var con pgInfoConstraint
+			err := rows.Scan(&con.Schema, &con.Table, &con.Name, &con.Definition)
+			s.Constraints = append(s.Constraints, con)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	// the indexes not backing a constraint, leaving out expression indexes
+	err = query("SELECT pg_get_indexdef(i.indexrelid)"+
+		" FROM pg_index i JOIN pg_class c ON c.oid = i.indrelid JOIN pg_namespace n ON n.oid = c.relnamespace"+
+		" WHERE i.indexprs IS NULL AND NOT EXISTS (SELECT 1 FROM pg_constraint con"+
+		" WHERE con.conindid = i.indexrelid AND con.conrelid = i.indrelid)"+
+		" AND "+inSchemas+filter+" ORDER BY n.nspname, c.relname, i.indexrelid",
+		args,
+		func(rows *sql.Rows) error {
+			var idx pgInfoIndex
+			err := rows.Scan(&idx.Definition)
+			s.Indexes = append(s.Indexes, idx)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	if !viewsFlag {
+		return s, nil
+	}
+	err = query("SELECT n.nspname, c.relname, pg_get_viewdef(c.oid)"+
+		" FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace"+
+		" WHERE c.relkind = 'v' AND "+inSchemas+filter+" ORDER BY n.nspname, c.relname",
+		args,
+		func(rows *sql.Rows) error {
+			var v pgInfoView
+			err := rows.Scan(&v.Schema, &v.Name, &v.Definition)
+			s.Views = append(s.Views, v)
+			return err
+		})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// pgQuoteIdent quotes a Postgres identifier.
+func pgQuoteIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// pgQuoteString quotes s as a Postgres string literal.
+func pgQuoteString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// SQL renders s as the CREATE TYPE, CREATE TABLE, CREATE INDEX, CREATE
+// VIEW and COMMENT statements of a pg_dump.
+func (s *pgInfoSchema) SQL() string {
+	var b strings.Builder
+	for i := 0; i < len(s.Enums); {
+		e := s.Enums[i]
+		var labels []string
+		for ; i < len(s.Enums) && s.Enums[i].Schema == e.Schema && s.Enums[i].Name == e.Name; i++ {
+			labels = append(labels, pgQuoteString(s.Enums[i].Label))
+		}
+		b.WriteString("CREATE TYPE " + pgQuoteIdent(e.Schema) + "." + pgQuoteIdent(e.Name) +
+			" AS ENUM (" + strings.Join(labels, ", ") + ");\n\n")
+	}
+	key := func(schema, table string) string { return schema + "." + table }
+	columns := make(map[string][]pgInfoColumn)
+	for _, c := range s.Columns {
+		columns[key(c.Schema, c.Table)] = append(columns[key(c.Schema, c.Table)], c)
+	}
+	constraints := make(map[string][]pgInfoConstraint)
+	for _, con := range s.Constraints {
+		constraints[key(con.Schema, con.Table)] = append(constraints[key(con.Schema, con.Table)], con)
+	}
+	var comments []string
+	for _, t := range s.Tables {
+		name := pgQuoteIdent(t.Schema) + "." + pgQuoteIdent(t.Name)
+		var defs []string
+		for _, c := range columns[key(t.Schema, t.Name)] {
+			defs = append(defs, c.definition())
+			if c.Comment != "" {
+				comments = append(comments, "COMMENT ON COLUMN "+name+"."+pgQuoteIdent(c.Name)+" IS "+pgQuoteString(c.Comment)+";\n")
+			}
+		}
+		for _, con := range constraints[key(t.Schema, t.Name)] {
+			defs = append(defs, "CONSTRAINT "+pgQuoteIdent(con.Name)+" "+con.Definition)
+		}
+		b.WriteString("CREATE TABLE " + name + " (\n    " + strings.Join(defs, ",\n    ") + "\n);\n\n")
+		if t.Comment != "" {
+			comments = append(comments, "COMMENT ON TABLE "+name+" IS "+pgQuoteString(t.Comment)+";\n")
+		}
+	}
+	for _, idx := range s.Indexes {
+		b.WriteString(idx.Definition + ";\n\n")
+	}
+	for _, v := range s.Views {
+		// pg_get_viewdef ends the SELECT with a semicolon
+		sel := strings.TrimSuffix(strings.TrimSpace(v.Definition), ";")
+		b.WriteString("CREATE VIEW " + pgQuoteIdent(v.Schema) + "." + pgQuoteIdent(v.Name) + " AS\n " + sel + ";\n\n")
+	}
+	b.WriteString(strings.Join(comments, ""))
+	return b.String()
+}
+
+// definition renders the column as in a CREATE TABLE statement.
+func (c pgInfoColumn) definition() string {
+	def := pgQuoteIdent(c.Name) + " " + c.Type
+	switch {
+	case c.Generated != "":
+		def += " GENERATED ALWAYS AS (" + c.Generated + ") STORED"
+	case c.Identity == "a":
+		def += " GENERATED ALWAYS AS IDENTITY"
+	case c.Identity == "d":
+		def += " GENERATED BY DEFAULT AS IDENTITY"
+	case c.Default != "":
+		def += " DEFAULT " + c.Default
+	}
+	if c.NotNull {
+		def += " NOT NULL"
+	}
+	return def
+}
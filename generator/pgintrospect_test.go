@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPgInfoSchemaViews(t *testing.T) {
+	reset()
+	dialect, viewsFlag = "postgres", true
+	s := &pgInfoSchema{
+		Tables: []pgInfoTable{{Schema: "public", Name: "users"}},
+		Columns: []pgInfoColumn{
+			{Schema: "public", Table: "users", Name: "id", Type: "bigint", NotNull: true},
+			{Schema: "public", Table: "users", Name: "email", Type: "character varying(255)", NotNull: true},
+		},
+		Constraints: []pgInfoConstraint{{Schema: "public", Table: "users", Name: "users_pkey", Definition: "PRIMARY KEY (id)"}},
+		Views:       []pgInfoView{{Schema: "public", Name: "user_emails", Definition: " SELECT users.id,\n    (users.email)::text AS email\n   FROM users;"}},
+	}
+	sql := s.SQL()
+	if !strings.Contains(sql, "CREATE VIEW \"public\".\"user_emails\" AS\n SELECT users.id,") {
+		t.Errorf("SQL() lacks the view:\n%s", sql)
+	}
+	ddls, err := ParsePostgresSQLs(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ddls) != 2 {
+		t.Fatalf("got %d tables, want users and user_emails:\n%s", len(ddls), sql)
+	}
+	view := ddls[1]
+	if name := view.NewName.Name.String(); name != "user_emails" || !viewTables[view] {
+		t.Fatalf("got table %s, want the user_emails view", name)
+	}
+	for i, want := range []string{"int64", "string"} {
+		if got := GoType(view.TableSpec.Columns[i]); got != want {
+			t.Errorf("column %s is %s, want %s", view.TableSpec.Columns[i].Name, got, want)
+		}
+	}
+}
@@ -0,0 +1,985 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// The sql parser only knows MySQL, so -dialect=postgres parses CREATE TABLE,
+// ALTER TABLE, CREATE INDEX, CREATE VIEW and COMMENT ON statements by hand
+// into the same sqlparser AST. -dialect=sqlite shares the parser, see
+// sqlite.go.
+
+// handParsed reports whether -dialect is parsed by the hand parser.
+func handParsed() bool {
+	return dialect == "postgres" || dialect == "sqlite"
+}
+
+type pgTokenKind int
+
+const (
+	pgIdent pgTokenKind = iota
+	// pgQuotedIdent is a "quoted" identifier, which keeps its case.
+	pgQuotedIdent
+	pgString
+	pgNumber
+	pgPunct
+)
+
+type pgToken struct {
+	Kind pgTokenKind
+	// Text is lower cased for Postgres identifiers, unquoted for quoted
+	// identifiers and strings.
+	Text string
+}
+
+// is reports whether t is the unquoted keyword or the punctuation s.
+func (t pgToken) is(s string) bool {
+	return t.Kind == pgIdent && strings.EqualFold(t.Text, s) || t.Kind == pgPunct && t.Text == s
+}
+
+func (t pgToken) isName() bool {
+	return t.Kind == pgIdent || t.Kind == pgQuotedIdent
+}
+
+// String renders the token back as SQL.
+// pgOperatorChars are the characters Postgres operators are made of.
+const pgOperatorChars = "+-*/<>=~!@#%^&|`?"
+
+// pgOperator returns the operator at the start of s, e.g. >= or <>. As in
+// Postgres, a multi-character operator only ends in + or - if it contains
+// one of ~!@#%^&|`?, so a=-1 is a = -1.
+func pgOperator(s string) string {
+	n := 1
+	for n < len(s) && strings.IndexByte(pgOperatorChars, s[n]) >= 0 &&
+		!strings.HasPrefix(s[n:], "--") && !strings.HasPrefix(s[n:], "/*") {
+		n++
+	}
+	op := s[:n]
+	if !strings.ContainsAny(op, "~!@#%^&|`?") {
+		for len(op) > 1 && (op[len(op)-1] == '+' || op[len(op)-1] == '-') {
+			op = op[:len(op)-1]
+		}
+	}
+	return op
+}
+
+func (t pgToken) String() string {
+	switch t.Kind {
+	case pgQuotedIdent:
+		return `"` + strings.Replace(t.Text, `"`, `""`, -1) + `"`
+	case pgString:
+		return "'" + strings.Replace(t.Text, "'", "''", -1) + "'"
+	}
+	return t.Text
+}
+
+// dollarTag returns the $tag$ opening a dollar quoted string at s, "" if
+// none.
+func dollarTag(s string) string {
+	if !strings.HasPrefix(s, "$") {
+		return ""
+	}
+	end := strings.IndexByte(s[1:], '$')
+	if end < 0 {
+		return ""
+	}
+	for _, r := range s[1 : end+1] {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return ""
+		}
+	}
+	return s[:end+2]
+}
+
+// splitPostgresStatements splits a Postgres script into statements, minding
+// quotes, dollar quoting and comments, and skipping the data of COPY ...
+// FROM stdin statements.
+func splitPostgresStatements(content string) []string {
+	var stmts []string
+	start := 0
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == '\'' || c == '"':
+			for i++; i < len(content); i++ {
+				if content[i] == c {
+					if i+1 < len(content) && content[i+1] == c {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case c == '-' && strings.HasPrefix(content[i:], "--"):
+			if j := strings.IndexByte(content[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(content)
+			}
+		case c == '/' && strings.HasPrefix(content[i:], "/*"):
+			if j := strings.Index(content[i+2:], "*/"); j >= 0 {
+				i += j + 3
+			} else {
+				i = len(content)
+			}
+		case c == '$':
+			if tag := dollarTag(content[i:]); tag != "" {
+				if j := strings.Index(content[i+len(tag):], tag); j >= 0 {
+					i += len(tag) + j + len(tag) - 1
+				} else {
+					i = len(content)
+				}
+			}
+		case c == ';':
+			stmt := strings.TrimSpace(content[start:i])
+			start = i + 1
+			if _, ok := sqlKeyword(stmt, "copy"); ok && strings.HasSuffix(strings.ToLower(stmt), "from stdin") {
+				// the data follows up to a line of \.
+				if j := strings.Index(content[start:], "\n\\."); j >= 0 {
+					i = start + j + 3
+				} else {
+					i = len(content)
+				}
+				start = i
+				continue
+			}
+			if stmt != "" {
+				stmts = append(stmts, stmt)
+			}
+		}
+	}
+	if stmt := strings.TrimSpace(content[start:]); stmt != "" {
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
+
+// tokenizePostgres splits a statement into tokens, dropping comments.
+func tokenizePostgres(stmt string) ([]pgToken, error) {
+	var tokens []pgToken
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(stmt[i:], "--"):
+			if j := strings.IndexByte(stmt[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(stmt)
+			}
+		case strings.HasPrefix(stmt[i:], "/*"):
+			j := strings.Index(stmt[i+2:], "*/")
+			if j < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += j + 4
+		case c == '\'' || c == '"' || (c == 'E' || c == 'e') && i+1 < len(stmt) && stmt[i+1] == '\'':
+			escapes := c == 'E' || c == 'e'
+			if escapes {
+				i++
+				c = '\''
+			}
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(stmt); j++ {
+				if escapes && stmt[j] == '\\' && j+1 < len(stmt) {
+					j++
+					switch stmt[j] {
+					case 'n':
+						b.WriteByte('\n')
+					case 't':
+						b.WriteByte('\t')
+					default:
+						b.WriteByte(stmt[j])
+					}
+					continue
+				}
+				if stmt[j] == c {
+					if j+1 < len(stmt) && stmt[j+1] == c {
+						b.WriteByte(c)
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteByte(stmt[j])
+			}
+			if j >= len(stmt) {
+				return nil, fmt.Errorf("unterminated quote in %q", stmt)
+			}
+			kind := pgString
+			if c == '"' {
+				kind = pgQuotedIdent
+			}
+			tokens = append(tokens, pgToken{kind, b.String()})
+			i = j + 1
+		case c == '$' && dollarTag(stmt[i:]) != "":
+			tag := dollarTag(stmt[i:])
+			j := strings.Index(stmt[i+len(tag):], tag)
+			if j < 0 {
+				return nil, fmt.Errorf("unterminated dollar quote in %q", stmt)
+			}
+			tokens = append(tokens, pgToken{pgString, stmt[i+len(tag) : i+len(tag)+j]})
+			i += len(tag) + j + len(tag)
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(stmt) && stmt[i+1] >= '0' && stmt[i+1] <= '9':
+			j := i + 1
+			for j < len(stmt) && (stmt[j] >= '0' && stmt[j] <= '9' || stmt[j] == '.' || stmt[j] == 'e' || stmt[j] == 'E') {
+				j++
+			}
+			tokens = append(tokens, pgToken{pgNumber, stmt[i:j]})
+			i = j
+		case c == '_' || c >= 0x80 || unicode.IsLetter(rune(c)):
+			j := i + 1
+			for j < len(stmt) && (stmt[j] == '_' || stmt[j] == '$' || stmt[j] >= 0x80 || unicode.IsLetter(rune(stmt[j])) || unicode.IsDigit(rune(stmt[j]))) {
+				j++
+			}
+			word := stmt[i:j]
+			if dialect != "sqlite" {
+				// SQLite keeps the case of identifiers
+				word = strings.ToLower(word)
+			}
+			tokens = append(tokens, pgToken{pgIdent, word})
+			i = j
+		case dialect == "sqlite" && (c == '`' || c == '['):
+			q := byte('`')
+			if c == '[' {
+				q = ']'
+			}
+			j := strings.IndexByte(stmt[i+1:], q)
+			if j < 0 {
+				return nil, fmt.Errorf("unterminated quote in %q", stmt)
+			}
+			tokens = append(tokens, pgToken{pgQuotedIdent, stmt[i+1 : i+1+j]})
+			i += j + 2
+		case strings.HasPrefix(stmt[i:], "::"):
+			tokens = append(tokens, pgToken{pgPunct, "::"})
+			i += 2
+		case strings.IndexByte(pgOperatorChars, c) >= 0:
+			op := pgOperator(stmt[i:])
+			tokens = append(tokens, pgToken{pgPunct, op})
+			i += len(op)
+		default:
+			tokens = append(tokens, pgToken{pgPunct, string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// pgParser walks the tokens of one statement.
+type pgParser struct {
+	tokens []pgToken
+	pos    int
+}
+
+func (p *pgParser) peek() pgToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return pgToken{Kind: pgPunct}
+}
+
+func (p *pgParser) next() pgToken {
+	t := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return t
+}
+
+func (p *pgParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// accept consumes the given keywords if they all come next.
+func (p *pgParser) accept(keywords ...string) bool {
+	for i, k := range keywords {
+		if p.pos+i >= len(p.tokens) || !p.tokens[p.pos+i].is(k) {
+			return false
+		}
+	}
+	p.pos += len(keywords)
+	return true
+}
+
+func (p *pgParser) expect(keywords ...string) error {
+	if !p.accept(keywords...) {
+		return fmt.Errorf("expected %s near %q", strings.Join(keywords, " "), p.peek().Text)
+	}
+	return nil
+}
+
+func (p *pgParser) name() (string, error) {
+	t := p.next()
+	if !t.isName() {
+		return "", fmt.Errorf("expected a name near %q", t.Text)
+	}
+	return t.Text, nil
+}
+
+// qualifiedName parses [schema.]name.
+func (p *pgParser) qualifiedName() (string, string, error) {
+	name, err := p.name()
+	if err != nil {
+		return "", "", err
+	}
+	if p.accept(".") {
+		table, err := p.name()
+		return name, table, err
+	}
+	return "", name, nil
+}
+
+// skipGroup skips a parenthesized group, the opening parenthesis included.
+func (p *pgParser) skipGroup() {
+	depth := 0
+	for !p.done() {
+		t := p.next()
+		switch {
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			depth--
+		}
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+// groupText consumes a parenthesized group and returns its text without
+// the parentheses, e.g. the expression of a CHECK constraint.
+func (p *pgParser) groupText() string {
+	start := p.pos
+	p.skipGroup()
+	var b strings.Builder
+	for i := start + 1; i < p.pos-1; i++ {
+		t, prev := p.tokens[i], p.tokens[i-1]
+		call := t.is("(") && prev.Kind == pgIdent && !prev.is("and") && !prev.is("or") && !prev.is("not") && !prev.is("in")
+		tight := t.is("::") || prev.is("::") || t.is(".") || prev.is(".")
+		if i > start+1 && !t.is(")") && !t.is(",") && !prev.is("(") && !call && !tight {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.String())
+	}
+	return b.String()
+}
+
+// columnList parses a parenthesized list of column names.
+func (p *pgParser) columnList() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var cols []string
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, name)
+		// ASC, DESC, NULLS FIRST, opclasses and the like
+		for !p.peek().is(",") && !p.peek().is(")") && !p.done() {
+			if p.peek().is("(") {
+				return nil, fmt.Errorf("expression indexes are not supported")
+			}
+			p.next()
+		}
+		if p.accept(")") {
+			return cols, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// split splits the tokens up to the closing parenthesis of the current
+// group on the commas at its top level, consuming the closing parenthesis.
+func (p *pgParser) split() ([][]pgToken, error) {
+	var parts [][]pgToken
+	depth, start := 0, p.pos
+	for !p.done() {
+		t := p.next()
+		switch {
+		case t.is("(") || t.is("["):
+			depth++
+		case (t.is(")") || t.is("]")) && depth > 0:
+			depth--
+		case t.is(")"):
+			return append(parts, p.tokens[start:p.pos-1]), nil
+		case t.is(",") && depth == 0:
+			parts = append(parts, p.tokens[start:p.pos-1])
+			start = p.pos
+		}
+	}
+	return nil, fmt.Errorf("unterminated definition list")
+}
+
+// pgSerialTypes maps the serial pseudo types to their integer types.
+var pgSerialTypes = map[string]string{
+	"smallserial": "smallint",
+	"serial2":     "smallint",
+	"serial":      "integer",
+	"serial4":     "integer",
+	"bigserial":   "bigint",
+	"serial8":     "bigint",
+}
+
+// pgConstraintStart reports whether t starts a column constraint, ending
+// the type or default expression before it.
+func pgConstraintStart(t pgToken) bool {
+	switch {
+	case t.is("not"), t.is("null"), t.is("default"), t.is("primary"), t.is("unique"), t.is("references"),
+		t.is("check"), t.is("generated"), t.is("collate"), t.is("constraint"), t.is("as"):
+		return true
+	}
+	return false
+}
+
+func pgSQLVal(tokens []pgToken) *sqlparser.SQLVal {
+	// drop casts, e.g. 'active'::character varying
+	for i, t := range tokens {
+		if t.is("::") {
+			tokens = tokens[:i]
+			break
+		}
+	}
+	if len(tokens) == 1 {
+		switch t := tokens[0]; t.Kind {
+		case pgString:
+			return sqlparser.NewStrVal([]byte(t.Text))
+		case pgNumber:
+			if strings.ContainsAny(t.Text, ".eE") {
+				return sqlparser.NewFloatVal([]byte(t.Text))
+			}
+			return sqlparser.NewIntVal([]byte(t.Text))
+		}
+	}
+	if len(tokens) == 2 && tokens[0].is("-") && tokens[1].Kind == pgNumber {
+		return sqlparser.NewIntVal([]byte("-" + tokens[1].Text))
+	}
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString(t.String())
+	}
+	return sqlparser.NewValArg([]byte(b.String()))
+}
+
+// pgColumn is a parsed column definition along with the constraints it
+// declares inline.
+type pgColumn struct {
+	def     *sqlparser.ColumnDefinition
+	primary bool
+	unique  bool
+	fk      *foreignKey
+	// integer marks a SQLite column declared exactly INTEGER.
+	integer bool
+}
+
+func parsePgColumn(tokens []pgToken) (pgColumn, error) {
+	p := &pgParser{tokens: tokens}
+	var col pgColumn
+	name, err := p.name()
+	if err != nil {
+		return col, err
+	}
+	var ct sqlparser.ColumnType
+	generated := false
+	// def is allocated first for the inline CHECK constraints
+	def, constraint := &sqlparser.ColumnDefinition{}, ""
+	if dialect == "sqlite" {
+		ct.Type = sqliteTypeName(p)
+		col.integer = strings.EqualFold(ct.Type, "integer")
+	} else {
+		typ := p.next()
+		if !typ.isName() {
+			return col, fmt.Errorf("column %s: expected a type near %q", name, typ.Text)
+		}
+		if p.accept(".") {
+			// schema qualified type
+			typ = p.next()
+		}
+		ct.Type = pgTypeName(p, typ)
+		if t, ok := pgSerialTypes[ct.Type]; ok {
+			ct.Type, ct.Autoincrement = t, true
+		}
+	}
+	if p.accept("(") {
+		var args []string
+		for !p.done() && !p.peek().is(")") {
+			if t := p.next(); !t.is(",") {
+				args = append(args, t.Text)
+			}
+		}
+		p.next()
+		if len(args) > 0 {
+			ct.Length = sqlparser.NewIntVal([]byte(args[0]))
+		}
+		if len(args) > 1 {
+			ct.Scale = sqlparser.NewIntVal([]byte(args[1]))
+		}
+	}
+	if dialect == "sqlite" {
+		ct.Type = sqliteType(ct.Type)
+	} else {
+		ct.Type = pgTimeZone(p, ct.Type)
+	}
+	for p.accept("[") {
+		for !p.done() && !p.next().is("]") {
+		}
+		ct.Type += "[]"
+	}
+	for !p.done() {
+		switch {
+		case p.accept("not", "null"):
+			ct.NotNull = true
+		case p.accept("null"):
+		case p.accept("default"):
+			start := p.pos
+			depth := 0
+			for !p.done() && (depth > 0 || !pgConstraintStart(p.peek())) {
+				switch t := p.next(); {
+				case t.is("("):
+					depth++
+				case t.is(")"):
+					depth--
+				}
+			}
+			expr := p.tokens[start:p.pos]
+			if len(expr) > 0 && expr[0].is("nextval") {
+				ct.Autoincrement = true
+				break
+			}
+			ct.Default = pgSQLVal(expr)
+		case p.accept("primary", "key"):
+			col.primary = true
+			ct.NotNull = true
+		case p.accept("autoincrement"):
+			ct.Autoincrement = true
+		case p.peek().is("as"):
+			// SQLite's short form of GENERATED ALWAYS AS
+			p.next()
+			p.skipGroup()
+			generated = true
+		case p.accept("unique"):
+			col.unique = true
+		case p.accept("references"):
+			schema, table, err := p.qualifiedName()
+			if err != nil {
+				return col, err
+			}
+			fk := &foreignKey{Columns: []string{name}, RefSchema: schema, RefTable: table, RefColumns: []string{"id"}}
+			if p.peek().is("(") {
+				if fk.RefColumns, err = p.columnList(); err != nil {
+					return col, err
+				}
+			}
+			col.fk = fk
+		case p.accept("generated"):
+			// GENERATED {ALWAYS | BY DEFAULT} AS IDENTITY [(options)]
+			for !p.done() && !p.peek().is("as") {
+				p.next()
+			}
+			p.accept("as")
+			if p.accept("identity") {
+				ct.Autoincrement = true
+				ct.NotNull = true
+			} else {
+				// GENERATED ALWAYS AS (expr) STORED
+				generated = true
+			}
+			if p.peek().is("(") {
+				p.skipGroup()
+			}
+			p.accept("stored")
+		case p.peek().is("check"):
+			p.next()
+			columnChecks[def] = checkConstraint{Name: constraint, Expr: checkExpr(p.groupText())}
+		case p.accept("collate"):
+			p.next()
+		case p.accept("constraint"):
+			constraint = p.next().Text
+		default:
+			// ON DELETE clauses, DEFERRABLE and the like
+			p.next()
+		}
+	}
+	*def = sqlparser.ColumnDefinition{Name: sqlparser.NewColIdent(name), Type: ct}
+	col.def = def
+	markPgEnumColumn(def)
+	if generated {
+		generatedColumns[col.def] = true
+	}
+	return col, nil
+}
+
+// pgConstraint is a parsed table constraint: an index or a foreign key,
+// both nil for constraints that do not matter to the models.
+type pgConstraint struct {
+	index *sqlparser.IndexDefinition
+	fk    *foreignKey
+	check *checkConstraint
+}
+
+func isPgConstraint(tokens []pgToken) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	switch t := tokens[0]; {
+	case t.is("constraint"), t.is("primary"), t.is("unique"), t.is("foreign"), t.is("check"), t.is("exclude"), t.is("like"):
+		return true
+	}
+	return false
+}
+
+func newIndex(name string, primary, unique bool, cols []string) *sqlparser.IndexDefinition {
+	idx := &sqlparser.IndexDefinition{Info: &sqlparser.IndexInfo{
+		Name:    sqlparser.NewColIdent(name),
+		Primary: primary,
+		Unique:  unique || primary,
+	}}
+	if primary {
+		idx.Info.Type = "primary key"
+	}
+	for _, c := range cols {
+		idx.Columns = append(idx.Columns, &sqlparser.IndexColumn{Column: sqlparser.NewColIdent(c)})
+	}
+	return idx
+}
+
+func parsePgConstraint(table string, tokens []pgToken) (pgConstraint, error) {
+	p := &pgParser{tokens: tokens}
+	var c pgConstraint
+	name := ""
+	if p.accept("constraint") {
+		var err error
+		if name, err = p.name(); err != nil {
+			return c, err
+		}
+	}
+	switch {
+	case p.accept("primary", "key"):
+		cols, err := p.columnList()
+		if err != nil {
+			return c, err
+		}
+		c.index = newIndex("PRIMARY", true, false, cols)
+	case p.accept("unique"):
+		cols, err := p.columnList()
+		if err != nil {
+			return c, err
+		}
+		if name == "" {
+			name = table + "_" + strings.Join(cols, "_") + "_key"
+		}
+		c.index = newIndex(name, false, true, cols)
+	case p.accept("foreign", "key"):
+		cols, err := p.columnList()
+		if err != nil {
+			return c, err
+		}
+		if err := p.expect("references"); err != nil {
+			return c, err
+		}
+		schema, refTable, err := p.qualifiedName()
+		if err != nil {
+			return c, err
+		}
+		fk := &foreignKey{Name: name, Columns: cols, RefSchema: schema, RefTable: refTable}
+		if p.peek().is("(") {
+			if fk.RefColumns, err = p.columnList(); err != nil {
+				return c, err
+			}
+		} else {
+			fk.RefColumns = []string{"id"}
+		}
+		if len(fk.RefColumns) != len(fk.Columns) {
+			return c, fmt.Errorf("foreign key %v references %v", fk.Columns, fk.RefColumns)
+		}
+		c.fk = fk
+	case p.accept("check"):
+		c.check = &checkConstraint{Name: name, Expr: checkExpr(p.groupText())}
+	}
+	return c, nil
+}
+
+// addPgColumn adds a column and its inline constraints to a table.
+func addPgColumn(ddl *sqlparser.DDL, col pgColumn) {
+	spec := ddl.TableSpec
+	spec.Columns = append(spec.Columns, col.def)
+	name := col.def.Name.String()
+	if col.primary {
+		spec.Indexes = append(spec.Indexes, newIndex("PRIMARY", true, false, []string{name}))
+	}
+	if col.unique {
+		spec.Indexes = append(spec.Indexes, newIndex(ddl.NewName.Name.String()+"_"+name+"_key", false, true, []string{name}))
+	}
+	if col.fk != nil {
+		tableForeignKeys[ddl] = append(tableForeignKeys[ddl], *col.fk)
+	}
+}
+
+func addPgConstraint(ddl *sqlparser.DDL, c pgConstraint) {
+	if c.index != nil {
+		ddl.TableSpec.Indexes = append(ddl.TableSpec.Indexes, c.index)
+	}
+	if c.check != nil {
+		tableChecks[ddl] = append(tableChecks[ddl], *c.check)
+	}
+	if c.fk != nil {
+		tableForeignKeys[ddl] = append(tableForeignKeys[ddl], *c.fk)
+	}
+}
+
+func parsePgCreateTable(p *pgParser) (*sqlparser.DDL, error) {
+	for _, kw := range []string{"global", "local", "temporary", "temp", "unlogged"} {
+		p.accept(kw)
+	}
+	if err := p.expect("table"); err != nil {
+		return nil, err
+	}
+	p.accept("if", "not", "exists")
+	schema, table, err := p.qualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	ddl := &sqlparser.DDL{
+		Action:    sqlparser.CreateStr,
+		NewName:   sqlparser.TableName{Name: sqlparser.NewTableIdent(table), Qualifier: sqlparser.NewTableIdent(schema)},
+		TableSpec: &sqlparser.TableSpec{},
+	}
+	if p.accept("partition", "of") || p.accept("of") {
+		return nil, nil
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	defs, err := p.split()
+	if err != nil {
+		return nil, err
+	}
+	integers := make(map[string]bool)
+	for _, def := range defs {
+		if len(def) == 0 {
+			continue
+		}
+		if isPgConstraint(def) {
+			c, err := parsePgConstraint(table, def)
+			if err != nil {
+				return nil, fmt.Errorf("table %s: %v", table, err)
+			}
+			addPgConstraint(ddl, c)
+			continue
+		}
+		col, err := parsePgColumn(def)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %v", table, err)
+		}
+		addPgColumn(ddl, col)
+		if col.integer {
+			integers[col.def.Name.Lowered()] = true
+		}
+	}
+	if dialect == "sqlite" {
+		applySQLiteRowid(ddl, integers, p)
+	}
+	return ddl, nil
+}
+
+func findTable(ddls []*sqlparser.DDL, name string) *sqlparser.DDL {
+	for _, ddl := range ddls {
+		if ddl.NewName.Name.String() == name {
+			return ddl
+		}
+	}
+	return nil
+}
+
+// applyPgCreateIndex adds a CREATE [UNIQUE] INDEX to its table.
+func applyPgCreateIndex(ddls []*sqlparser.DDL, p *pgParser) error {
+	unique := p.accept("unique")
+	if err := p.expect("index"); err != nil {
+		return err
+	}
+	p.accept("concurrently")
+	p.accept("if", "not", "exists")
+	name := ""
+	if !p.peek().is("on") {
+		var err error
+		if name, err = p.name(); err != nil {
+			return err
+		}
+	}
+	if err := p.expect("on"); err != nil {
+		return err
+	}
+	p.accept("only")
+	_, table, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if p.accept("using") {
+		p.next()
+	}
+	cols, err := p.columnList()
+	if err != nil {
+		return fmt.Errorf("index %s: %v", name, err)
+	}
+	if p.accept("where") {
+		// a partial unique index does not make its columns unique
+		unique = false
+	}
+	ddl := findTable(ddls, table)
+	if ddl == nil {
+		return fmt.Errorf("index %s: no such table %s", name, table)
+	}
+	ddl.TableSpec.Indexes = append(ddl.TableSpec.Indexes, newIndex(name, false, unique, cols))
+	return nil
+}
+
+// applyPgComment applies COMMENT ON TABLE and COMMENT ON COLUMN.
+func applyPgComment(ddls []*sqlparser.DDL, p *pgParser) error {
+	onTable := p.accept("table")
+	if !onTable && !p.accept("column") {
+		return nil
+	}
+	var names []string
+	for {
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+		if !p.accept(".") {
+			break
+		}
+	}
+	if err := p.expect("is"); err != nil {
+		return err
+	}
+	text := p.next()
+	if onTable {
+		if ddl := findTable(ddls, names[len(names)-1]); ddl != nil && text.Kind == pgString {
+			ddl.TableSpec.Options = " comment='" + text.Text + "'"
+		}
+		return nil
+	}
+	if len(names) < 2 {
+		return fmt.Errorf("comment on column %s: expected table.column", names[0])
+	}
+	ddl := findTable(ddls, names[len(names)-2])
+	if ddl == nil {
+		return nil
+	}
+	if c := findColumn(ddl, names[len(names)-1]); c != nil {
+		c.Type.Comment = nil
+		if text.Kind == pgString {
+			c.Type.Comment = sqlparser.NewStrVal([]byte(text.Text))
+		}
+	}
+	return nil
+}
+
+// pgAlterStatement renders an ALTER TABLE statement for applyAlterTable,
+// with its quoted identifiers in backticks.
+func pgAlterStatement(tokens []pgToken) string {
+	parts := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if t.Kind == pgQuotedIdent {
+			parts = append(parts, "`"+t.Text+"`")
+			continue
+		}
+		parts = append(parts, t.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+func pgTokens(def string) ([]pgToken, error) {
+	def = strings.Replace(def, "`", `"`, -1)
+	return tokenizePostgres(def)
+}
+
+// ParsePostgresSQLs is the -dialect=postgres and -dialect=sqlite ParseSQLs.
+func ParsePostgresSQLs(content string) ([]*sqlparser.DDL, error) {
+	var ddls []*sqlparser.DDL
+	stmts := splitPostgresStatements(content)
+	lines := statementLines(content, stmts)
+	for i, stmt := range stmts {
+		tokens, err := tokenizePostgres(stmt)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			continue
+		}
+		p := &pgParser{tokens: tokens}
+		switch {
+		case p.accept("create"):
+			p.accept("or", "replace")
+			if pos := p.pos; p.accept("temporary") || p.accept("temp") || p.accept("recursive") || p.peek().is("view") {
+				p.accept("recursive")
+				if p.accept("view") {
+					if !viewsFlag {
+						break
+					}
+					var view *sqlparser.DDL
+					if view, err = parsePgView(ddls, p); err == nil {
+						viewTables[view] = true
+						if ddls, err = addTable(ddls, view, inputPosition(lines[i])); err != nil {
+							return nil, err
+						}
+					}
+					break
+				}
+				p.pos = pos
+			}
+			if p.peek().is("unique") || p.peek().is("index") {
+				err = applyPgCreateIndex(ddls, p)
+				break
+			}
+			if p.accept("type") {
+				err = parsePgCreateType(p)
+				break
+			}
+			if pos := p.pos; p.accept("table") || p.accept("unlogged") || p.accept("temporary") || p.accept("temp") || p.accept("global") || p.accept("local") {
+				p.pos = pos
+				var ddl *sqlparser.DDL
+				if ddl, err = parsePgCreateTable(p); err == nil && ddl != nil {
+					if ddls, err = addTable(ddls, ddl, inputPosition(lines[i])); err != nil {
+						return nil, err
+					}
+				}
+			}
+		case p.accept("alter", "table"):
+			if n := len(tokens); n > 3 && tokens[n-3].is("owner") && tokens[n-2].is("to") {
+				// pg_dump sets the owner of the sequences with ALTER TABLE too
+				break
+			}
+			err = applyAlterTable(ddls, pgAlterStatement(tokens))
+			if err != nil {
+				return nil, err
+			}
+		case p.accept("comment", "on"):
+			err = applyPgComment(ddls, p)
+		case p.accept("drop", "table"):
+			p.accept("if", "exists")
+			for !p.done() {
+				var table string
+				if _, table, err = p.qualifiedName(); err != nil {
+					break
+				}
+				ddls = dropTable(ddls, table)
+				if !p.accept(",") {
+					break
+				}
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+	return ddls, nil
+}
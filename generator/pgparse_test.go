@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the tests")
+
+// TestPgDumpGolden generates the models of a pg_dump, under -strict, and
+// compares them with testdata/pgdump, which go test -update rewrites.
+func TestPgDumpGolden(t *testing.T) {
+	dump, err := ioutil.ReadFile("testdata/pgdump.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := filepath.Abs("testdata/pgdump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chdir(t)
+	writeFile(t, "pgdump.sql", string(dump))
+	if err := run("-dialect", "postgres", "-strict", "pgdump.sql"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"customers.go", "orders.go", "stores.go", "types.go"} {
+		checkGolden(t, filepath.Join(golden, name+".golden"), gofmt(t, readFile(t, filepath.Join("model", name))))
+	}
+}
+
+func TestPgUnsupportedType(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", "CREATE TABLE public.docs (id bigint PRIMARY KEY, body xml NOT NULL);")
+	err := run("-dialect", "postgres", "schema.sql")
+	if err == nil || !strings.Contains(err.Error(), "docs.body: unsupported type xml") {
+		t.Errorf("got %v, want docs.body: unsupported type xml", err)
+	}
+	writeFile(t, "types.json", `{"types": {"xml": {"type": "string"}}}`)
+	if err := run("-dialect", "postgres", "-type-map", "types.json", "schema.sql"); err != nil {
+		t.Errorf("xml mapped by -type-map: %v", err)
+	}
+}
@@ -0,0 +1,400 @@
+package generator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// netGoType returns the Go type of a Postgres inet, cidr or macaddr column:
+// a string, or with -net-types the IP, IPNet or MAC type dalgen declares
+// in the package, which scan the text form the drivers return.
+func netGoType(sqlType string) string {
+	if !netTypes {
+		return "string"
+	}
+	switch sqlType {
+	case "inet":
+		return "IP"
+	case "cidr":
+		return "IPNet"
+	default:
+		return "MAC"
+	}
+}
+
+// intervalGoType returns the Go type of a Postgres interval column: the
+// Duration type dalgen declares in the package, which scans the text form
+// the drivers return, e.g. "1 day 02:00:00", or a string with
+// -interval-string.
+func intervalGoType() string {
+	if intervalString {
+		return "string"
+	}
+	return "Duration"
+}
+
+// pgSupportTypes are the types dalgen declares for the Postgres columns,
+// by name, with the imports of their declarations.
+var pgSupportTypes = map[string]struct {
+	decl    string
+	imports []string
+}{
+	"Duration": {durationDecl, []string{"database/sql/driver", "fmt", "strconv", "strings", "time"}},
+	"IP":       {ipDecl, []string{"database/sql/driver", "fmt", "net", "strings"}},
+	"IPNet":    {ipNetDecl, []string{"database/sql/driver", "fmt", "net"}},
+	"MAC":      {macDecl, []string{"database/sql/driver", "fmt", "net"}},
+}
+
+// supportTypes returns the names of the types of pgSupportTypes the
+// columns of ddls use, sorted.
+func supportTypes(ddls []*sqlparser.DDL) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, ddl := range ddls {
+		for _, c := range ddl.TableSpec.Columns {
+			t := strings.TrimPrefix(safeGoType(c), "*")
+			if _, ok := pgSupportTypes[t]; ok && !seen[t] {
+				seen[t] = true
+				names = append(names, t)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// supportDecls returns the declarations of the support types names and
+// the imports they need.
+func supportDecls(names []string) (string, []string) {
+	var b strings.Builder
+	var imports []string
+	for _, name := range names {
+		t := pgSupportTypes[name]
+		b.WriteString(t.decl)
+		imports = append(imports, t.imports...)
+	}
+	if len(names) > 0 {
+		b.WriteString(scanTextDecl)
+	}
+	return b.String(), uniqueSorted(imports)
+}
+
+// genSupportTypes renders the dalgen_pgtypes file declaring the support
+// types names.
+func genSupportTypes(pkg string, names []string) string {
+	decls, imports := supportDecls(names)
+	return "\npackage " + pkg + "\n\n" + renderImports(imports) + decls
+}
+
+const scanTextDecl = `
+// dalgenScanText returns the text form a driver scans a column into.
+func dalgenScanText(typ string, src interface{}) (string, error) {
+	switch src := src.(type) {
+	case string:
+		return src, nil
+	case []byte:
+		return string(src), nil
+	}
+	return "", fmt.Errorf("%s: cannot scan %T", typ, src)
+}
+`
+
+const durationDecl = `
+// Duration is a Postgres interval, scanned from its text form in the
+// default postgres IntervalStyle, e.g. "1 year 2 mons 3 days 04:05:06.5",
+// and written as hours, minutes and seconds, e.g. "26:00:00". A month
+// counts 30 days and a year 365.25 days, as in extract(epoch from ...).
+type Duration time.Duration
+
+// durationUnits are the durations of the units of an interval, by their
+// singular name.
+var durationUnits = map[string]time.Duration{
+	"year": 8766 * time.Hour,
+	"mon":  30 * 24 * time.Hour,
+	"day":  24 * time.Hour,
+	"hour": time.Hour,
+	"min":  time.Minute,
+	"sec":  time.Second,
+}
+
+// Scan implements sql.Scanner.
+func (d *Duration) Scan(src interface{}) error {
+	if src == nil {
+		*d = 0
+		return nil
+	}
+	s, err := dalgenScanText("Duration", src)
+	if err != nil {
+		return err
+	}
+	var total time.Duration
+	fields := strings.Fields(s)
+	for i := 0; i < len(fields); i++ {
+		if strings.Contains(fields[i], ":") {
+			t, err := parseIntervalTime(fields[i])
+			if err != nil {
+				return fmt.Errorf("Duration: bad interval %q: %v", s, err)
+			}
+			total += t
+			continue
+		}
+		if i+1 == len(fields) {
+			return fmt.Errorf("Duration: bad interval %q", s)
+		}
+		unit, ok := durationUnits[strings.TrimSuffix(fields[i+1], "s")]
+		if !ok {
+			return fmt.Errorf("Duration: bad interval %q: unknown unit %s", s, fields[i+1])
+		}
+		if n, err := strconv.ParseInt(fields[i], 10, 64); err == nil {
+			total += time.Duration(n) * unit
+		} else if f, err := strconv.ParseFloat(fields[i], 64); err == nil {
+			total += time.Duration(f * float64(unit))
+		} else {
+			return fmt.Errorf("Duration: bad interval %q", s)
+		}
+		i++
+	}
+	*d = Duration(total)
+	return nil
+}
+
+// parseIntervalTime parses the [-]hh:mm[:ss[.ffffff]] part of an interval.
+func parseIntervalTime(s string) (time.Duration, error) {
+	neg := strings.HasPrefix(s, "-")
+	parts := strings.Split(strings.TrimLeft(s, "+-"), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("bad time %q", s)
+	}
+	h, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	m, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	t := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute
+	if len(parts) == 3 {
+		sec, err := time.ParseDuration(parts[2] + "s")
+		if err != nil {
+			return 0, err
+		}
+		t += sec
+	}
+	if neg {
+		t = -t
+	}
+	return t, nil
+}
+
+// Value implements driver.Valuer.
+func (d Duration) Value() (driver.Value, error) {
+	t := time.Duration(d)
+	sign := ""
+	if t < 0 {
+		sign, t = "-", -t
+	}
+	h := t / time.Hour
+	t -= h * time.Hour
+	m := t / time.Minute
+	t -= m * time.Minute
+	s := fmt.Sprintf("%s%d:%02d:%02d", sign, h, m, t/time.Second)
+	if frac := t % time.Second; frac != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%09d", frac), "0")
+	}
+	return s, nil
+}
+
+// String returns the duration as time.Duration formats it, e.g. "26h0m0s".
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+`
+
+const ipDecl = `
+// IP is a Postgres inet address, scanned from its text form; the prefix
+// length of a network, e.g. 10.0.0.0/8, is dropped.
+type IP net.IP
+
+// Scan implements sql.Scanner.
+func (ip *IP) Scan(src interface{}) error {
+	if src == nil {
+		*ip = nil
+		return nil
+	}
+	s, err := dalgenScanText("IP", src)
+	if err != nil {
+		return err
+	}
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		s = s[:i]
+	}
+	parsed := net.ParseIP(s)
+	if parsed == nil {
+		return fmt.Errorf("IP: bad address %q", s)
+	}
+	*ip = IP(parsed)
+	return nil
+}
+
+// Value implements driver.Valuer, writing NULL for a nil IP.
+func (ip IP) Value() (driver.Value, error) {
+	if ip == nil {
+		return nil, nil
+	}
+	return net.IP(ip).String(), nil
+}
+
+// String returns the text form of the address.
+func (ip IP) String() string {
+	return net.IP(ip).String()
+}
+
+// MarshalText implements encoding.TextMarshaler, for JSON.
+func (ip IP) MarshalText() ([]byte, error) {
+	return net.IP(ip).MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (ip *IP) UnmarshalText(text []byte) error {
+	return (*net.IP)(ip).UnmarshalText(text)
+}
+`
+
+const ipNetDecl = `
+// IPNet is a Postgres cidr network, scanned from its text form, e.g.
+// 10.0.0.0/8.
+type IPNet net.IPNet
+
+// Scan implements sql.Scanner.
+func (n *IPNet) Scan(src interface{}) error {
+	if src == nil {
+		*n = IPNet{}
+		return nil
+	}
+	s, err := dalgenScanText("IPNet", src)
+	if err != nil {
+		return err
+	}
+	_, parsed, err := net.ParseCIDR(s)
+	if err != nil {
+		return fmt.Errorf("IPNet: %v", err)
+	}
+	*n = IPNet(*parsed)
+	return nil
+}
+
+// Value implements driver.Valuer, writing NULL for the zero IPNet.
+func (n IPNet) Value() (driver.Value, error) {
+	if n.IP == nil {
+		return nil, nil
+	}
+	return n.String(), nil
+}
+
+// String returns the text form of the network.
+func (n IPNet) String() string {
+	ipNet := net.IPNet(n)
+	return ipNet.String()
+}
+
+// MarshalText implements encoding.TextMarshaler, for JSON.
+func (n IPNet) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (n *IPNet) UnmarshalText(text []byte) error {
+	return n.Scan(text)
+}
+`
+
+const macDecl = `
+// MAC is a Postgres macaddr, scanned from its text form, e.g.
+// 08:00:2b:01:02:03.
+type MAC net.HardwareAddr
+
+// Scan implements sql.Scanner.
+func (m *MAC) Scan(src interface{}) error {
+	if src == nil {
+		*m = nil
+		return nil
+	}
+	s, err := dalgenScanText("MAC", src)
+	if err != nil {
+		return err
+	}
+	parsed, err := net.ParseMAC(s)
+	if err != nil {
+		return fmt.Errorf("MAC: %v", err)
+	}
+	*m = MAC(parsed)
+	return nil
+}
+
+// Value implements driver.Valuer, writing NULL for a nil MAC.
+func (m MAC) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return m.String(), nil
+}
+
+// String returns the text form of the address.
+func (m MAC) String() string {
+	return net.HardwareAddr(m).String()
+}
+
+// MarshalText implements encoding.TextMarshaler, for JSON.
+func (m MAC) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *MAC) UnmarshalText(text []byte) error {
+	return m.Scan(text)
+}
+`
+
+// pgArrayGoType returns the Go type of a Postgres array column, the lib/pq
+// array of its element type; the arrays of the other types, e.g. date[],
+// scan into a pq.StringArray of their text form, and multidimensional
+// arrays into a string.
+func pgArrayGoType(sqlType string) string {
+	if strings.Count(sqlType, "[]") > 1 {
+		return "string"
+	}
+	switch strings.TrimSuffix(sqlType, "[]") {
+	case "bigint", "int8", "int", "integer", "int4", "smallint", "int2":
+		return "pq.Int64Array"
+	case "float", "double", "float8", "float4", "real", "decimal", "numeric":
+		return "pq.Float64Array"
+	case "bool", "boolean":
+		return "pq.BoolArray"
+	case "bytea":
+		return "pq.ByteaArray"
+	}
+	return "pq.StringArray"
+}
+
+// columnDBType returns the gorm type of the columns whose Go type does not
+// tell, the time and money columns, the Postgres network, interval and
+// array columns, the MySQL spatial columns and the TiDB AUTO_RANDOM
+// columns, "" for the other columns.
+func columnDBType(c *sqlparser.ColumnDefinition) string {
+	if t := autoRandomDBType(c); t != "" {
+		return t
+	}
+	if strings.HasSuffix(c.Type.Type, "[]") {
+		// undo pgTypeName
+		return strings.Replace(c.Type.Type, "double[", "double precision[", 1)
+	}
+	switch c.Type.Type {
+	case "inet", "cidr", "macaddr", "interval", "time", "timetz", "money",
+		"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection":
+		return c.Type.Type
+	}
+	return ""
+}
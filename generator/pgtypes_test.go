@@ -0,0 +1,226 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const intervalSchema = `CREATE TABLE public.jobs (
+    id bigint NOT NULL,
+    timeout interval NOT NULL,
+    backoff interval
+);
+ALTER TABLE ONLY public.jobs ADD CONSTRAINT jobs_pkey PRIMARY KEY (id);`
+
+const durationTest = `package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationScan(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		want time.Duration
+	}{
+		{"00:00:00", 0},
+		{"02:30:00", 2*time.Hour + 30*time.Minute},
+		{"-01:00:00.25", -time.Hour - 250*time.Millisecond},
+		{"1 day 02:00:00", 26 * time.Hour},
+		{"3 days", 72 * time.Hour},
+		{"1 mon -1 days", 29 * 24 * time.Hour},
+		{"1 year 2 mons", 8766*time.Hour + 60*24*time.Hour},
+		{"-2 days +03:00:00", -45 * time.Hour},
+	} {
+		for _, src := range []interface{}{tt.text, []byte(tt.text)} {
+			var d Duration
+			if err := d.Scan(src); err != nil {
+				t.Errorf("%q: %v", tt.text, err)
+			} else if time.Duration(d) != tt.want {
+				t.Errorf("%q: got %v, want %v", tt.text, time.Duration(d), tt.want)
+			}
+		}
+	}
+	var d Duration
+	if err := d.Scan("1 fortnight"); err == nil {
+		t.Error("scanned an unknown unit")
+	}
+	if err := d.Scan(int64(5)); err == nil {
+		t.Error("scanned an int64")
+	}
+}
+
+func TestDurationRoundTrip(t *testing.T) {
+	for _, want := range []time.Duration{
+		0,
+		time.Second,
+		90 * time.Minute,
+		49*time.Hour + 5*time.Second + 1500*time.Microsecond,
+		-(3*time.Hour + time.Nanosecond*1000),
+	} {
+		v, err := Duration(want).Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Duration
+		if err := got.Scan(v); err != nil {
+			t.Fatalf("%v: %v", v, err)
+		}
+		if time.Duration(got) != want {
+			t.Errorf("%v: got %v, want %v", v, time.Duration(got), want)
+		}
+	}
+	if v, _ := Duration(26 * time.Hour).Value(); v != "26:00:00" {
+		t.Errorf("got %v, want 26:00:00", v)
+	}
+}
+
+func TestJobsModel(t *testing.T) {
+	var j Jobs
+	if err := j.Timeout.Scan("00:00:30"); err != nil || time.Duration(j.Timeout) != 30*time.Second {
+		t.Errorf("got %v, %v", j.Timeout, err)
+	}
+}
+`
+
+func TestIntervalDuration(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", intervalSchema)
+	if err := run("-dialect", "postgres", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	jobs := readFile(t, modelPath("jobs"))
+	if !strings.Contains(jobs, "Timeout Duration") {
+		t.Errorf("jobs.timeout is not a Duration:\n%s", jobs)
+	}
+	types := readFile(t, "model/dalgen_pgtypes.go")
+	if !strings.Contains(types, "type Duration time.Duration") {
+		t.Errorf("Duration not declared:\n%s", types)
+	}
+	writeFile(t, "model/duration_test.go", durationTest)
+	goTest(t, "./model")
+
+	// -interval-string keeps the text
+	chdir(t)
+	writeFile(t, "schema.sql", intervalSchema)
+	if err := run("-dialect", "postgres", "-interval-string", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if jobs := readFile(t, modelPath("jobs")); !strings.Contains(jobs, "Timeout string") {
+		t.Errorf("jobs.timeout is not a string with -interval-string:\n%s", jobs)
+	}
+	if _, err := os.Stat("model/dalgen_pgtypes.go"); err == nil {
+		t.Error("dalgen_pgtypes.go written with -interval-string")
+	}
+}
+
+func TestIntervalSingleFile(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", intervalSchema)
+	if err := run("-dialect", "postgres", "-single-file", "models.go", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	models := gofmt(t, readFile(t, "model/models.go"))
+	if !strings.Contains(models, "type Duration time.Duration") {
+		t.Errorf("Duration not declared in the single file:\n%s", models)
+	}
+	if _, err := os.Stat("model/dalgen_pgtypes.go"); err == nil {
+		t.Error("dalgen_pgtypes.go written with -single-file")
+	}
+}
+
+const netSchema = `CREATE TABLE public.hosts (
+    id bigint NOT NULL,
+    addr inet NOT NULL,
+    network cidr,
+    mac macaddr
+);
+ALTER TABLE ONLY public.hosts ADD CONSTRAINT hosts_pkey PRIMARY KEY (id);`
+
+const netTest = `package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+)
+
+type valueScanner interface {
+	driver.Valuer
+	Scan(src interface{}) error
+}
+
+func TestNetScanValue(t *testing.T) {
+	for _, tt := range []struct {
+		scan func() valueScanner
+		text string
+		want string
+	}{
+		{func() valueScanner { return new(IP) }, "192.168.0.1", "192.168.0.1"},
+		{func() valueScanner { return new(IP) }, "2001:db8::1", "2001:db8::1"},
+		{func() valueScanner { return new(IP) }, "10.1.2.3/8", "10.1.2.3"},
+		{func() valueScanner { return new(IPNet) }, "10.0.0.0/8", "10.0.0.0/8"},
+		{func() valueScanner { return new(IPNet) }, "2001:db8::/32", "2001:db8::/32"},
+		{func() valueScanner { return new(MAC) }, "08:00:2b:01:02:03", "08:00:2b:01:02:03"},
+	} {
+		for _, src := range []interface{}{tt.text, []byte(tt.text)} {
+			v := tt.scan()
+			if err := v.Scan(src); err != nil {
+				t.Errorf("%q: %v", tt.text, err)
+				continue
+			}
+			got, err := v.Value()
+			if err != nil || got != tt.want {
+				t.Errorf("%q: got %v, %v, want %s", tt.text, got, err, tt.want)
+			}
+		}
+	}
+	for _, v := range []valueScanner{new(IP), new(IPNet), new(MAC)} {
+		if err := v.Scan("nonsense"); err == nil {
+			t.Errorf("%T scanned nonsense", v)
+		}
+		if err := v.Scan(nil); err != nil {
+			t.Errorf("%T: %v", v, err)
+		}
+		if got, err := v.Value(); got != nil || err != nil {
+			t.Errorf("%T: NULL written as %v, %v", v, got, err)
+		}
+	}
+}
+
+func TestHostsJSON(t *testing.T) {
+	var h Hosts
+	h.Addr.Scan("192.168.0.1")
+	h.Network.Scan("10.0.0.0/8")
+	h.Mac.Scan("08:00:2b:01:02:03")
+	b, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Hosts
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatalf("%s: %v", b, err)
+	}
+	if back.Addr.String() != "192.168.0.1" || back.Network.String() != "10.0.0.0/8" || back.Mac.String() != "08:00:2b:01:02:03" {
+		t.Errorf("%s read back as %+v", b, back)
+	}
+}
+`
+
+func TestNetTypes(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", netSchema)
+	if err := run("-dialect", "postgres", "-net-types", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	hosts := gofmt(t, readFile(t, modelPath("hosts")))
+	for _, want := range []string{"Addr    IP", "Network IPNet", "Mac     MAC"} {
+		if !strings.Contains(hosts, want) {
+			t.Errorf("no %q in:\n%s", want, hosts)
+		}
+	}
+	writeFile(t, "model/net_test.go", netTest)
+	goTest(t, "./model")
+}
@@ -0,0 +1,49 @@
+package generator
+
+import "strings"
+
+// pgTypeSpellings are the multi-word Postgres type spellings the models do
+// not know, by their first word, with their single-word equivalents.
+var pgTypeSpellings = map[string]struct {
+	rest []string
+	repl string
+}{
+	"character": {[]string{"varying"}, "varchar"},
+	"double":    {[]string{"precision"}, "double"},
+}
+
+// pgTypeName returns the single-word name of the type typ starts, reading
+// the rest of its spelling, so that GoType maps character varying to
+// string and double precision to float64. Only the type of a column is
+// rewritten, not the identifiers, comments and expressions spelled alike.
+func pgTypeName(p *pgParser, typ pgToken) string {
+	if typ.Kind != pgIdent {
+		return typ.Text
+	}
+	name := strings.ToLower(typ.Text)
+	if s, ok := pgTypeSpellings[name]; ok && p.accept(s.rest...) {
+		return s.repl
+	}
+	if name == "character" {
+		return "char"
+	}
+	return typ.Text
+}
+
+// pgTimeZone reads the WITH or WITHOUT TIME ZONE following the precision
+// of a timestamp or time, returning the type: timestamp either way, which
+// maps to time.Time, and timetz for a time with time zone.
+func pgTimeZone(p *pgParser, typ string) string {
+	switch strings.ToLower(typ) {
+	case "timestamp":
+		if !p.accept("with", "time", "zone") {
+			p.accept("without", "time", "zone")
+		}
+	case "time":
+		if p.accept("with", "time", "zone") {
+			return "timetz"
+		}
+		p.accept("without", "time", "zone")
+	}
+	return typ
+}
@@ -0,0 +1,34 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostgresTypeSpellings(t *testing.T) {
+	schema := `CREATE TABLE items (
+    id bigint PRIMARY KEY,
+    "character" integer,
+    name character varying(64) NOT NULL,
+    code character(3) NOT NULL,
+    price double precision DEFAULT (0)::double precision NOT NULL,
+    created_at timestamp(3) with time zone NOT NULL,
+    CONSTRAINT price_check CHECK ((price >= (0)::double precision))
+);
+COMMENT ON COLUMN items.name IS 'a character varying kind';`
+	got := generate(t, schema, "items", "-dialect", "postgres")
+	for _, want := range []string{
+		"Character int ",
+		"`gorm:\"Column:character\" json:\"character\"`",
+		"Name      string ",
+		"Code      string ",
+		"Price     float64 ",
+		"CreatedAt time.Time ",
+		"// a character varying kind",
+		"price >= (0)::double precision",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("items.go lacks %q:\n%s", want, got)
+		}
+	}
+}
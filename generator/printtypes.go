@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// builtinSQLTypes lists the SQL types GoType maps, in the order -print-types
+// prints them. Keep it in sync with the switch of GoType.
+var builtinSQLTypes = []string{
+	"bigint", "int8", "int", "integer", "int4", "smallint", "int2", "tinyint", "bit",
+	"bool", "boolean",
+	"float", "double", "float8", "float4", "real", "decimal", "numeric",
+	"char", "varchar", "tinytext", "text", "mediumtext", "longtext",
+	"binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bytea",
+	"date", "datetime", "timestamp", "timestamptz", "time", "timetz",
+	"money",
+	"json", "jsonb", "uuid",
+	"inet", "cidr", "macaddr", "interval",
+	"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection",
+}
+
+func integerSQLType(t string) bool {
+	switch t {
+	case "bigint", "int", "smallint", "tinyint", "int8", "integer", "int4", "int2":
+		return true
+	}
+	return false
+}
+
+// printTypes writes the effective SQL to Go type mapping of the current
+// -dialect and -type-map, as used for the models, followed by the
+// -type-map pattern and column overrides. The NULLABLE column is the type of
+// the model field of a column that may be NULL, which differs with
+// -nullable-pointers; the overrides keep their type.
+func printTypes(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "SQL TYPE\tGO TYPE\tNULLABLE\tSOURCE\n")
+	row := func(sqlType string, unsigned bool) {
+		c := &sqlparser.ColumnDefinition{Type: sqlparser.ColumnType{Type: sqlType, Unsigned: sqlparser.BoolVal(unsigned)}}
+		source := "builtin"
+		if o, ok := typeMap.Types[sqlType]; ok && o.Type != "" {
+			columnOverrides[c] = o
+			source = "type-map"
+		}
+		goType := GoType(c)
+		nullable := goType
+		if source == "builtin" {
+			nullable = nullableGoType(goType)
+		}
+		delete(columnOverrides, c)
+		name := sqlType
+		if unsigned {
+			name += " unsigned"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, goType, nullable, source)
+	}
+	builtin := make(map[string]bool)
+	for _, t := range builtinSQLTypes {
+		builtin[t] = true
+		row(t, false)
+		if integerSQLType(t) {
+			row(t, true)
+		}
+	}
+	var extra []string
+	for t, o := range typeMap.Types {
+		if !builtin[t] && o.Type != "" {
+			extra = append(extra, t)
+		}
+	}
+	sort.Strings(extra)
+	for _, t := range extra {
+		row(t, false)
+	}
+	fmt.Fprintf(tw, "enum\t<Table><Column>\t%s\tbuiltin\n", nullableGoType("<Table><Column>"))
+	fmt.Fprintf(tw, "set\t<Table><Column>\t<Table><Column>\tbuiltin\n")
+
+	for _, p := range typeMap.Patterns {
+		if p.Type != "" {
+			fmt.Fprintf(tw, "/%s/\t%s\t%s\t%s\n", p.Pattern, p.Type, p.Type, "type-map pattern")
+		}
+	}
+
+	var columns []string
+	for name, o := range typeMap.Columns {
+		if o.Type != "" {
+			columns = append(columns, name)
+		}
+	}
+	sort.Strings(columns)
+	for _, name := range columns {
+		t := typeMap.Columns[name].Type
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, t, t, "type-map column")
+	}
+	tw.Flush()
+}
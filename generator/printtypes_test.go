@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// printedTypes returns the GO TYPE and NULLABLE columns -print-types prints
+// for the SQL types, with the flags given.
+func printedTypes(t *testing.T, args ...string) map[string][2]string {
+	t.Helper()
+	reset()
+	if _, err := parseArgs(args); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	printTypes(&buf)
+	types := make(map[string][2]string)
+	for _, line := range strings.Split(buf.String(), "\n")[1:] {
+		// the SQL type may be two words, e.g. bigint unsigned
+		fields := strings.Fields(line)
+		if len(fields) >= 4 {
+			n := len(fields)
+			types[strings.Join(fields[:n-3], " ")] = [2]string{fields[n-3], fields[n-2]}
+		}
+	}
+	return types
+}
+
+func TestPrintTypes(t *testing.T) {
+	types := printedTypes(t)
+	for sqlType, want := range map[string][2]string{
+		"bigint":  {"int64", "int64"},
+		"varchar": {"string", "string"},
+		"json":    {"json.RawMessage", "json.RawMessage"},
+		"blob":    {"[]byte", "[]byte"},
+	} {
+		if got := types[sqlType]; got != want {
+			t.Errorf("%s: got %q, want %q", sqlType, got, want)
+		}
+	}
+}
+
+// TestPrintTypesNullable checks that the NULLABLE column is the field type
+// the models get for the nullable columns.
+func TestPrintTypesNullable(t *testing.T) {
+	types := printedTypes(t, "-nullable-pointers")
+	users := generate(t, `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  age int,
+  nickname varchar(64),
+  avatar blob,
+  born_at datetime,
+  payload json,
+  mood enum('happy','sad'),
+  PRIMARY KEY (id)
+);`, "users", "-nullable-pointers")
+	for _, tt := range []struct {
+		sqlType, field string
+	}{
+		{"int", "Age"},
+		{"varchar", "Nickname"},
+		{"blob", "Avatar"},
+		{"datetime", "BornAt"},
+		{"json", "Payload"},
+		{"enum", "Mood"},
+	} {
+		nullable := strings.Replace(types[tt.sqlType][1], "<Table><Column>", "UsersMood", 1)
+		if got := fieldType(users, tt.field); got != nullable {
+			t.Errorf("%s: print-types says %s, the model has %s", tt.sqlType, nullable, got)
+		}
+	}
+	if got := types["int"]; got != [2]string{"int", "*int"} {
+		t.Errorf("int: got %q, want int and *int", got)
+	}
+}
+
+// fieldType returns the type of a field of the struct in src.
+func fieldType(src, field string) string {
+	for _, line := range strings.Split(src, "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && fields[0] == field {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+func TestPrintTypesDialect(t *testing.T) {
+	mysql := printedTypes(t)
+	postgres := printedTypes(t, "-dialect", "postgres")
+	if got := mysql["real"][0]; got != "float64" {
+		t.Errorf("mysql real: got %s, want float64", got)
+	}
+	if got := postgres["real"][0]; got != "float32" {
+		t.Errorf("postgres real: got %s, want float32", got)
+	}
+	// the unsigned variants of the integer types are listed too
+	for _, sqlType := range []string{"bigint unsigned", "int unsigned", "tinyint unsigned"} {
+		if _, ok := mysql[sqlType]; !ok {
+			t.Errorf("%s not printed", sqlType)
+		}
+	}
+	if _, ok := mysql["varchar unsigned"]; ok {
+		t.Error("varchar unsigned printed")
+	}
+}
+
+func TestPrintTypesTypeMap(t *testing.T) {
+	chdir(t)
+	writeFile(t, "types.json", `{
+  "types": {"decimal": {"type": "decimal.Decimal"}, "citext": {"type": "string"}},
+  "patterns": [{"pattern": "_cents$", "type": "int64"}],
+  "columns": {"users.email": {"type": "Email"}, "users.note": {"tag": "size:64"}}
+}`)
+	reset()
+	if err := loadTypeMap("types.json"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	printTypes(&buf)
+	got := strings.Join(strings.Fields(buf.String()), " ")
+	for _, want := range []string{
+		"decimal decimal.Decimal decimal.Decimal type-map ",
+		"numeric float64 float64 builtin ",
+		"citext string string type-map ",
+		"/_cents$/ int64 int64 type-map pattern ",
+		"users.email Email Email type-map column",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("no %q in:\n%s", want, buf.String())
+		}
+	}
+	// a tag override keeps the type
+	if strings.Contains(got, "users.note") {
+		t.Errorf("users.note printed:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// isRenameTable reports whether stmt is a RENAME TABLE statement.
+func isRenameTable(stmt string) bool {
+	rest, ok := sqlKeyword(stmt, "rename")
+	if !ok {
+		return false
+	}
+	_, ok = sqlKeyword(rest, "table")
+	return ok
+}
+
+// applyRenameTable applies a RENAME TABLE a TO b[, c TO d] statement onto
+// the tables parsed so far, in order, so later statements refer to the new
+// names. An unknown table is reported like an ALTER TABLE of it.
+func applyRenameTable(ddls []*sqlparser.DDL, stmt string) error {
+	rest, _ := sqlKeyword(stmt, "rename", "table")
+	for _, pair := range splitTopLevel(rest) {
+		from, r := tableNameWord(pair)
+		r, ok := sqlKeyword(r, "to")
+		to, r := tableNameWord(r)
+		if !ok || to == "" || strings.TrimSpace(r) != "" {
+			return fmt.Errorf("rename table: cannot parse %q", strings.TrimSpace(pair))
+		}
+		var ddl *sqlparser.DDL
+		for _, d := range ddls {
+			if d.NewName.Name.String() == from {
+				ddl = d
+			}
+		}
+		if ddl == nil {
+			if err := alterConflict(fmt.Errorf("rename table %s: no such table", from)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := renameTable(ddls, ddl, to); err != nil {
+			return fmt.Errorf("rename table %s: %v", from, err)
+		}
+	}
+	return nil
+}
+
+// renameTable renames ddl to name, failing when another table of ddls
+// already has it.
+func renameTable(ddls []*sqlparser.DDL, ddl *sqlparser.DDL, name string) error {
+	if i := tableIndex(ddls, name); i >= 0 && ddls[i] != ddl {
+		return fmt.Errorf("table %s already exists", name)
+	}
+	ddl.NewName = sqlparser.TableName{Name: sqlparser.NewTableIdent(name)}
+	return nil
+}
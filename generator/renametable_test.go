@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const renameSchema = `CREATE TABLE old_orders (
+  id bigint NOT NULL AUTO_INCREMENT,
+  total int NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE TABLE old_items (
+  id bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (id)
+);
+RENAME TABLE old_orders TO orders, old_items TO items;
+ALTER TABLE orders ADD COLUMN note varchar(64);
+ALTER TABLE items RENAME TO order_items;
+ALTER TABLE order_items ADD COLUMN qty int NOT NULL;`
+
+func TestRenameTable(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", renameSchema)
+	if err := run("-strict", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	checkGenerated(t, map[string]bool{"old_orders": false, "old_items": false, "items": false, "orders": true, "order_items": true})
+	orders := gofmt(t, readFile(t, modelPath("orders")))
+	for _, want := range []string{"type Orders struct {", "Total int", "Note  string", `return "orders"`} {
+		if !strings.Contains(orders, want) {
+			t.Errorf("no %s in:\n%s", want, orders)
+		}
+	}
+	items := gofmt(t, readFile(t, modelPath("order_items")))
+	for _, want := range []string{"type OrderItems struct {", "Qty int", `return "order_items"`} {
+		if !strings.Contains(items, want) {
+			t.Errorf("no %s in:\n%s", want, items)
+		}
+	}
+}
+
+func TestRenameTableErrors(t *testing.T) {
+	const tables = "CREATE TABLE a (id int);\nCREATE TABLE b (id int);\n"
+	for _, tt := range []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{"rename to existing", tables + "RENAME TABLE a TO b;", "rename table a: table b already exists"},
+		{"alter rename to existing", tables + "ALTER TABLE a RENAME TO b;", "alter table a: table b already exists"},
+		{"alter old name", tables + "RENAME TABLE a TO c;\nALTER TABLE a ADD COLUMN x int;", "alter table a: no such table"},
+		{"unknown table", tables + "RENAME TABLE d TO e;", "rename table d: no such table"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t)
+			writeFile(t, "schema.sql", tt.sql)
+			err := run("-strict", "schema.sql")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+			if _, err := os.Stat("model"); err == nil {
+				t.Error("models written despite the error")
+			}
+		})
+	}
+}
@@ -0,0 +1,634 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// reverseModel is a struct dalgen reverse renders a CREATE TABLE for.
+type reverseModel struct {
+	Name  string
+	Table string
+	// TableName marks the models with a TableName method.
+	TableName bool
+	Struct    *ast.StructType
+}
+
+// reversePackage is the package dalgen reverse reads the models of.
+type reversePackage struct {
+	Models  []*reverseModel
+	structs map[string]*ast.StructType
+	// types holds the underlying type of the other named types, and enums
+	// the string constants declared of them.
+	types map[string]ast.Expr
+	enums map[string][]string
+}
+
+// reverse writes the CREATE TABLE statements of the gorm models of the Go
+// package of dir, e.g. ./model or an import path, to w.
+func reverse(dir string, w io.Writer) error {
+	if dialect != "mysql" && dialect != "auto" {
+		return fmt.Errorf("reverse writes MySQL DDL, not -dialect %s", dialect)
+	}
+	pkg, err := loadReversePackage(dir)
+	if err != nil {
+		return err
+	}
+	if len(pkg.Models) == 0 {
+		return fmt.Errorf("reverse: no struct of %s has a TableName method or gorm tags", dir)
+	}
+	for i, m := range pkg.Models {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprint(w, pkg.createTable(m))
+	}
+	return nil
+}
+
+func loadReversePackage(dir string) (*reversePackage, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	bp, err := build.Default.Import(dir, cwd, 0)
+	if err != nil {
+		return nil, fmt.Errorf("reverse: %v", err)
+	}
+	pkg := &reversePackage{
+		structs: make(map[string]*ast.StructType),
+		types:   make(map[string]ast.Expr),
+		enums:   make(map[string][]string),
+	}
+	fset := token.NewFileSet()
+	tableNames := make(map[string]string)
+	var order []string
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(bp.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("reverse: %v", err)
+		}
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				pkg.addDecl(decl, &order)
+			case *ast.FuncDecl:
+				if recv, table, ok := tableNameMethod(decl); ok {
+					tableNames[recv] = table
+				}
+			}
+		}
+	}
+	// the structs embedded in a model are its columns, not tables
+	embedded := make(map[string]bool)
+	for _, st := range pkg.structs {
+		for _, field := range st.Fields.List {
+			if id, ok := derefExpr(field.Type).(*ast.Ident); ok && len(field.Names) == 0 {
+				embedded[id.Name] = true
+			}
+		}
+	}
+	for _, name := range order {
+		st := pkg.structs[name]
+		table, ok := tableNames[name]
+		if !ok && (embedded[name] || !hasGormTags(st)) {
+			continue
+		}
+		m := &reverseModel{Name: name, Table: table, TableName: ok, Struct: st}
+		if table == "" {
+			m.Table = pluralSnake(name)
+			if ok {
+				fmt.Fprintf(os.Stderr, "warning: %s.TableName does not return a constant, using the table name %s\n", name, m.Table)
+			}
+		}
+		pkg.Models = append(pkg.Models, m)
+	}
+	return pkg, nil
+}
+
+// addDecl records the structs, the other named types and the typed string
+// constants of a declaration.
+func (pkg *reversePackage) addDecl(decl *ast.GenDecl, order *[]string) {
+	for _, spec := range decl.Specs {
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			if st, ok := spec.Type.(*ast.StructType); ok {
+				pkg.structs[spec.Name.Name] = st
+				*order = append(*order, spec.Name.Name)
+			} else {
+				pkg.types[spec.Name.Name] = spec.Type
+			}
+		case *ast.ValueSpec:
+			if decl.Tok != token.CONST {
+				continue
+			}
+			id, ok := spec.Type.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			for _, v := range spec.Values {
+				if lit, ok := v.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					if s, err := strconv.Unquote(lit.Value); err == nil {
+						pkg.enums[id.Name] = append(pkg.enums[id.Name], s)
+					}
+				}
+			}
+		}
+	}
+}
+
+// tableNameMethod returns the receiver and table of a TableName method
+// returning a constant, an empty table for one returning anything else.
+func tableNameMethod(fn *ast.FuncDecl) (recv, table string, ok bool) {
+	if fn.Name.Name != "TableName" || fn.Recv == nil || len(fn.Recv.List) != 1 {
+		return "", "", false
+	}
+	id, isIdent := derefExpr(fn.Recv.List[0].Type).(*ast.Ident)
+	if !isIdent {
+		return "", "", false
+	}
+	if fn.Body != nil && len(fn.Body.List) == 1 {
+		if ret, isRet := fn.Body.List[0].(*ast.ReturnStmt); isRet && len(ret.Results) == 1 {
+			if lit, isLit := ret.Results[0].(*ast.BasicLit); isLit && lit.Kind == token.STRING {
+				table, _ = strconv.Unquote(lit.Value)
+			}
+		}
+	}
+	return id.Name, table, true
+}
+
+func derefExpr(e ast.Expr) ast.Expr {
+	if star, ok := e.(*ast.StarExpr); ok {
+		return star.X
+	}
+	return e
+}
+
+func hasGormTags(st *ast.StructType) bool {
+	for _, field := range st.Fields.List {
+		if _, ok := fieldTag(field).Lookup("gorm"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, _ := strconv.Unquote(field.Tag.Value)
+	return reflect.StructTag(tag)
+}
+
+// parseGormTag splits a gorm tag into its settings by upper-cased name, as
+// gorm does, along with the names in order.
+func parseGormTag(tag string) (map[string]string, []string) {
+	settings := make(map[string]string)
+	var names []string
+	var parts []string
+	for _, p := range strings.Split(tag, ";") {
+		if n := len(parts); n > 0 && strings.HasSuffix(parts[n-1], `\`) {
+			parts[n-1] = strings.TrimSuffix(parts[n-1], `\`) + ";" + p
+			continue
+		}
+		parts = append(parts, p)
+	}
+	for _, p := range parts {
+		kv := strings.SplitN(p, ":", 2)
+		name := strings.ToUpper(strings.TrimSpace(kv[0]))
+		if name == "" {
+			continue
+		}
+		value := ""
+		if len(kv) == 2 {
+			value = kv[1]
+		} else {
+			value = name
+		}
+		if _, ok := settings[name]; !ok {
+			names = append(names, name)
+		}
+		settings[name] = value
+	}
+	return settings, names
+}
+
+// reverseColumn is a column of a model, or the placeholder of a field
+// whose Go type has no SQL type.
+type reverseColumn struct {
+	Name        string
+	Field       string
+	Placeholder string
+	Type        string
+	NotNull     bool
+	// Options follow NOT NULL in the definition: the default, UNIQUE and
+	// the comment.
+	Options    []string
+	PrimaryKey bool
+	settings   map[string]string
+	names      []string
+}
+
+// reverseIndex is an index gathered from the index and uniqueIndex tags.
+type reverseIndex struct {
+	Name    string
+	Unique  bool
+	Class   string
+	columns []reverseIndexColumn
+}
+
+type reverseIndexColumn struct {
+	Name     string
+	Priority int
+	Sort     string
+}
+
+// columns flattens the fields of a model into its columns, expanding the
+// embedded structs and gorm.Model and leaving out the associations.
+func (pkg *reversePackage) columns(st *ast.StructType, prefix string, seen map[string]bool) []*reverseColumn {
+	var cols []*reverseColumn
+	for _, field := range st.Fields.List {
+		settings, names := parseGormTag(fieldTag(field).Get("gorm"))
+		if hasSetting(settings, "-") {
+			continue
+		}
+		if settings["FOREIGNKEY"] != "" || settings["MANY2MANY"] != "" || settings["REFERENCES"] != "" {
+			continue
+		}
+		typ := derefExpr(field.Type)
+		if len(field.Names) == 0 || hasSetting(settings, "EMBEDDED") {
+			if sel, ok := typ.(*ast.SelectorExpr); ok && sel.Sel.Name == "Model" && isPackage(sel.X, "gorm") {
+				cols = append(cols, gormModelColumns(prefix)...)
+				continue
+			}
+			if id, ok := typ.(*ast.Ident); ok && pkg.structs[id.Name] != nil && !seen[id.Name] {
+				seen[id.Name] = true
+				cols = append(cols, pkg.columns(pkg.structs[id.Name], prefix+settings["EMBEDDEDPREFIX"], seen)...)
+				delete(seen, id.Name)
+				continue
+			}
+			if len(field.Names) == 0 {
+				cols = append(cols, &reverseColumn{
+					Field:       types.ExprString(field.Type),
+					Placeholder: fmt.Sprintf("embedded %s: no columns for the embedded type", types.ExprString(field.Type)),
+				})
+				continue
+			}
+		}
+		if pkg.association(field.Type) {
+			continue
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			col := &reverseColumn{Field: name.Name, settings: settings, names: names}
+			col.Name = prefix + snakeCase(name.Name)
+			if c := strings.TrimSpace(settings["COLUMN"]); c != "" {
+				col.Name = c
+			}
+			comment := strings.Trim(settings["COMMENT"], "'")
+			if comment == "" && field.Comment != nil {
+				comment = strings.TrimSpace(field.Comment.Text())
+			}
+			col.Type, col.NotNull, col.Options, col.Placeholder = pkg.columnDefinition(field.Type, settings, comment)
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+func hasSetting(settings map[string]string, name string) bool {
+	_, ok := settings[name]
+	return ok
+}
+
+func isPackage(e ast.Expr, name string) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == name
+}
+
+// association reports whether a field holds other models, a belongs-to,
+// has-one or has-many association rather than a column.
+func (pkg *reversePackage) association(e ast.Expr) bool {
+	e = derefExpr(e)
+	if arr, ok := e.(*ast.ArrayType); ok {
+		e = derefExpr(arr.Elt)
+	}
+	id, ok := e.(*ast.Ident)
+	return ok && pkg.structs[id.Name] != nil
+}
+
+// gormModelColumns are the columns gorm.Model embeds.
+func gormModelColumns(prefix string) []*reverseColumn {
+	return []*reverseColumn{
+		{Name: prefix + "id", Field: "ID", Type: "bigint unsigned", NotNull: true, PrimaryKey: true},
+		{Name: prefix + "created_at", Field: "CreatedAt", Type: "datetime(3)"},
+		{Name: prefix + "updated_at", Field: "UpdatedAt", Type: "datetime(3)"},
+		{Name: prefix + "deleted_at", Field: "DeletedAt", Type: "datetime(3)",
+			settings: map[string]string{"INDEX": "INDEX"}, names: []string{"INDEX"}},
+	}
+}
+
+// reverseTypes inverts the type mapping of GoType for the types it maps
+// to, and the common database/sql, gorm and driver types. The nullable
+// types are NULL even when not a pointer.
+var reverseTypes = map[string]struct {
+	SQL      string
+	Nullable bool
+}{
+	"int":                 {"int", false},
+	"int8":                {"tinyint", false},
+	"int16":               {"smallint", false},
+	"int32":               {"int", false},
+	"int64":               {"bigint", false},
+	"uint":                {"int unsigned", false},
+	"uint8":               {"tinyint unsigned", false},
+	"uint16":              {"smallint unsigned", false},
+	"uint32":              {"int unsigned", false},
+	"uint64":              {"bigint unsigned", false},
+	"bool":                {"tinyint(1)", false},
+	"string":              {"varchar(255)", false},
+	"float32":             {"float", false},
+	"float64":             {"double", false},
+	"[]byte":              {"blob", false},
+	"time.Time":           {"datetime", false},
+	"json.RawMessage":     {"json", false},
+	"datatypes.JSON":      {"json", false},
+	"uuid.UUID":           {"char(36)", false},
+	"sql.NullString":      {"varchar(255)", true},
+	"sql.NullInt64":       {"bigint", true},
+	"sql.NullInt32":       {"int", true},
+	"sql.NullInt16":       {"smallint", true},
+	"sql.NullByte":        {"tinyint unsigned", true},
+	"sql.NullBool":        {"tinyint(1)", true},
+	"sql.NullFloat64":     {"double", true},
+	"sql.NullTime":        {"datetime", true},
+	"gorm.DeletedAt":      {"datetime(3)", true},
+	"decimal.Decimal":     {"decimal(20,6)", false},
+	"decimal.NullDecimal": {"decimal(20,6)", true},
+}
+
+// columnDefinition returns the SQL type and options of a column of Go type
+// e, or the reason it has none.
+func (pkg *reversePackage) columnDefinition(e ast.Expr, settings map[string]string, comment string) (sqlType string, notNull bool, options []string, placeholder string) {
+	nullable := false
+	if star, ok := e.(*ast.StarExpr); ok {
+		nullable = true
+		e = star.X
+	}
+	goType := types.ExprString(e)
+	sqlType = strings.TrimSpace(settings["TYPE"])
+	var enum []string
+	if sqlType == "" {
+		resolved := goType
+		// a named type of the package maps as its underlying type, a string
+		// type with constants as an enum of them
+		for i := 0; i < 10; i++ {
+			if labels := pkg.enums[resolved]; len(labels) > 0 && i == 0 {
+				enum = labels
+			}
+			u, ok := pkg.types[resolved]
+			if !ok {
+				break
+			}
+			resolved = types.ExprString(u)
+		}
+		t, ok := reverseTypes[resolved]
+		if !ok {
+			return "", false, nil, fmt.Sprintf("no SQL type for the Go type %s", goType)
+		}
+		sqlType = t.SQL
+		nullable = nullable || t.Nullable
+		switch {
+		case enum != nil && resolved == "string":
+			quoted := make([]string, 0, len(enum))
+			for _, l := range enum {
+				quoted = append(quoted, sqlString(l))
+			}
+			sqlType = "enum(" + strings.Join(quoted, ",") + ")"
+		case resolved == "string" || resolved == "sql.NullString":
+			if size, err := strconv.Atoi(settings["SIZE"]); err == nil && size > 0 {
+				if size > 16383 {
+					sqlType = "text"
+				} else {
+					sqlType = fmt.Sprintf("varchar(%d)", size)
+				}
+			}
+		case strings.HasPrefix(sqlType, "decimal") || sqlType == "double" || sqlType == "float":
+			// gorm makes a decimal of the floats with a precision
+			if p := settings["PRECISION"]; p != "" {
+				s := settings["SCALE"]
+				if s == "" {
+					s = "0"
+				}
+				sqlType = "decimal(" + p + "," + s + ")"
+			}
+		case sqlType == "datetime" || sqlType == "datetime(3)":
+			if p := settings["PRECISION"]; p != "" {
+				sqlType = "datetime(" + p + ")"
+			}
+		}
+	}
+	notNull = hasSetting(settings, "NOT NULL") || !nullable
+	switch {
+	case hasSetting(settings, "AUTOUPDATETIME"):
+		options = append(options, "DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP")
+	case hasSetting(settings, "AUTOCREATETIME"):
+		options = append(options, "DEFAULT CURRENT_TIMESTAMP")
+	case hasSetting(settings, "DEFAULT"):
+		options = append(options, "DEFAULT "+defaultSQL(settings["DEFAULT"]))
+	}
+	if hasSetting(settings, "UNIQUE") {
+		options = append(options, "UNIQUE")
+	}
+	if comment != "" {
+		options = append(options, "COMMENT "+sqlString(comment))
+	}
+	return sqlType, notNull, options, ""
+}
+
+var defaultKeyword = regexp.MustCompile(`(?i)^(null|true|false|current_timestamp(\(\d*\))?|-?\d+(\.\d+)?)$`)
+
+// defaultSQL renders a gorm default tag value as an SQL literal, quoting
+// the bare strings.
+func defaultSQL(v string) string {
+	v = strings.TrimSpace(v)
+	if defaultKeyword.MatchString(v) || strings.HasPrefix(v, "'") || strings.HasPrefix(v, "(") {
+		return v
+	}
+	return sqlString(v)
+}
+
+// createTable renders the CREATE TABLE statement of a model.
+func (pkg *reversePackage) createTable(m *reverseModel) string {
+	cols := pkg.columns(m.Struct, "", map[string]bool{m.Name: true})
+	var pk []string
+	for _, c := range cols {
+		if hasSetting(c.settings, "PRIMARYKEY") || hasSetting(c.settings, "PRIMARY_KEY") {
+			c.PrimaryKey = true
+		}
+	}
+	hasPK := false
+	for _, c := range cols {
+		hasPK = hasPK || c.PrimaryKey
+	}
+	for _, c := range cols {
+		// gorm's convention: the ID field is the primary key
+		if !hasPK && c.Field == "ID" && c.Placeholder == "" {
+			c.PrimaryKey = true
+		}
+		if c.PrimaryKey {
+			pk = append(pk, quoteIdent(c.Name))
+		}
+	}
+	var lines []string
+	defs := 0
+	for _, c := range cols {
+		if c.Placeholder != "" {
+			name := c.Name
+			if name == "" {
+				name = c.Field
+			}
+			lines = append(lines, "  -- "+name+": "+c.Placeholder)
+			continue
+		}
+		def := []string{quoteIdent(c.Name), c.Type}
+		if c.NotNull || c.PrimaryKey {
+			def = append(def, "NOT NULL")
+		}
+		// gorm auto-increments a lone integer primary key
+		autoIncrement, set := c.settings["AUTOINCREMENT"]
+		if set && !strings.EqualFold(autoIncrement, "false") ||
+			!set && c.PrimaryKey && len(pk) == 1 && integerSQLType(strings.ToLower(strings.Fields(c.Type)[0])) {
+			def = append(def, "AUTO_INCREMENT")
+		}
+		def = append(def, c.Options...)
+		lines = append(lines, "  "+strings.Join(def, " "))
+		defs++
+	}
+	var keys []string
+	if len(pk) > 0 {
+		keys = append(keys, "  PRIMARY KEY ("+strings.Join(pk, ",")+")")
+	}
+	for _, idx := range reverseIndexes(m.Table, cols) {
+		sort.SliceStable(idx.columns, func(i, j int) bool { return idx.columns[i].Priority < idx.columns[j].Priority })
+		var names []string
+		for _, c := range idx.columns {
+			names = append(names, quoteIdent(c.Name)+c.Sort)
+		}
+		kind := "KEY"
+		switch {
+		case idx.Unique:
+			kind = "UNIQUE KEY"
+		case idx.Class != "":
+			kind = idx.Class + " KEY"
+		}
+		keys = append(keys, "  "+kind+" "+quoteIdent(idx.Name)+" ("+strings.Join(names, ",")+")")
+	}
+	for _, c := range cols {
+		if check := c.settings["CHECK"]; check != "" {
+			if kv := strings.SplitN(check, ",", 2); len(kv) == 2 && !strings.ContainsAny(kv[0], " ()<>=") {
+				keys = append(keys, "  CONSTRAINT "+quoteIdent(kv[0])+" CHECK ("+kv[1]+")")
+			} else {
+				keys = append(keys, "  CHECK ("+check+")")
+			}
+		}
+	}
+	// the comma-separated definitions, with the placeholders kept where
+	// their fields are
+	var b strings.Builder
+	b.WriteString("CREATE TABLE " + quoteIdent(m.Table) + " (\n")
+	remaining := defs + len(keys)
+	for _, line := range append(lines, keys...) {
+		b.WriteString(line)
+		if !strings.HasPrefix(line, "  --") {
+			if remaining--; remaining > 0 {
+				b.WriteString(",")
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(");\n")
+	return b.String()
+}
+
+// reverseIndexes gathers the indexes of the index and uniqueIndex tags of
+// the columns, named as gorm names them when the tag does not.
+func reverseIndexes(table string, cols []*reverseColumn) []*reverseIndex {
+	var indexes []*reverseIndex
+	byName := make(map[string]*reverseIndex)
+	for _, c := range cols {
+		for _, name := range c.names {
+			if name != "INDEX" && name != "UNIQUEINDEX" {
+				continue
+			}
+			value := c.settings[name]
+			if value == name {
+				value = ""
+			}
+			opts := strings.Split(value, ",")
+			idxName := strings.TrimSpace(opts[0])
+			if idxName == "" || strings.Contains(idxName, ":") {
+				idxName = "idx_" + table + "_" + c.Name
+			} else {
+				opts = opts[1:]
+			}
+			idx := byName[idxName]
+			if idx == nil {
+				idx = &reverseIndex{Name: idxName, Unique: name == "UNIQUEINDEX"}
+				byName[idxName] = idx
+				indexes = append(indexes, idx)
+			}
+			col := reverseIndexColumn{Name: c.Name, Priority: 10}
+			for _, opt := range opts {
+				kv := strings.SplitN(strings.TrimSpace(opt), ":", 2)
+				key := strings.ToUpper(kv[0])
+				switch {
+				case key == "UNIQUE":
+					idx.Unique = true
+				case key == "CLASS" && len(kv) == 2:
+					idx.Class = strings.ToUpper(kv[1])
+				case key == "PRIORITY" && len(kv) == 2:
+					if p, err := strconv.Atoi(kv[1]); err == nil {
+						col.Priority = p
+					}
+				case key == "SORT" && len(kv) == 2:
+					col.Sort = " " + strings.ToUpper(kv[1])
+				}
+			}
+			if idx.Class == "UNIQUE" {
+				idx.Class, idx.Unique = "", true
+			}
+			idx.columns = append(idx.columns, col)
+		}
+	}
+	return indexes
+}
+
+// pluralSnake is the table name gorm gives a model without a TableName
+// method, the plural of its snake case name.
+func pluralSnake(name string) string {
+	s := snakeCase(name)
+	switch {
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsAny(s[len(s)-2:len(s)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	default:
+		return s + "s"
+	}
+}
@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReverseGolden compares the DDL reverse writes of the models of
+// testdata/reverse/model with testdata/reverse/schema.sql.golden.
+func TestReverseGolden(t *testing.T) {
+	reset()
+	dir := testdataPath(t, "reverse")
+	chdir(t)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := reverse("./model", &buf); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, filepath.Join(dir, "schema.sql.golden"), buf.String())
+
+	// the DDL parses back, the placeholder column left out
+	ddls, err := ParseSQLs(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []string
+	for _, ddl := range ddls {
+		tables = append(tables, ddl.NewName.Name.String())
+	}
+	if got := strings.Join(tables, " "); got != "addresses app_users orders" {
+		t.Fatalf("got tables %s", got)
+	}
+	var columns []string
+	for _, c := range ddls[1].TableSpec.Columns {
+		columns = append(columns, c.Name.String())
+	}
+	if got, want := strings.Join(columns, " "), "id email nickname status age balance bio phone addr_street addr_city created_at updated_at"; got != want {
+		t.Errorf("app_users has columns %s, want %s", got, want)
+	}
+}
+
+func TestReverseNoModels(t *testing.T) {
+	reset()
+	chdir(t)
+	writeFile(t, "plain/plain.go", "package plain\n\ntype Point struct{ X, Y int }\n")
+	err := reverse("./plain", &bytes.Buffer{})
+	if want := "reverse: no struct of ./plain has a TableName method or gorm tags"; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %s", err, want)
+	}
+}
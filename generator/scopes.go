@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const scopesTemplate = `
+package {{.Package}}
+
+import (
+{{- range std .Imports}}
+	"{{.}}"
+{{- end}}
+{{range thirdParty .Imports}}
+	"{{.}}"
+{{- end}}
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type {{.Type}} struct{}
+
+// {{.Var}} groups the query scopes of {{.TableName}}, for use with
+// {{.TableName}}DAO.List or gorm's Scopes.
+var {{.Var}} {{.Type}}
+{{range .Scopes}}
+func ({{$.Type}}) {{.Name}}({{.Params}}) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return {{.Body}}
+	}
+}
+{{end}}`
+
+type scope struct {
+	Name   string
+	Params string
+	Body   string
+}
+
+func betweenType(goType string) bool {
+	switch goType {
+	case "int", "int64", "uint64", "float32", "float64", "time.Time":
+		return true
+	}
+	return false
+}
+
+func genScopes(pkg string, ddl *sqlparser.DDL) string {
+	tableName := modelName(ddl.NewName.Name.String())
+
+	used := make(map[string]bool)
+	var scopes []scope
+	add := func(name, params, body string) {
+		// a column may camel-case into the name of another scope, e.g.
+		// created_at_between; suffix until the name is free
+		for used[name] {
+			name += "Column"
+		}
+		used[name] = true
+		scopes = append(scopes, scope{Name: name, Params: params, Body: body})
+	}
+	add("Limit", "n int", "db.Limit(n)")
+	add("Offset", "n int", "db.Offset(n)")
+
+	var types []string
+	for _, c := range ddl.TableSpec.Columns {
+		col := newDALColumn(c)
+		ref := `clause.Column{Name: "` + col.Name + `"}`
+		add("Where"+col.Field, "v "+col.Type, "db.Where(clause.Eq{Column: "+ref+", Value: v})")
+		types = append(types, col.Type)
+		if betweenType(col.Type) {
+			add("Where"+col.Field+"Between", "from, to "+col.Type,
+				"db.Where(clause.Gte{Column: "+ref+", Value: from}).Where(clause.Lte{Column: "+ref+", Value: to})")
+		}
+		add("OrderBy"+col.Field+"Asc", "", "db.Order(clause.OrderByColumn{Column: "+ref+"})")
+		add("OrderBy"+col.Field+"Desc", "", "db.Order(clause.OrderByColumn{Column: "+ref+", Desc: true})")
+	}
+
+	params := struct {
+		Package   string
+		TableName string
+		Type      string
+		Var       string
+		Imports   []string
+		Scopes    []scope
+	}{
+		Package:   pkg,
+		TableName: tableName,
+		Type:      lowerFirst(tableName) + "Scopes",
+		Var:       tableName + "Scopes",
+		Imports:   importsOf(types...),
+		Scopes:    scopes,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("scopes").Funcs(importFuncs).Parse(scopesTemplate)).Execute(&buf, params)
+	return buf.String()
+}
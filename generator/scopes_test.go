@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const scopesSchema = "CREATE TABLE `items` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `qty` int NOT NULL,\n" +
+	"  `status` varchar(16) NOT NULL,\n" +
+	"  `limit` int NOT NULL,\n" +
+	"  `created_at` datetime NOT NULL,\n" +
+	"  `created_at_between` varchar(16) NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");"
+
+const scopesTest = `package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var day = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func seed(t *testing.T) *ItemsDAO {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Items{}); err != nil {
+		t.Fatal(err)
+	}
+	dao := NewItemsDAO(db)
+	for i, m := range []Items{
+		{Qty: 1, Status: "new", Limit: 1},
+		{Qty: 2, Status: "new", Limit: 2},
+		{Qty: 2, Status: "paid", Limit: 3},
+		{Qty: 2, Status: "new", Limit: 4},
+		{Qty: 3, Status: "new", Limit: 5},
+	} {
+		m.CreatedAt = day.AddDate(0, 0, i)
+		if err := dao.Create(context.Background(), &m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dao
+}
+
+func ids(ms []*Items) []int64 {
+	var ids []int64
+	for _, m := range ms {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func check(t *testing.T, ms []*Items, err error, want ...int64) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ids(ms)
+	if len(got) != len(want) {
+		t.Fatalf("got ids %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got ids %v, want %v", got, want)
+		}
+	}
+}
+
+func TestComposedScopes(t *testing.T) {
+	dao := seed(t)
+	ctx := context.Background()
+	ms, err := dao.List(ctx, 10, 0, ItemsScopes.WhereQty(2), ItemsScopes.OrderByIDDesc())
+	check(t, ms, err, 4, 3, 2)
+	ms, err = dao.List(ctx, 10, 0, ItemsScopes.WhereQty(2), ItemsScopes.WhereStatus("new"))
+	check(t, ms, err, 2, 4)
+	ms, err = dao.List(ctx, 10, 0, ItemsScopes.WhereCreatedAtBetween(day.AddDate(0, 0, 1), day.AddDate(0, 0, 3)), ItemsScopes.OrderByQtyDesc(), ItemsScopes.OrderByIDAsc())
+	check(t, ms, err, 2, 3, 4)
+	ms, err = dao.List(ctx, 10, 0, ItemsScopes.WhereLimitBetween(2, 3))
+	check(t, ms, err, 2, 3)
+}
+
+func TestLimitOffsetScopes(t *testing.T) {
+	dao := seed(t)
+	// -1 lifts the limit and offset of List, leaving those of the scopes
+	ms, err := dao.List(context.Background(), -1, -1, ItemsScopes.OrderByIDDesc(), ItemsScopes.Limit(2), ItemsScopes.Offset(1))
+	check(t, ms, err, 4, 3)
+	var got []*Items
+	err = dao.db.Scopes(ItemsScopes.WhereLimit(5)).Find(&got).Error
+	check(t, got, err, 5)
+}
+`
+
+func TestScopes(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", scopesSchema)
+	if err := run("-gen-scopes", "-dal", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	scopes := gofmt(t, readFile(t, "model/items_scopes.go"))
+	for _, want := range []string{
+		"func (itemsScopes) Limit(n int) func(*gorm.DB) *gorm.DB {",
+		"func (itemsScopes) WhereLimit(v int) func(*gorm.DB) *gorm.DB {",
+		"func (itemsScopes) WhereCreatedAtBetween(from, to time.Time) func(*gorm.DB) *gorm.DB {",
+		// the column takes the name after the scope of created_at
+		"func (itemsScopes) WhereCreatedAtBetweenColumn(v string) func(*gorm.DB) *gorm.DB {",
+	} {
+		if !strings.Contains(scopes, want) {
+			t.Errorf("no %q in:\n%s", want, scopes)
+		}
+	}
+	writeFile(t, "model/scopes_test.go", scopesTest)
+	goTest(t, "./model")
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// shardAnnotation in a column comment marks its table as sharded by suffix on
+// that column, e.g. "dalgen:shard=64" for orders_0 … orders_63.
+const shardAnnotation = "dalgen:shard="
+
+const shardTemplate = `
+// {{.TableName}}ShardCount is the number of {{.TableNameStr}} shards.
+const {{.TableName}}ShardCount = {{.Count}}
+
+// ShardedTableName returns the name of the {{.TableNameStr}} shard holding
+// shardKey, which is the {{.Key.Name}} column.
+func ({{.TableName}}) ShardedTableName(shardKey int64) string {
+	return "{{.TableNameStr}}_" + strconv.FormatUint(uint64(shardKey)%{{.TableName}}ShardCount, 10)
+}
+`
+
+type shardSpec struct {
+	Key   dalColumn
+	Count int
+}
+
+// tableShard returns the sharding of a table, or nil when no integer column
+// carries a valid shardAnnotation.
+func tableShard(ddl *sqlparser.DDL) *shardSpec {
+	for _, c := range ddl.TableSpec.Columns {
+		comment := getComment(c)
+		i := strings.Index(comment, shardAnnotation)
+		if i < 0 {
+			continue
+		}
+		count := comment[i+len(shardAnnotation):]
+		if j := strings.IndexFunc(count, func(r rune) bool { return r < '0' || r > '9' }); j >= 0 {
+			count = count[:j]
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 1 {
+			continue
+		}
+		col := newDALColumn(c)
+		switch col.Type {
+		case "int", "int64", "uint64":
+			return &shardSpec{Key: col, Count: n}
+		}
+	}
+	return nil
+}
+
+func genShard(ddl *sqlparser.DDL) string {
+	s := tableShard(ddl)
+	if s == nil {
+		return ""
+	}
+	tableNameStr := ddl.NewName.Name.String()
+	params := struct {
+		TableName    string
+		TableNameStr string
+		Key          dalColumn
+		Count        int
+	}{
+		TableName:    modelName(tableNameStr),
+		TableNameStr: tablePrefix + tableNameStr + tableSuffix,
+		Key:          s.Key,
+		Count:        s.Count,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("shard").Parse(shardTemplate)).Execute(&buf, params)
+	return buf.String()
+}
@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const shardSchema = "CREATE TABLE `orders` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `user_id` bigint NOT NULL COMMENT 'dalgen:shard=4',\n" +
+	"  `status` varchar(16) NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");"
+
+const shardTest = `package model
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestShardedTableName(t *testing.T) {
+	for key, want := range map[int64]string{0: "orders_0", 3: "orders_3", 4: "orders_0", 63: "orders_3"} {
+		if got := (Orders{}).ShardedTableName(key); got != want {
+			t.Errorf("ShardedTableName(%d) = %s, want %s", key, got, want)
+		}
+	}
+	if got := (Orders{}).TableName(); got != "orders" {
+		t.Errorf("TableName() = %s, want orders", got)
+	}
+}
+
+func TestShardRouting(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(0); i < OrdersShardCount; i++ {
+		if err := db.Table((Orders{}).ShardedTableName(i)).AutoMigrate(&Orders{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	dao := NewOrdersDAO(db)
+	m := &Orders{UserID: 6, Status: "new"}
+	if err := dao.Create(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	var n int64
+	db.Table("orders_2").Count(&n)
+	if n != 1 {
+		t.Fatalf("orders_2 has %d rows, want 1", n)
+	}
+
+	m.Status = "paid"
+	if err := dao.Update(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	got, err := dao.GetByID(ctx, 6, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != "paid" || got.UserID != 6 {
+		t.Errorf("got %+v, want status paid and user_id 6", got)
+	}
+
+	if _, err := dao.UpdateStatusByIDs(ctx, 6, []int64{m.ID}, "shipped"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := dao.GetByID(ctx, 6, m.ID); got.Status != "shipped" {
+		t.Errorf("got %+v, want status shipped", got)
+	}
+}
+`
+
+func TestShardDAL(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", shardSchema)
+	if err := run("-dal", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	dal := readFile(t, "model/orders_dal.go")
+	// moving a row to another shard takes a Delete and a Create
+	if strings.Contains(dal, "UpdateUserIDByIDs") {
+		t.Errorf("UpdateUserIDByIDs generated for the shard key:\n%s", dal)
+	}
+	if !strings.Contains(dal, "UpdateStatusByIDs") {
+		t.Errorf("UpdateStatusByIDs not generated:\n%s", dal)
+	}
+	if !strings.Contains(dal, `Select("*").Omit("user_id").Updates(m)`) {
+		t.Errorf("Update writes the shard key:\n%s", dal)
+	}
+	writeFile(t, "model/shard_test.go", shardTest)
+	goTest(t, "./model")
+}
@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// irregularPlurals are the plurals the suffix rules of singularize get
+// wrong, and the words without a singular.
+var irregularPlurals = map[string]string{
+	"people":   "person",
+	"men":      "man",
+	"women":    "woman",
+	"children": "child",
+	"mice":     "mouse",
+	"geese":    "goose",
+	"teeth":    "tooth",
+	"feet":     "foot",
+	"criteria": "criterion",
+	"indices":  "index",
+	"matrices": "matrix",
+	"vertices": "vertex",
+	"analyses": "analysis",
+	"leaves":   "leaf",
+	"lives":    "life",
+	"knives":   "knife",
+	"wives":    "wife",
+	"halves":   "half",
+	"movies":   "movie",
+	"cookies":  "cookie",
+	"shoes":    "shoe",
+	"news":     "news",
+	"series":   "series",
+	"species":  "species",
+	"data":     "data",
+	"metadata": "metadata",
+	"media":    "media",
+}
+
+// singularSuffixes are the plural suffixes and their singular replacements,
+// tried in order.
+var singularSuffixes = []struct{ plural, singular string }{
+	{"ies", "y"},
+	{"sses", "ss"},
+	{"uses", "us"},
+	{"shes", "sh"},
+	{"ches", "ch"},
+	{"xes", "x"},
+	{"zzes", "zz"},
+	{"oes", "o"},
+	// address, status, analysis are singular already
+	{"ss", "ss"},
+	{"us", "us"},
+	{"is", "is"},
+	{"s", ""},
+}
+
+// singularOverrides holds the -singularize-overrides words.
+var singularOverrides map[string]string
+
+// parseSingularOverrides parses -singularize-overrides, e.g.
+// "cacti=cactus,staff=staff".
+func parseSingularOverrides(s string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" {
+			return nil, fmt.Errorf("-singularize-overrides: expected plural=singular, got %q", pair)
+		}
+		overrides[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.TrimSpace(kv[1])
+	}
+	return overrides, nil
+}
+
+// singularWord returns the singular of a lower case word.
+func singularWord(word string) string {
+	if s, ok := singularOverrides[word]; ok {
+		return s
+	}
+	if s, ok := irregularPlurals[word]; ok {
+		return s
+	}
+	for _, r := range singularSuffixes {
+		if strings.HasSuffix(word, r.plural) && len(word) > len(r.plural) {
+			return word[:len(word)-len(r.plural)] + r.singular
+		}
+	}
+	return word
+}
+
+// singularize returns the singular of a table name under -singularize, by
+// its last word: order_items becomes order_item. An override may also name
+// the whole table.
+func singularize(table string) string {
+	if !singularizeFlag {
+		return table
+	}
+	lower := strings.ToLower(table)
+	if s, ok := singularOverrides[lower]; ok {
+		return s
+	}
+	i := strings.LastIndexByte(lower, '_') + 1
+	word := singularWord(lower[i:])
+	if word == lower[i:] {
+		return table
+	}
+	return table[:i] + word
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSingularWord(t *testing.T) {
+	for plural, want := range map[string]string{
+		"users":      "user",
+		"items":      "item",
+		"boxes":      "box",
+		"classes":    "class",
+		"batches":    "batch",
+		"wishes":     "wish",
+		"statuses":   "status",
+		"heroes":     "hero",
+		"categories": "category",
+		"companies":  "company",
+		"people":     "person",
+		"children":   "child",
+		"addresses":  "address",
+		"address":    "address",
+		"status":     "status",
+		"analysis":   "analysis",
+		"news":       "news",
+		"user":       "user",
+	} {
+		if got := singularWord(plural); got != want {
+			t.Errorf("singularWord(%s) = %s, want %s", plural, got, want)
+		}
+	}
+}
+
+func TestSingularize(t *testing.T) {
+	const schema = `CREATE TABLE users (id bigint NOT NULL, PRIMARY KEY (id));
+CREATE TABLE order_items (id bigint NOT NULL, PRIMARY KEY (id));
+CREATE TABLE categories (id bigint NOT NULL, PRIMARY KEY (id));
+CREATE TABLE people (id bigint NOT NULL, PRIMARY KEY (id));
+CREATE TABLE cacti (id bigint NOT NULL, PRIMARY KEY (id));
+CREATE TABLE staff_bonuses (id bigint NOT NULL, PRIMARY KEY (id));`
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	if err := run("-singularize", "-singularize-overrides", "cacti=cactus, staff_bonuses=StaffBonus", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	for table, model := range map[string]string{
+		"users":         "User",
+		"order_items":   "OrderItem",
+		"categories":    "Category",
+		"people":        "Person",
+		"cacti":         "Cactus",
+		"staff_bonuses": "StaffBonus",
+	} {
+		// the file and TableName keep the real table name
+		fp := modelPath(table)
+		src := readFile(t, fp)
+		for _, want := range []string{"type " + model + " struct {", "func (" + model + ") TableName() string {\n\treturn \"" + table + "\""} {
+			if !strings.Contains(src, want) {
+				t.Errorf("%s lacks %s:\n%s", fp, want, src)
+			}
+		}
+	}
+}
+
+func TestSingularizeOverridesMalformed(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", "CREATE TABLE users (id bigint NOT NULL, PRIMARY KEY (id));")
+	err := run("-singularize", "-singularize-overrides", "cacti", "schema.sql")
+	if err == nil || !strings.Contains(err.Error(), `expected plural=singular, got "cacti"`) {
+		t.Errorf("got %v, want the malformed override", err)
+	}
+}
@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// skipColumnPatterns are the compiled -skip-columns expressions.
+var skipColumnPatterns []*regexp.Regexp
+
+// parseSkipColumns compiles the comma separated -skip-columns expressions,
+// each matching whole column names.
+func parseSkipColumns(list string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, expr := range strings.Split(list, ",") {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		if _, err := regexp.Compile(expr); err != nil {
+			return nil, fmt.Errorf("-skip-columns %q: %v", expr, err)
+		}
+		patterns = append(patterns, regexp.MustCompile(`^(?:`+expr+`)$`))
+	}
+	return patterns, nil
+}
+
+func skippedColumn(name string) bool {
+	for _, re := range skipColumnPatterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipMatchingColumns drops the columns matching -skip-columns from every
+// table, before any generator sees them, and reports them per table.
+// Primary key columns cannot be skipped.
+func skipMatchingColumns(ddls []*sqlparser.DDL) error {
+	if len(skipColumnPatterns) == 0 {
+		return nil
+	}
+	for _, ddl := range ddls {
+		table := ddl.NewName.Name.String()
+		pk := make(map[string]bool)
+		for _, c := range primaryKeyColumns(ddl) {
+			pk[c.Name] = true
+		}
+		var skipped []string
+		columns := ddl.TableSpec.Columns[:0]
+		for _, c := range ddl.TableSpec.Columns {
+			name := c.Name.String()
+			if !skippedColumn(name) {
+				columns = append(columns, c)
+				continue
+			}
+			if pk[name] {
+				return fmt.Errorf("-skip-columns: cannot skip %s.%s, it is part of the primary key", table, name)
+			}
+			skipped = append(skipped, name)
+		}
+		ddl.TableSpec.Columns = columns
+		switch len(skipped) {
+		case 0:
+		case 1:
+			fmt.Fprintf(os.Stderr, "table %s: skipped 1 column (%s)\n", table, skipped[0])
+		default:
+			fmt.Fprintf(os.Stderr, "table %s: skipped %d columns (%s)\n", table, len(skipped), strings.Join(skipped, ", "))
+		}
+	}
+	return nil
+}
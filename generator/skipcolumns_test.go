@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const auditSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  created_by varchar(64) NOT NULL DEFAULT '',
+  updated_by varchar(64) NOT NULL DEFAULT '',
+  trace_id varchar(64) NOT NULL DEFAULT '',
+  PRIMARY KEY (id)
+);
+CREATE TABLE orders (
+  id bigint NOT NULL AUTO_INCREMENT,
+  total int NOT NULL,
+  trace_id varchar(64) NOT NULL DEFAULT '',
+  PRIMARY KEY (id)
+);
+CREATE TABLE notes (
+  id bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (id)
+);`
+
+const auditTest = `package model
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSkipColumns(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the columns the middleware fills in are still in the table
+	if err := db.Exec("CREATE TABLE users (id integer PRIMARY KEY AUTOINCREMENT, email text NOT NULL, created_by text NOT NULL DEFAULT '', updated_by text NOT NULL DEFAULT '', trace_id text NOT NULL DEFAULT '')").Error; err != nil {
+		t.Fatal(err)
+	}
+	var insert string
+	db.Callback().Create().After("gorm:create").Register("capture", func(tx *gorm.DB) {
+		insert = tx.Statement.SQL.String()
+	})
+	if err := NewUsersDAO(db).Create(context.Background(), &Users{Email: "a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(insert, "INSERT INTO ` + "`users` (`email`)" + ` VALUES") {
+		t.Errorf("insert %s", insert)
+	}
+	for _, skipped := range []string{"created_by", "updated_by", "trace_id"} {
+		if strings.Contains(insert, skipped) {
+			t.Errorf("insert %s sets %s", insert, skipped)
+		}
+	}
+	got, err := NewUsersDAO(db).GetByID(context.Background(), 1)
+	if err != nil || got.Email != "a@example.com" {
+		t.Errorf("got %+v, %v", got, err)
+	}
+}
+`
+
+func TestSkipColumns(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", auditSchema)
+	_, stderr, err := capture(t, func() error {
+		return run("-dal", "-skip-columns", "created_by, updated_by,trace_.*", "schema.sql")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the summary counts the columns of each table
+	want := "table users: skipped 3 columns (created_by, updated_by, trace_id)\n" +
+		"table orders: skipped 1 column (trace_id)\n"
+	if !strings.Contains(stderr, want) {
+		t.Errorf("no summary\n%s\nin:\n%s", want, stderr)
+	}
+	if strings.Contains(stderr, "table notes") {
+		t.Errorf("summary of a table without skipped columns:\n%s", stderr)
+	}
+	for _, table := range []string{"users", "orders"} {
+		for _, file := range []string{modelPath(table), "model/" + table + "_dal.go"} {
+			code := readFile(t, file)
+			for _, skipped := range []string{"created_by", "updated_by", "trace_id", "CreatedBy", "UpdatedBy", "TraceID"} {
+				if strings.Contains(code, skipped) {
+					t.Errorf("%s still has %s:\n%s", file, skipped, code)
+				}
+			}
+		}
+	}
+	writeFile(t, "model/audit_test.go", auditTest)
+	goTest(t, "./model")
+}
+
+func TestSkipColumnsErrors(t *testing.T) {
+	for _, tt := range []struct {
+		expr string
+		want string
+	}{
+		{"id", "-skip-columns: cannot skip users.id, it is part of the primary key"},
+		{"trace_id,(", `-skip-columns "(": error parsing regexp`},
+	} {
+		chdir(t)
+		writeFile(t, "schema.sql", auditSchema)
+		err := run("-skip-columns", tt.expr, "schema.sql")
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want %s", tt.expr, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// -dialect=sqlite parses with the Postgres hand parser of pgparse.go, which
+// keeps the case of SQLite identifiers, also accepts `quoted` and [quoted]
+// identifiers and maps the declared column types through sqliteType.
+
+// sqliteTypeName consumes the words of a declared SQLite column type, e.g.
+// "UNSIGNED BIG INT", returning "" for a column without a type.
+func sqliteTypeName(p *pgParser) string {
+	var words []string
+	for !p.done() && p.peek().Kind == pgIdent && !pgConstraintStart(p.peek()) {
+		words = append(words, p.next().Text)
+	}
+	return strings.Join(words, " ")
+}
+
+// sqliteType maps a declared SQLite type to the SQL type GoType knows,
+// following the SQLite type affinity rules. Dates and booleans keep their
+// declared types, which the SQLite drivers scan into time.Time and bool.
+func sqliteType(declared string) string {
+	t := strings.ToLower(declared)
+	switch t {
+	case "date", "datetime", "timestamp":
+		return t
+	case "bool", "boolean":
+		return "boolean"
+	}
+	switch {
+	case strings.Contains(t, "int"):
+		// every SQLite integer is 64 bits
+		return "bigint"
+	case strings.Contains(t, "char"), strings.Contains(t, "clob"), strings.Contains(t, "text"):
+		return "text"
+	case t == "" || strings.Contains(t, "blob"):
+		return "blob"
+	case strings.Contains(t, "real"), strings.Contains(t, "floa"), strings.Contains(t, "doub"):
+		return "double"
+	}
+	// NUMERIC affinity, mapped like DECIMAL, -type-map included
+	return "decimal"
+}
+
+// applySQLiteRowid marks the INTEGER PRIMARY KEY of a table, an alias of
+// the rowid SQLite assigns, auto incremented, unless the table is declared
+// WITHOUT ROWID. integers holds the columns declared exactly INTEGER and p
+// is at the table options.
+func applySQLiteRowid(ddl *sqlparser.DDL, integers map[string]bool, p *pgParser) {
+	for !p.done() {
+		if p.accept("without", "rowid") {
+			return
+		}
+		p.next()
+	}
+	for _, idx := range ddl.TableSpec.Indexes {
+		if !idx.Info.Primary || len(idx.Columns) != 1 || !integers[idx.Columns[0].Column.Lowered()] {
+			continue
+		}
+		if c := findColumn(ddl, idx.Columns[0].Column.String()); c != nil {
+			c.Type.Autoincrement = true
+		}
+	}
+}
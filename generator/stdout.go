@@ -0,0 +1,37 @@
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// writeStdout writes the models of every table to w as one Go file of
+// package pkg, rendered as -single-file renders them, with their enum and
+// set types, whatever the -group-by-prefix groups and -types-file. Nothing
+// else is written, so that the stream can be redirected into a file.
+func writeStdout(w io.Writer, pkg string, ddls []*sqlparser.DDL) error {
+	if checkMode {
+		return fmt.Errorf("-stdout writes no files for -check to compare")
+	}
+	var enums []enumType
+	if typesFile != "" {
+		enums = packageEnums(ddls)
+	}
+	content, err := runHooks("-", buildHeader+generatedHeader+strings.TrimLeft(genModels(pkg, ddls, enums), "\n"))
+	if err != nil {
+		return err
+	}
+	if !noFmt {
+		formatted, err := format.Source([]byte(content))
+		if err != nil {
+			return fmt.Errorf("-stdout: %v", err)
+		}
+		content = string(formatted)
+	}
+	_, err = io.WriteString(w, content)
+	return err
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+)
+
+const stdoutSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  uid char(36) NOT NULL,
+  state enum('active','banned') NOT NULL,
+  created_at datetime NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE TABLE events (
+  id bigint NOT NULL AUTO_INCREMENT,
+  payload json,
+  at datetime NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+func TestStdout(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", stdoutSchema)
+	writeFile(t, "types.json", `{"columns": {"users.uid": {"type": "uuid.UUID", "import": "github.com/google/uuid"}}}`)
+	stdout, stderr, err := capture(t, func() error {
+		return run("-no-fmt=false", "-stdout", "-type-map", "types.json", "-group-by-prefix", "event", "schema.sql")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stderr != "" {
+		t.Errorf("got stderr:\n%s", stderr)
+	}
+	if _, err := os.Stat("model"); !os.IsNotExist(err) {
+		t.Errorf("model written: %v", err)
+	}
+
+	// valid, gofmt-clean Go with one package clause and merged imports
+	formatted, err := format.Source([]byte(stdout))
+	if err != nil {
+		t.Fatalf("%v in:\n%s", err, stdout)
+	}
+	if string(formatted) != stdout {
+		t.Errorf("not gofmt-clean:\n%s", stdout)
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "stdout.go", stdout, parser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name.Name != "model" {
+		t.Errorf("package %s, want model", f.Name.Name)
+	}
+	var imports []string
+	for _, spec := range f.Imports {
+		imports = append(imports, spec.Path.Value)
+	}
+	if got, want := strings.Join(imports, " "), `"database/sql/driver" "encoding/json" "fmt" "time" "github.com/google/uuid"`; got != want {
+		t.Errorf("imports %s, want %s", got, want)
+	}
+	if !strings.HasPrefix(stdout, "// Code generated by dalgen. DO NOT EDIT.\n\npackage model\n") ||
+		strings.Count(stdout, "package ") != 1 || strings.Count(stdout, "import (") != 1 {
+		t.Errorf("not a single file:\n%s", stdout)
+	}
+	for _, want := range []string{"type Users struct {", "type Events struct {", "type UsersState string"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("no %s in:\n%s", want, stdout)
+		}
+	}
+
+	// the stream redirected into a file builds
+	writeFile(t, "model/models.go", stdout)
+	goTest(t, "./model")
+}
+
+func TestStdoutCheck(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", stdoutSchema)
+	if err := run("-stdout", "-check", "schema.sql"); err == nil || err.Error() != "-stdout writes no files for -check to compare" {
+		t.Errorf("got %v", err)
+	}
+}
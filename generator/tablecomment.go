@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// docWidth is the width long struct doc comments are wrapped at.
+const docWidth = 100
+
+// tableComment returns the COMMENT table option of a table, "" if none.
+// The parser has already unescaped the option, so the closing quote is the
+// first one followed by a space or the end of the options.
+func tableComment(ddl *sqlparser.DDL) string {
+	opts := ddl.TableSpec.Options
+	i := strings.Index(strings.ToLower(opts), "comment")
+	if i < 0 {
+		return ""
+	}
+	rest := strings.TrimLeft(opts[i+len("comment"):], " =")
+	if rest == "" || rest[0] != '\'' && rest[0] != '"' {
+		return ""
+	}
+	q := rest[0]
+	rest = rest[1:]
+	for j := 0; j < len(rest); j++ {
+		if rest[j] == q && (j+1 == len(rest) || rest[j+1] == ' ') {
+			return rest[:j]
+		}
+	}
+	return rest
+}
+
+// sanitizeComment collapses the whitespace, newlines included, of a SQL
+// comment into single spaces, so it stays on its Go comment line, and
+// breaks up the */ that would end a block comment around it. Go source
+// being UTF-8, the bytes of a comment in another encoding, such as GBK,
+// are replaced with U+FFFD; UTF-8 comments are kept byte for byte.
+func sanitizeComment(comment string) string {
+	if !utf8.ValidString(comment) {
+		fmt.Fprintf(os.Stderr, "warning: comment %q is not valid UTF-8, replacing its invalid bytes\n", comment)
+		comment = strings.ToValidUTF8(comment, string(utf8.RuneError))
+	}
+	return strings.Replace(strings.Join(strings.Fields(comment), " "), "*/", "* /", -1)
+}
+
+// displayWidth returns the number of columns s takes in a terminal, where
+// CJK characters are two columns wide.
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n++
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+			r >= 0x3000 && r <= 0x303f || r >= 0xff00 && r <= 0xffef {
+			n++
+		}
+	}
+	return n
+}
+
+// splitWidth splits the longest prefix of s that fits in width columns.
+func splitWidth(s string, width int) (string, string) {
+	n := 0
+	for i, r := range s {
+		n += displayWidth(string(r))
+		if n > width {
+			return s[:i], s[i:]
+		}
+	}
+	return s, ""
+}
+
+// wrapComment wraps text into lines of at most width columns, breaking at
+// spaces or, for text without spaces such as CJK, anywhere.
+func wrapComment(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for word != "" {
+			sep := ""
+			if line != "" {
+				sep = " "
+			}
+			if displayWidth(line+sep+word) <= width {
+				line += sep + word
+				break
+			}
+			if line != "" && displayWidth(word) <= width {
+				lines = append(lines, line)
+				line = ""
+				continue
+			}
+			// the word does not fit on a line of its own, break it
+			head, rest := splitWidth(word, width-displayWidth(line+sep))
+			lines = append(lines, line+sep+head)
+			line, word = "", rest
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// structDoc returns the doc comment of a model struct, built from the table
+// comment: "// Users is the user account master table.", or "// Users maps
+// to the users table." when the table has no comment.
+func structDoc(ddl *sqlparser.DDL) string {
+	tableNameStr := ddl.NewName.Name.String()
+	tableName := modelName(tableNameStr)
+	comment := sanitizeComment(tableComment(ddl))
+	var text string
+	if comment == "" {
+		kind := "table"
+		if viewTables[ddl] {
+			kind = "view"
+		}
+		text = tableName + " maps to the " + tableNameStr + " " + kind + "."
+	} else {
+		r, size := utf8.DecodeRuneInString(comment)
+		if r < utf8.RuneSelf && unicode.IsLetter(r) {
+			// lower "User account" but not acronyms like "SKU"
+			if next, _ := utf8.DecodeRuneInString(comment[size:]); !unicode.IsUpper(next) {
+				comment = string(unicode.ToLower(r)) + comment[size:]
+			}
+			lower := strings.ToLower(comment)
+			if !strings.HasPrefix(lower, "the ") && !strings.HasPrefix(lower, "a ") && !strings.HasPrefix(lower, "an ") {
+				comment = "the " + comment
+			}
+		}
+		text = tableName + " is " + comment
+		if last, _ := utf8.DecodeLastRuneInString(text); !unicode.IsPunct(last) {
+			text += "."
+		}
+	}
+	lines := wrapComment(text, docWidth-3)
+	doc := "// " + strings.Join(lines, "\n// ")
+	if checks := checksDoc(ddl); checks != "" {
+		doc += "\n" + checks
+	}
+	return doc
+}
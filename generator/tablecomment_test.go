@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestTableCommentGolden generates the models of testdata/tablecomment,
+// whose tables have a plain, a multi-line, a short and a long CJK comment
+// and none, and compares them with the golden files there, which go test
+// -update rewrites.
+func TestTableCommentGolden(t *testing.T) {
+	schema, err := ioutil.ReadFile("testdata/tablecomment/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := testdataPath(t, "tablecomment")
+	chdir(t)
+	writeFile(t, "schema.sql", string(schema))
+	if err := run("-no-column-maps", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range []string{"users", "orders", "accounts", "ledgers", "sessions"} {
+		checkGolden(t, filepath.Join(golden, table+".go.golden"), gofmt(t, readFile(t, modelPath(table))))
+	}
+}
+
+func TestWrapComment(t *testing.T) {
+	for _, tt := range []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"a b c", 3, []string{"a b", "c"}},
+		{"abcdef", 4, []string{"abcd", "ef"}},
+		// CJK characters take two columns
+		{"用户账户主表", 5, []string{"用户", "账户", "主表"}},
+		{"id 用户账户", 6, []string{"id 用", "户账户"}},
+	} {
+		got := wrapComment(tt.text, tt.width)
+		if len(got) != len(tt.want) {
+			t.Errorf("wrapComment(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("wrapComment(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestCommentUTF8(t *testing.T) {
+	const column = "用户昵称（可为空），例如：小明"
+	table := strings.Repeat("订单流水记录表，", 10) + "按月归档"
+	schema := "CREATE TABLE `orders` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `nickname` varchar(64) COMMENT '" + column + "',\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") COMMENT='" + table + "';"
+	orders := generate(t, schema, "orders")
+	if !utf8.ValidString(orders) {
+		t.Fatalf("invalid UTF-8 in:\n%s", orders)
+	}
+	if !strings.Contains(orders, "// "+column+"\n") {
+		t.Errorf("the column comment is not kept byte for byte:\n%s", orders)
+	}
+	// the wrapped doc comment holds the whole comment, split between runes
+	var doc []string
+	for _, line := range strings.Split(orders, "\n") {
+		if strings.HasPrefix(line, "// Orders is ") || len(doc) > 0 && strings.HasPrefix(line, "// ") {
+			doc = append(doc, strings.TrimPrefix(line, "// "))
+		} else if len(doc) > 0 {
+			break
+		}
+	}
+	if len(doc) < 2 {
+		t.Fatalf("the long comment is not wrapped:\n%s", orders)
+	}
+	for _, line := range doc {
+		if displayWidth(line) > docWidth-3 {
+			t.Errorf("line %q is wider than %d columns", line, docWidth-3)
+		}
+	}
+	if got, want := strings.Join(doc, ""), "Orders is "+table+"."; got != want {
+		t.Errorf("got doc %q, want %q", got, want)
+	}
+}
+
+func TestCommentInvalidUTF8(t *testing.T) {
+	// 用户 in GBK
+	gbk := string([]byte{0xd3, 0xc3, 0xbb, 0xa7})
+	chdir(t)
+	writeFile(t, "schema.sql", "CREATE TABLE users (id bigint NOT NULL COMMENT '"+gbk+"', PRIMARY KEY (id));")
+	_, stderr, err := capture(t, func() error { return run("schema.sql") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stderr, "is not valid UTF-8, replacing its invalid bytes") {
+		t.Errorf("no warning:\n%s", stderr)
+	}
+	users := readFile(t, modelPath("users"))
+	if !utf8.ValidString(users) || !strings.Contains(users, "// �") {
+		t.Errorf("the invalid bytes are not replaced:\n%s", users)
+	}
+}
+
+const tableCommentSchema = "CREATE TABLE `users` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	") ENGINE=InnoDB COMMENT='registered \"users\", not admins';\n" +
+	"CREATE TABLE `tags` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");"
+
+const tableCommentTest = `package model
+
+import "testing"
+
+func TestTableComment(t *testing.T) {
+	if got, want := (Users{}).TableComment(), ` + "`registered \"users\", not admins`" + `; got != want {
+		t.Errorf("TableComment() = %q, want %q", got, want)
+	}
+	var tags interface{} = Tags{}
+	if _, ok := tags.(interface{ TableComment() string }); ok {
+		t.Error("Tags has a TableComment method")
+	}
+}
+`
+
+func TestTableComment(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", tableCommentSchema)
+	if err := run("-gen-table-comment", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	users := readFile(t, modelPath("users"))
+	if want := "// Users is the registered \"users\", not admins.\ntype Users struct {"; !strings.Contains(users, want) {
+		t.Errorf("no %s in:\n%s", want, users)
+	}
+	writeFile(t, "model/tablecomment_test.go", tableCommentTest)
+	goTest(t, "./model")
+
+	// without -gen-table-comment the comment only documents the struct
+	users = generate(t, tableCommentSchema, "users")
+	if strings.Contains(users, "TableComment") || !strings.Contains(users, "// Users is the registered") {
+		t.Errorf("got:\n%s", users)
+	}
+}
+
+func TestTableCommentPostgres(t *testing.T) {
+	const schema = `CREATE TABLE public.users (
+    id bigint NOT NULL
+);
+COMMENT ON TABLE public.users IS 'registered users';`
+	users := generate(t, schema, "users", "-dialect", "postgres", "-gen-table-comment")
+	for _, want := range []string{"// Users is the registered users.\n", "return \"registered users\""} {
+		if !strings.Contains(users, want) {
+			t.Errorf("no %s in:\n%s", want, users)
+		}
+	}
+}
+
+func TestTableCommentTemplate(t *testing.T) {
+	chdir(t)
+	writeFile(t, "model.tmpl", "package {{.PackageName}}\n\n"+
+		"// {{.TableName}}Comment is the COMMENT of {{.RawName}}.\n"+
+		"const {{.TableName}}Comment = {{printf \"%q\" .TableComment}}\n")
+	writeFile(t, "schema.sql", tableCommentSchema)
+	if err := run("-template", "model.tmpl", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if users, want := readFile(t, modelPath("users")), `const UsersComment = "registered \"users\", not admins"`; !strings.Contains(users, want) {
+		t.Errorf("no %s in:\n%s", want, users)
+	}
+	if tags, want := readFile(t, modelPath("tags")), `const TagsComment = ""`; !strings.Contains(tags, want) {
+		t.Errorf("no %s in:\n%s", want, tags)
+	}
+}
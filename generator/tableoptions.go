@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+var (
+	// partitionClause matches the PARTITION BY clause following the table
+	// options, which the sql parser does not know.
+	partitionClause = regexp.MustCompile(`(?i)^partition\s+by\b`)
+	// knownTableOption matches the table options the models use.
+	knownTableOption = regexp.MustCompile(`(?i)^(comment|auto_increment)\s*=?\s*('(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"|\d+)`)
+)
+
+// tableOptionRetry is a simpler version of a CREATE TABLE statement, along
+// with what it leaves out.
+type tableOptionRetry struct {
+	stmt    string
+	dropped string
+}
+
+// tableOptionRetries returns the simpler versions of a CREATE TABLE
+// statement to parse when the statement itself does not: first with the
+// statement cut at its PARTITION BY clause, then with only the COMMENT and
+// AUTO_INCREMENT table options kept.
+func tableOptionRetries(stmt string) []tableOptionRetry {
+	_, end, ok := createTableBody(stmt)
+	if !ok {
+		return nil
+	}
+	opts := stmt[end+1:]
+	cut := len(opts)
+	var known []string
+	depth := 0
+	var quote byte
+	for i := 0; i < cut; i++ {
+		c := opts[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth != 0 || i > 0 && isWordByte(opts[i-1]):
+		case partitionClause.MatchString(opts[i:]):
+			cut = i
+		default:
+			if m := knownTableOption.FindString(opts[i:]); m != "" {
+				known = append(known, m)
+				i += len(m) - 1
+			}
+		}
+	}
+	var retries []tableOptionRetry
+	if cut < len(opts) {
+		retries = append(retries, tableOptionRetry{stmt[:end+1] + opts[:cut], "its PARTITION BY clause"})
+	}
+	return append(retries, tableOptionRetry{stmt[:end+1] + " " + strings.Join(known, " "),
+		"its table options other than COMMENT and AUTO_INCREMENT"})
+}
+
+// parseStatement parses a statement. A CREATE TABLE statement the sql parser
+// rejects for its table options or partitioning is retried without them,
+// with a warning, rather than losing its columns.
+func parseStatement(stmt string) (sqlparser.Statement, error) {
+	parsed, err := sqlparser.ParseStrictDDL(stmt)
+	if err == nil {
+		return parsed, nil
+	}
+	for _, retry := range tableOptionRetries(stmt) {
+		parsed, retryErr := sqlparser.ParseStrictDDL(retry.stmt)
+		if ddl, ok := parsed.(*sqlparser.DDL); retryErr == nil && ok && ddl.TableSpec != nil {
+			fmt.Fprintf(os.Stderr, "warning: table %s: %v, ignoring %s\n", ddl.NewName.Name.String(), err, retry.dropped)
+			return parsed, nil
+		}
+	}
+	return sqlparser.Parse(stmt)
+}
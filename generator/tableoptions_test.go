@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+func TestTableOptions(t *testing.T) {
+	schema := testdataPath(t, "tableoptions.sql")
+	chdir(t)
+	_, stderr, err := capture(t, func() error { return run(schema) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"warning: table events: syntax error at position 263, ignoring its PARTITION BY clause\n",
+		"warning: table all_archives: syntax error at position 175, ignoring its table options other than COMMENT and AUTO_INCREMENT\n",
+	} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("no %q in:\n%s", want, stderr)
+		}
+	}
+	// ROW_FORMAT, KEY_BLOCK_SIZE and TABLESPACE parse as they are
+	if strings.Contains(stderr, "table archives") {
+		t.Errorf("archives retried:\n%s", stderr)
+	}
+	for table, want := range map[string]string{
+		"events":       "// Events is the partitioned by year.\n",
+		"archives":     "// Archives is the compressed archive.\n",
+		"all_archives": "// AllArchives is the every archive.\n",
+	} {
+		if m := readFile(t, modelPath(table)); !strings.Contains(m, want) {
+			t.Errorf("%s lacks the table comment %q:\n%s", table, want, m)
+		}
+	}
+}
+
+func TestTableOptionRetries(t *testing.T) {
+	for _, tt := range []struct {
+		stmt string
+		want []string
+	}{
+		{
+			"CREATE TABLE a (id int) ENGINE=InnoDB AUTO_INCREMENT=1001 COMMENT='it''s (partitioned)' PARTITION BY RANGE (id) (PARTITION p0 VALUES LESS THAN (10))",
+			[]string{"engine=innodb", "auto_increment=1001", "comment='it's (partitioned)'"},
+		},
+		{
+			"CREATE TABLE a (id int) ENGINE=MRG_MyISAM AUTO_INCREMENT=7 UNION=(b,c) COMMENT 'merged'",
+			[]string{"auto_increment=7", "comment 'merged'"},
+		},
+	} {
+		_, _, err := capture(t, func() error {
+			parsed, err := parseStatement(tt.stmt)
+			if err != nil {
+				return err
+			}
+			options := strings.ToLower(parsed.(*sqlparser.DDL).TableSpec.Options)
+			for _, want := range tt.want {
+				if !strings.Contains(options, want) {
+					t.Errorf("options %q of %s lack %q", options, tt.stmt, want)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("%s: %v", tt.stmt, err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package generator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// TableContext is what the model file of a table is rendered from, by the
+// built-in template or a -template one:
+//
+//	package {{.PackageName}}
+//
+//	{{.Imports}}
+//	// {{.TableName}} maps to {{.RawName}}.
+//	type {{.TableName}} struct {
+//	{{- range .Columns}}
+//		{{.GoName}} {{.GoType}} `{{.Tags}}`
+//	{{- end}}
+//	}
+//	{{.Types}}
+//
+// Types declares the enum and set types the fields may refer to, so a
+// template leaving it out does not compile for the tables having them.
+//
+// Along with the context, a template may call std and thirdParty, which
+// split ImportPaths into the standard library and the third-party ones.
+type TableContext struct {
+	// PackageName is the package of the file.
+	PackageName string
+	// Imports is the import declaration of the file, ImportPaths its
+	// package paths.
+	Imports     string
+	ImportPaths []string
+	// TableName is the name of the model, RawName that of the table in
+	// the schema and TableNameStr what TableName() returns, RawName with
+	// the -table-prefix and -table-suffix.
+	TableName    string
+	RawName      string
+	TableNameStr string
+	// Doc is the doc comment of the model.
+	Doc string
+	// TableComment is the COMMENT of the table, "" if none, and
+	// TableCommentMethod is set for -gen-table-comment to generate a
+	// TableComment method returning it.
+	TableComment       string
+	TableCommentMethod bool
+	// Columns are the fields of the model, leaving out those of the
+	// -embed-struct, and Indexes the indexes of the table.
+	Columns []ColumnContext
+	Indexes []IndexContext
+	// Fields is the body of the model struct as the built-in template
+	// writes it, associations and embedded struct included, and Types the
+	// declarations following the model: its enum and set types, shard
+	// helpers and column maps.
+	Fields string
+	Types  string
+}
+
+// ColumnContext is a field of the model.
+type ColumnContext struct {
+	// Name is the column name, GoName the field name and GoType its type.
+	Name   string
+	GoName string
+	GoType string
+	// Tags is the struct tag of the field, without its backquotes.
+	Tags string
+	// Comment is the comment of the column, "" if none.
+	Comment string
+	// Nullable reports whether the column may be NULL, never for a primary
+	// key column, which the database makes NOT NULL.
+	Nullable  bool
+	IsPrimary bool
+	// Line is the field as the built-in template writes it.
+	Line string
+}
+
+// IndexContext is an index of the table.
+type IndexContext struct {
+	Name    string
+	Columns []string
+	Primary bool
+	Unique  bool
+}
+
+// customTemplate is the parsed -template, nil for the built-in template.
+var customTemplate *template.Template
+
+// loadTemplate parses the -template file. The template is named after the
+// file, so that its parse and execution errors name the file and line.
+func loadTemplate(file string) error {
+	if singleFile != "" || stdoutFlag {
+		return fmt.Errorf("-template renders one file per table, it cannot be combined with -single-file or -stdout")
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	customTemplate, err = template.New(file).Funcs(importFuncs).Parse(string(data))
+	return err
+}
+
+func newTableContext(pkg string, ddl *sqlparser.DDL) TableContext {
+	paths := tableImports(ddl)
+
+	tableNameStr := ddl.NewName.Name.String()
+	tableName := modelName(tableNameStr)
+
+	var fields strings.Builder
+	for i, c := range genColumns(ddl) {
+		if i != 0 {
+			fields.WriteString("\n")
+		}
+		fields.WriteString("\t")
+		fields.WriteString(c)
+	}
+	for i, a := range tableAssociations[ddl] {
+		if i == 0 {
+			fields.WriteString("\n")
+		}
+		fields.WriteString("\n\t")
+		fields.WriteString(a.String())
+	}
+
+	var columns []ColumnContext
+	for _, c := range modelColumns(ddl) {
+		columns = append(columns, ColumnContext{
+			Name:      c.Name,
+			GoName:    c.goName(),
+			GoType:    c.Type,
+			Tags:      c.Tags(),
+			Comment:   c.Comment,
+			Nullable:  !c.NotNull && !c.PrimaryKey,
+			IsPrimary: c.PrimaryKey,
+			Line:      c.String(),
+		})
+	}
+	var indexes []IndexContext
+	for _, idx := range ddl.TableSpec.Indexes {
+		i := IndexContext{Name: indexName(ddl, idx), Primary: idx.Info.Primary, Unique: idx.Info.Unique}
+		for _, c := range idx.Columns {
+			i.Columns = append(i.Columns, c.Column.String())
+		}
+		indexes = append(indexes, i)
+	}
+
+	comment := tableComment(ddl)
+	types := genShard(ddl)
+	if typesFile == "" {
+		types = genEnumTypes(tableEnums(ddl)) + types
+	}
+	if !noColumnMaps {
+		types += genColumnMaps(ddl)
+	}
+
+	return TableContext{
+		PackageName:  pkg,
+		Imports:      renderImports(paths),
+		ImportPaths:  paths,
+		TableName:    tableName,
+		RawName:      tableNameStr,
+		TableNameStr: tablePrefix + tableNameStr + tableSuffix,
+		Doc:          structDoc(ddl),
+		TableComment: comment,
+		Columns:      columns,
+		Indexes:      indexes,
+		Fields:       fields.String(),
+		Types:        types,
+
+		TableCommentMethod: genTableComment && comment != "",
+	}
+}
@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+// docTemplate is the example template of the TableContext doc comment,
+// listing the nullability of the columns.
+const docTemplate = `package {{.PackageName}}
+
+{{.Imports}}
+// {{.TableName}} maps to {{.RawName}}.
+type {{.TableName}} struct {
+{{- range .Columns}}
+	{{.GoName}} {{.GoType}} ` + "`{{.Tags}}`" + ` // nullable {{.Nullable}}
+{{- end}}
+}
+{{.Types}}`
+
+func TestTemplate(t *testing.T) {
+	chdir(t)
+	writeFile(t, "model.tmpl", docTemplate)
+	writeFile(t, "schema.sql", "CREATE TABLE orders (\n"+
+		"  id bigint,\n"+
+		"  state enum('new','paid') NOT NULL,\n"+
+		"  note varchar(255),\n"+
+		"  PRIMARY KEY (id)\n"+
+		");")
+	if err := run("-template", "model.tmpl", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	got := gofmt(t, readFile(t, modelPath("orders")))
+	for _, want := range []string{
+		"ID    int64       `gorm:\"Column:id;primaryKey\" json:\"id\"`   // nullable false\n",
+		"State OrdersState `gorm:\"Column:state\" json:\"state\"`        // nullable false\n",
+		"Note  string      `gorm:\"Column:note;size:255\" json:\"note\"` // nullable true\n",
+		"type OrdersState string\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("orders.go lacks %q:\n%s", want, got)
+		}
+	}
+}
+
+const tablePrefixTest = `package model
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestTableName(t *testing.T) {
+	if got := (Users{}).TableName(); got != "prod_users_v1" {
+		t.Errorf("TableName() = %s, want prod_users_v1", got)
+	}
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Users{}); err != nil {
+		t.Fatal(err)
+	}
+	if !db.Migrator().HasTable("prod_users_v1") || db.Migrator().HasTable("users") {
+		t.Error("AutoMigrate did not create prod_users_v1 alone")
+	}
+	if err := db.Create(&Users{Email: "a@example.com"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var n int64
+	db.Table("prod_users_v1").Count(&n)
+	if n != 1 {
+		t.Errorf("prod_users_v1 has %d rows, want 1", n)
+	}
+}
+`
+
+func TestTablePrefix(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", usersSchema)
+	if err := run("-table-prefix", "prod_", "-table-suffix", "_v1", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	users := readFile(t, modelPath("users"))
+	if !strings.Contains(users, "type Users struct") {
+		t.Errorf("the struct name changed:\n%s", users)
+	}
+	writeFile(t, "model/users_test.go", tablePrefixTest)
+	goTest(t, "./model")
+}
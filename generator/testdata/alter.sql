@@ -0,0 +1,13 @@
+CREATE TABLE `users` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `name` varchar(64) NOT NULL,
+  `legacy` int,
+  `temp` int,
+  PRIMARY KEY (`id`)
+);
+
+ALTER TABLE `users` ADD COLUMN IF NOT EXISTS `email` varchar(255) NOT NULL AFTER `name`;
+ALTER TABLE `users` DROP COLUMN IF EXISTS `legacy`;
+ALTER TABLE `users` DROP IF EXISTS `temp`;
+ALTER TABLE `users` DROP COLUMN IF EXISTS `never_added`;
+ALTER TABLE `users` MODIFY COLUMN `name` varchar(128) NOT NULL;
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/shopspring/decimal"
+	dec "github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// Posts maps to the posts table.
+type Posts struct {
+	ID    int64           `gorm:"Column:id;primaryKey;autoIncrement" json:"id"`
+	Title string          `gorm:"Column:title;size:255" json:"title"`
+	Price decimal.Decimal `gorm:"Column:price" json:"price"`
+}
+
+func (Posts) TableName() string {
+	return "posts"
+}
+
+// Users maps to the users table.
+type Users struct {
+	ID   int64  `gorm:"Column:id;primaryKey;autoIncrement" json:"id"`
+	Name string `gorm:"Column:name;size:64" json:"name"`
+}
+
+func (Users) TableName() string {
+	return "users"
+}
+
+// 用户缓存 caches the users in redis,
+// 每个用户一个键 (one key per user).
+type UserCache struct {
+	client redis.UniversalClient // 客户端
+	ttl    time.Duration
+}
+
+/*
+Get returns the cached user, or nil on a miss.
+缓存未命中时返回 nil。
+*/
+func (c *UserCache) Get(ctx context.Context, key string) (*Users, error) {
+	b, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var u Users
+	return &u, yaml.Unmarshal(b, &u)
+}
+
+// Total sums the prices, 合计.
+func Total(prices []dec.Decimal) dec.Decimal {
+	return dec.Sum(dec.Zero, prices...)
+}
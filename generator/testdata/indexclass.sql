@@ -0,0 +1,12 @@
+CREATE TABLE `posts` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `title` varchar(255) NOT NULL,
+  `body` text NOT NULL,
+  `location` point NOT NULL,
+  `slug` varchar(64) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_slug` (`slug`),
+  FULLTEXT KEY `ft_body` (`body`) /*!50100 WITH PARSER `ngram` */,
+  FULLTEXT KEY `ft_title_body` (`title`,`body`),
+  SPATIAL KEY `sp_loc` (`location`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
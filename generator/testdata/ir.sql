@@ -0,0 +1,25 @@
+CREATE TABLE `customers` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `email` varchar(255) NOT NULL COMMENT 'login email',
+  `active` bool NOT NULL DEFAULT true,
+  `tier` enum('free','pro') NOT NULL DEFAULT 'free',
+  `flags` set('a','b') DEFAULT NULL,
+  `balance` decimal(12,2) NOT NULL DEFAULT '0.00',
+  `bio` text,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_email` (`email`),
+  FULLTEXT KEY `ft_bio` (`bio`),
+  CONSTRAINT `chk_balance` CHECK (`balance` >= 0)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='The shop customers';
+
+CREATE TABLE `orders` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `customer_id` bigint unsigned NOT NULL,
+  `total` double NOT NULL,
+  `total_cents` bigint GENERATED ALWAYS AS (`total` * 100) STORED,
+  PRIMARY KEY (`id`),
+  KEY `idx_customer` (`customer_id`),
+  CONSTRAINT `fk_customer` FOREIGN KEY (`customer_id`) REFERENCES `customers` (`id`)
+);
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  name varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);
+
+-- +goose Down
+DROP TABLE users;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN name;
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE users ADD COLUMN name varchar(64) NOT NULL;
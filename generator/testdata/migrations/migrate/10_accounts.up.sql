@@ -0,0 +1,8 @@
+RENAME TABLE users TO accounts;
+ALTER TABLE accounts MODIFY COLUMN name varchar(128) NOT NULL;
+DROP TABLE sessions;
+CREATE TABLE audit (
+  id bigint NOT NULL AUTO_INCREMENT,
+  account_id bigint NOT NULL,
+  PRIMARY KEY (id)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  name varchar(64) NOT NULL,
+  legacy_flag tinyint NOT NULL DEFAULT 0,
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE sessions (
+  id bigint NOT NULL AUTO_INCREMENT,
+  token char(32) NOT NULL,
+  PRIMARY KEY (id)
+);
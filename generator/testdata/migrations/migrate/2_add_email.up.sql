@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN email varchar(255) NOT NULL AFTER name;
+ALTER TABLE users ADD UNIQUE KEY uk_email (email);
+ALTER TABLE users DROP COLUMN legacy_flag;
@@ -0,0 +1,126 @@
+--
+-- PostgreSQL database dump
+--
+
+-- Dumped from database version 15.4
+-- Dumped by pg_dump version 15.4
+
+SET statement_timeout = 0;
+SET lock_timeout = 0;
+SET client_encoding = 'UTF8';
+SET standard_conforming_strings = on;
+SELECT pg_catalog.set_config('search_path', '', false);
+SET check_function_bodies = false;
+SET client_min_messages = warning;
+
+CREATE TYPE public.order_status AS ENUM (
+    'pending',
+    'paid',
+    'shipped'
+);
+
+
+ALTER TYPE public.order_status OWNER TO shop;
+
+SET default_tablespace = '';
+
+SET default_table_access_method = heap;
+
+--
+-- Name: customers; Type: TABLE; Schema: public; Owner: shop
+--
+
+CREATE TABLE public.customers (
+    id bigint NOT NULL,
+    email character varying(255) NOT NULL,
+    name text,
+    balance numeric(12,2) DEFAULT 0 NOT NULL,
+    tags text[],
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.customers OWNER TO shop;
+
+COMMENT ON TABLE public.customers IS 'The shop customers';
+
+COMMENT ON COLUMN public.customers.email IS 'login email';
+
+CREATE SEQUENCE public.customers_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER TABLE public.customers_id_seq OWNER TO shop;
+
+ALTER SEQUENCE public.customers_id_seq OWNED BY public.customers.id;
+
+CREATE TABLE public.orders (
+    id integer NOT NULL,
+    customer_id bigint NOT NULL,
+    status public.order_status DEFAULT 'pending'::public.order_status NOT NULL,
+    total double precision NOT NULL,
+    shipped_at timestamp without time zone
+);
+
+
+ALTER TABLE public.orders OWNER TO shop;
+
+--
+-- Name: stores; Type: TABLE; Schema: public; Owner: shop
+--
+
+CREATE TABLE public.stores (
+    id bigint NOT NULL,
+    opens_at time without time zone NOT NULL,
+    closes_at time(0) without time zone,
+    cutoff time with time zone,
+    delivery_fee money DEFAULT 0 NOT NULL
+);
+
+
+ALTER TABLE public.stores OWNER TO shop;
+
+CREATE SEQUENCE public.orders_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.orders_id_seq OWNED BY public.orders.id;
+
+ALTER TABLE ONLY public.customers ALTER COLUMN id SET DEFAULT nextval('public.customers_id_seq'::regclass);
+
+ALTER TABLE ONLY public.orders ALTER COLUMN id SET DEFAULT nextval('public.orders_id_seq'::regclass);
+
+COPY public.customers (id, email, name, balance, tags, created_at) FROM stdin;
+1	ann@example.com	Ann	10.00	{vip}	2024-01-01 00:00:00+00
+\.
+
+SELECT pg_catalog.setval('public.customers_id_seq', 1, true);
+
+ALTER TABLE ONLY public.customers
+    ADD CONSTRAINT customers_email_key UNIQUE (email);
+
+ALTER TABLE ONLY public.customers
+    ADD CONSTRAINT customers_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.stores
+    ADD CONSTRAINT stores_pkey PRIMARY KEY (id);
+
+CREATE INDEX orders_customer_id_idx ON public.orders USING btree (customer_id);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_customer_id_fkey FOREIGN KEY (customer_id) REFERENCES public.customers(id);
+
+--
+-- PostgreSQL database dump complete
+--
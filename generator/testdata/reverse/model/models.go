@@ -0,0 +1,53 @@
+// Package model holds hand-written gorm models, the fixture of the
+// reverse golden test.
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Status string
+
+const (
+	StatusActive Status = "active"
+	StatusBanned Status = "banned"
+)
+
+type Address struct {
+	Street string `gorm:"size:128"`
+	City   string `gorm:"size:64;index:idx_city"`
+}
+
+type User struct {
+	ID        uint64  `gorm:"primaryKey;autoIncrement"`
+	Email     string  `gorm:"size:191;not null;uniqueIndex:uk_email"`
+	Nickname  *string `gorm:"size:32"`
+	Status    Status  `gorm:"default:active"`
+	Age       int     // years
+	Balance   float64 `gorm:"precision:12;scale:2"`
+	Bio       string  `gorm:"size:70000"`
+	Phone     sql.NullString
+	Address   Address   `gorm:"embedded;embeddedPrefix:addr_"`
+	Settings  chan int  // not a column type
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	Orders    []Order
+	secret    string
+}
+
+func (User) TableName() string {
+	return "app_users"
+}
+
+type Order struct {
+	gorm.Model
+	UserID uint64    `gorm:"index:idx_user_placed,priority:1"`
+	Placed time.Time `gorm:"index:idx_user_placed,priority:2;precision:6"`
+	Note   string    `gorm:"column:remark;type:text;comment:'free text'"`
+	Body   string    `gorm:"index:ft_body,class:FULLTEXT"`
+	Ignore string    `gorm:"-"`
+	User   *User
+}
@@ -0,0 +1,27 @@
+PRAGMA foreign_keys=OFF;
+BEGIN TRANSACTION;
+CREATE TABLE "users" (
+  "id" INTEGER PRIMARY KEY,
+  "email" TEXT NOT NULL UNIQUE,
+  `display name` VARCHAR(64),
+  [balance] NUMERIC NOT NULL DEFAULT 0 CHECK (balance >= 0),
+  score REAL,
+  avatar BLOB,
+  active BOOLEAN NOT NULL DEFAULT 1,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CHECK (length(email) > 3)
+);
+CREATE TABLE sessions (
+  token TEXT NOT NULL,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  expires_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (token)
+) WITHOUT ROWID;
+CREATE TABLE "audit_events" (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  kind TEXT NOT NULL CONSTRAINT kind_known CHECK (kind IN ('login', 'logout')),
+  payload,
+  counter UNSIGNED BIG INT
+);
+CREATE INDEX sessions_user ON sessions (user_id);
+COMMIT;
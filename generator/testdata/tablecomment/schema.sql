@@ -0,0 +1,27 @@
+CREATE TABLE `users` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='User account master table';
+
+CREATE TABLE `orders` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB COMMENT='Orders placed by the customers,
+one row per checkout.
+
+Rows are never deleted: cancelled orders keep their row with the cancelled state, so that the ledger can be rebuilt from them at any time.';
+
+CREATE TABLE `accounts` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户账户主表';
+
+CREATE TABLE `ledgers` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='账本流水表，记录每一笔资金变动，包括充值、提现、转账、退款以及手续费，按月归档到历史表中，归档后的数据只读，不允许修改或删除，如需更正请追加冲正记录';
+
+CREATE TABLE `sessions` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB;
@@ -0,0 +1,22 @@
+CREATE TABLE `events` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `created` date NOT NULL,
+  `name` varchar(64) NOT NULL,
+  PRIMARY KEY (`id`,`created`)
+) ENGINE=InnoDB AUTO_INCREMENT=1001 DEFAULT CHARSET=utf8mb4 COMMENT='partitioned by year'
+PARTITION BY RANGE (YEAR(`created`))
+(PARTITION p2023 VALUES LESS THAN (2024) ENGINE = InnoDB,
+ PARTITION p2024 VALUES LESS THAN (2025) ENGINE = InnoDB,
+ PARTITION pmax VALUES LESS THAN MAXVALUE ENGINE = InnoDB);
+
+CREATE TABLE `archives` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `body` mediumtext NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB AUTO_INCREMENT=42 ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8 TABLESPACE innodb_file_per_table COMMENT 'compressed archive';
+
+CREATE TABLE `all_archives` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `body` mediumtext NOT NULL,
+  KEY (`id`)
+) ENGINE=MRG_MyISAM AUTO_INCREMENT=7 UNION=(`archives`) INSERT_METHOD=LAST COMMENT='every archive';
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// trimTablePrefix strips the first matching -trim-prefix prefix from a
+// table name, unless that would leave no valid Go name.
+func trimTablePrefix(table string) string {
+	for _, prefix := range strings.Split(trimPrefix, ",") {
+		prefix = strings.TrimSpace(prefix)
+		if prefix == "" || !strings.HasPrefix(table, prefix) {
+			continue
+		}
+		rest := table[len(prefix):]
+		if r, _ := utf8.DecodeRuneInString(rest); unicode.IsLetter(r) {
+			return rest
+		}
+	}
+	return table
+}
+
+// modelName returns the Go name of the model of a table: t_order_items
+// becomes TOrderItems, or OrderItem with -trim-prefix t_ and -singularize.
+func modelName(table string) string {
+	return exportedIdent(ToCamelFirstUpper(singularize(trimTablePrefix(table))))
+}
+
+// checkModelNames reports the tables of a package whose models end up with
+// the same name, e.g. t_user and user under -trim-prefix t_.
+func checkModelNames(ddls []*sqlparser.DDL) error {
+	seen := make(map[string]string)
+	for _, ddl := range ddls {
+		table := ddl.NewName.Name.String()
+		key := tableGroup(table) + "." + modelName(table)
+		if other, ok := seen[key]; ok {
+			return fmt.Errorf("tables %s and %s both generate the model %s", other, table, modelName(table))
+		}
+		seen[key] = table
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const prefixedSchema = `CREATE TABLE t_user (id bigint NOT NULL, PRIMARY KEY (id));
+CREATE TABLE t_order_item (id bigint NOT NULL, PRIMARY KEY (id));
+CREATE TABLE tx_log (id bigint NOT NULL, PRIMARY KEY (id));
+CREATE TABLE audit (id bigint NOT NULL, PRIMARY KEY (id));
+CREATE TABLE t_2fa (id bigint NOT NULL, PRIMARY KEY (id));`
+
+func TestTrimPrefix(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", prefixedSchema)
+	if err := run("-trim-prefix", "t_, tx_", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		file, model, table string
+	}{
+		{"user", "User", "t_user"},
+		{"order_item", "OrderItem", "t_order_item"},
+		{"log", "Log", "tx_log"},
+		// no prefix to trim
+		{"audit", "Audit", "audit"},
+		// trimming would leave no valid Go name
+		{"t_2fa", "T2fa", "t_2fa"},
+	} {
+		src := readFile(t, modelPath(tt.file))
+		for _, want := range []string{"type " + tt.model + " struct {", "return \"" + tt.table + "\""} {
+			if !strings.Contains(src, want) {
+				t.Errorf("%s.go lacks %s:\n%s", tt.file, want, src)
+			}
+		}
+	}
+	if _, err := os.Stat(modelPath("t_user")); err == nil {
+		t.Error("t_user.go written under -trim-prefix")
+	}
+}
+
+func TestTrimPrefixCollision(t *testing.T) {
+	for _, tt := range []struct {
+		schema string
+		args   []string
+		want   string
+	}{
+		{
+			"CREATE TABLE t_user (id bigint NOT NULL, PRIMARY KEY (id));\n" +
+				"CREATE TABLE user (id bigint NOT NULL, PRIMARY KEY (id));",
+			[]string{"-trim-prefix", "t_"},
+			"tables t_user and user both generate the model User",
+		},
+		{
+			"CREATE TABLE t_users (id bigint NOT NULL, PRIMARY KEY (id));\n" +
+				"CREATE TABLE user (id bigint NOT NULL, PRIMARY KEY (id));",
+			[]string{"-trim-prefix", "t_", "-singularize"},
+			"tables t_users and user both generate the model User",
+		},
+	} {
+		chdir(t)
+		writeFile(t, "schema.sql", tt.schema)
+		err := run(append(tt.args, "schema.sql")...)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%v: got %v, want %s", tt.args, err, tt.want)
+		}
+		if _, err := os.Stat("model"); err == nil {
+			t.Errorf("%v: models written despite the collision", tt.args)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// TypeOverride replaces the generated Go type and/or gorm tag of a column.
+type TypeOverride struct {
+	// Type is the Go type of the field, e.g. "decimal.Decimal".
+	Type string `json:"type" yaml:"type"`
+	// Import is the package path Type needs, e.g. "github.com/shopspring/decimal".
+	Import string `json:"import" yaml:"import"`
+	// Tag is merged into the generated gorm tag, its options win over the
+	// generated ones with the same key.
+	Tag string `json:"tag" yaml:"tag"`
+	// ReplaceTag makes Tag replace the generated gorm tag entirely.
+	ReplaceTag bool `json:"replace_tag" yaml:"replace_tag"`
+	// Rename is the Go field name of a column, for legacy names like
+	// usr_nm; the tags keep the column name.
+	Rename string `json:"rename" yaml:"rename"`
+	// Skip leaves a column out of the generated code entirely.
+	Skip bool `json:"skip" yaml:"skip"`
+}
+
+// PatternOverride is a TypeOverride of the columns whose name matches
+// Pattern.
+type PatternOverride struct {
+	// Pattern is a regular expression matched against the column name,
+	// e.g. "_cents$".
+	Pattern string `json:"pattern"`
+	TypeOverride
+
+	re *regexp.Regexp
+}
+
+// TypeMap is the -type-map file:
+//
+//	{
+//	  "types": {"decimal": {"type": "decimal.Decimal", "import": "github.com/shopspring/decimal"}},
+//	  "patterns": [
+//	    {"pattern": "_cents$", "type": "int64"},
+//	    {"pattern": "_amount$", "type": "decimal.Decimal", "import": "github.com/shopspring/decimal"}
+//	  ],
+//	  "columns": {
+//	    "users.email": {"tag": "index:,sort:desc"},
+//	    "users.usr_nm": {"rename": "UserName"},
+//	    "users.raw_payload": {"skip": true}
+//	  }
+//	}
+//
+// Column overrides take precedence over pattern overrides, the first
+// matching one, which take precedence over SQL type overrides, and all of
+// them over the built-in mapping. Only column overrides may rename or skip.
+type TypeMap struct {
+	// Types overrides by SQL base type.
+	Types map[string]TypeOverride `json:"types"`
+	// Patterns overrides by column name pattern, in order.
+	Patterns []PatternOverride `json:"patterns"`
+	// Columns overrides by table.column.
+	Columns map[string]TypeOverride `json:"columns"`
+}
+
+// patternOverride returns the override of the first pattern matching
+// column.
+func (m TypeMap) patternOverride(column string) (TypeOverride, bool) {
+	for _, p := range m.Patterns {
+		if p.re.MatchString(column) {
+			return p.TypeOverride, true
+		}
+	}
+	return TypeOverride{}, false
+}
+
+var (
+	typeMap TypeMap
+	// columnOverrides holds the effective override of each parsed column.
+	columnOverrides = make(map[*sqlparser.ColumnDefinition]TypeOverride)
+	// goTypeImports maps a Go type to the package it needs.
+	goTypeImports = map[string]string{
+		"time.Time":        "time",
+		"time.Duration":    "time",
+		"json.RawMessage":  "encoding/json",
+		"uuid.UUID":        "github.com/google/uuid",
+		"net.IP":           "net",
+		"net.IPNet":        "net",
+		"net.HardwareAddr": "net",
+		"pq.StringArray":   "github.com/lib/pq",
+		"pq.Int64Array":    "github.com/lib/pq",
+		"pq.Float64Array":  "github.com/lib/pq",
+		"pq.BoolArray":     "github.com/lib/pq",
+		"pq.ByteaArray":    "github.com/lib/pq",
+	}
+)
+
+func loadTypeMap(file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, &typeMap); err != nil {
+		return fmt.Errorf("type map %s: %v", file, err)
+	}
+	for typ, o := range typeMap.Types {
+		if o.Rename != "" || o.Skip {
+			return fmt.Errorf("type map %s: type %s: rename and skip apply to columns only", file, typ)
+		}
+	}
+	for i := range typeMap.Patterns {
+		p := &typeMap.Patterns[i]
+		if p.Rename != "" || p.Skip {
+			return fmt.Errorf("type map %s: pattern %s: rename and skip apply to columns only", file, p.Pattern)
+		}
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return fmt.Errorf("type map %s: pattern %s: %v", file, p.Pattern, err)
+		}
+		p.re = re
+	}
+	for column, o := range typeMap.Columns {
+		if o.Rename != "" && (!token.IsIdentifier(o.Rename) || !token.IsExported(o.Rename)) {
+			return fmt.Errorf("type map %s: column %s: rename %q is not an exported Go identifier", file, column, o.Rename)
+		}
+	}
+	return nil
+}
+
+// applyTypeMap resolves the override of every column of the parsed tables
+// and drops the skipped columns. A column override naming a column that a
+// generated table does not have is an error; those of the tables left out
+// of the schema or excluded are ignored.
+func applyTypeMap(ddls []*sqlparser.DDL) error {
+	tables := make(map[string]*sqlparser.DDL)
+	for _, ddl := range ddls {
+		tables[ddl.NewName.Name.String()] = ddl
+	}
+	for key := range typeMap.Columns {
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			return fmt.Errorf("%s: column override %s is not table.column", typeMapSource(key), key)
+		}
+		if ddl, ok := tables[key[:i]]; ok && findColumn(ddl, key[i+1:]) == nil {
+			return fmt.Errorf("%s: table %s has no column %s", typeMapSource(key), key[:i], key[i+1:])
+		}
+	}
+	for _, ddl := range ddls {
+		table := ddl.NewName.Name.String()
+		pk := make(map[string]bool)
+		for _, c := range primaryKeyColumns(ddl) {
+			pk[c.Name] = true
+		}
+		columns := ddl.TableSpec.Columns[:0]
+		for _, c := range ddl.TableSpec.Columns {
+			o, ok := typeMap.Columns[table+"."+c.Name.String()]
+			if !ok {
+				o, ok = typeMap.patternOverride(c.Name.String())
+			}
+			if !ok {
+				o, ok = typeMap.Types[c.Type.Type]
+			}
+			if o.Skip {
+				if pk[c.Name.String()] {
+					return fmt.Errorf("%s: cannot skip %s.%s, it is part of the primary key", typeMapSource(table+"."+c.Name.String()), table, c.Name.String())
+				}
+				continue
+			}
+			columns = append(columns, c)
+			if !ok {
+				continue
+			}
+			columnOverrides[c] = o
+			if o.Type != "" && o.Import != "" {
+				// keyed as importsOf looks it up, e.g. *decimal.Decimal
+				// by decimal.Decimal
+				goTypeImports[strings.TrimLeft(o.Type, "*[]")] = o.Import
+			}
+		}
+		ddl.TableSpec.Columns = columns
+	}
+	return nil
+}
+
+// importsOf returns the sorted package paths needed by the given Go types.
+func importsOf(goTypes ...string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, t := range goTypes {
+		t = strings.TrimLeft(t, "*[]")
+		if p, ok := goTypeImports[t]; ok && !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func uniqueSorted(paths []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, p := range paths {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// standardImport reports whether an import path is of the standard
+// library, as goimports tells: its first element has no dot.
+func standardImport(p string) bool {
+	return !strings.Contains(strings.SplitN(p, "/", 2)[0], ".")
+}
+
+// stdImports and thirdPartyImports filter paths, keeping their order.
+func stdImports(paths []string) []string {
+	var std []string
+	for _, p := range paths {
+		if standardImport(p) {
+			std = append(std, p)
+		}
+	}
+	return std
+}
+
+func thirdPartyImports(paths []string) []string {
+	var other []string
+	for _, p := range paths {
+		if !standardImport(p) {
+			other = append(other, p)
+		}
+	}
+	return other
+}
+
+// importFuncs split the import paths of a template into the standard
+// library group and the third-party one.
+var importFuncs = template.FuncMap{
+	"std":        stdImports,
+	"thirdParty": thirdPartyImports,
+}
+
+// renderImports renders the import declaration of paths, grouping the
+// standard library imports apart from the third-party ones as goimports
+// does.
+func renderImports(paths []string) string {
+	switch len(paths) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("import %q\n", paths[0])
+	}
+	var b strings.Builder
+	b.WriteString("import (\n")
+	std, other := stdImports(paths), thirdPartyImports(paths)
+	for _, p := range std {
+		fmt.Fprintf(&b, "\t%q\n", p)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		b.WriteString("\n")
+	}
+	for _, p := range other {
+		fmt.Fprintf(&b, "\t%q\n", p)
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// mergeGormTag merges the options of override into the gorm tag options of
+// generated. Options are keyed by the text before ':', override wins. A
+// field may have several indexes, so index options are keyed by the index
+// name as well.
+func mergeGormTag(generated, override string) string {
+	var opts []string
+	index := make(map[string]int)
+	add := func(opt string) {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			return
+		}
+		key := strings.ToLower(strings.SplitN(opt, ":", 2)[0])
+		if key == "index" || key == "uniqueindex" {
+			key = strings.ToLower(strings.SplitN(opt, ",", 2)[0])
+		}
+		if i, ok := index[key]; ok {
+			opts[i] = opt
+			return
+		}
+		index[key] = len(opts)
+		opts = append(opts, opt)
+	}
+	for _, opt := range splitGormTag(generated) {
+		add(opt)
+	}
+	for _, opt := range splitGormTag(override) {
+		add(opt)
+	}
+	return strings.Join(opts, ";")
+}
+
+// splitGormTag splits a gorm tag into its options at the semicolons not
+// escaped as \;, like gorm does.
+func splitGormTag(tag string) []string {
+	var opts []string
+	start := 0
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == ';' && (i == 0 || tag[i-1] != '\\') {
+			opts = append(opts, tag[start:i])
+			start = i + 1
+		}
+	}
+	return append(opts, tag[start:])
+}
@@ -0,0 +1,220 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const typeMapSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  state varchar(16) NOT NULL,
+  note text,
+  price decimal(10,2) NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+const typeMapJSON = `{
+  "types": {"decimal": {"type": "decimal.Decimal", "import": "github.com/shopspring/decimal"}},
+  "columns": {
+    "users.email": {"tag": "index:,sort:desc"},
+    "users.state": {"tag": "size:32"},
+    "users.note": {"tag": "column:note;type:text", "replace_tag": true}
+  }
+}`
+
+const typeMapTest = `package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestTagOverrides(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Users{}); err != nil {
+		t.Fatal(err)
+	}
+	if !db.Migrator().HasIndex(&Users{}, "idx_users_email") {
+		t.Error("the index of the email tag override was not created")
+	}
+	if err := db.Create(&Users{Email: "a@example.com", Note: "hi", Price: decimal.RequireFromString("1.25")}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var got Users
+	if err := db.First(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Note != "hi" || !got.Price.Equal(decimal.RequireFromString("1.25")) {
+		t.Errorf("got %+v", got)
+	}
+	f, _ := reflect.TypeOf(Users{}).FieldByName("Note")
+	if tag := f.Tag.Get("gorm"); tag != "column:note;type:text" {
+		t.Errorf("note tag %q, want the replacement", tag)
+	}
+}
+`
+
+func TestTypeMapTags(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", typeMapSchema)
+	writeFile(t, "types.json", typeMapJSON)
+	if err := run("-type-map", "types.json", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	users := gofmt(t, readFile(t, modelPath("users")))
+	for _, want := range []string{
+		// merged into the generated tag
+		"Email string          `gorm:\"Column:email;size:255;index:,sort:desc\" json:\"email\"`",
+		// the override wins over the generated option of the same key
+		"State string          `gorm:\"Column:state;size:32\" json:\"state\"`",
+		// replace_tag drops the generated tag
+		"Note  string          `gorm:\"column:note;type:text\" json:\"note\"`",
+		"Price decimal.Decimal `gorm:\"Column:price\" json:\"price\"`",
+	} {
+		if !strings.Contains(users, want) {
+			t.Errorf("no %s in:\n%s", want, users)
+		}
+	}
+	writeFile(t, "model/users_test.go", typeMapTest)
+	goTest(t, "./model")
+}
+
+func TestRenderImports(t *testing.T) {
+	for _, tt := range []struct {
+		paths []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"time"}, "import \"time\"\n"},
+		{[]string{"github.com/google/uuid"}, "import \"github.com/google/uuid\"\n"},
+		{[]string{"database/sql", "time"}, "import (\n\t\"database/sql\"\n\t\"time\"\n)\n"},
+		{[]string{"github.com/google/uuid", "github.com/shopspring/decimal"},
+			"import (\n\t\"github.com/google/uuid\"\n\t\"github.com/shopspring/decimal\"\n)\n"},
+		{[]string{"time", "github.com/google/uuid"}, "import (\n\t\"time\"\n\n\t\"github.com/google/uuid\"\n)\n"},
+	} {
+		if got := renderImports(tt.paths); got != tt.want {
+			t.Errorf("%q: got\n%s\nwant\n%s", tt.paths, got, tt.want)
+		}
+	}
+}
+
+func TestImportGroups(t *testing.T) {
+	const schema = `CREATE TABLE sessions (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (id)
+);`
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	if err := run("-dialect", "postgres", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	// as written, before gofmt
+	sessions := readFile(t, modelPath("sessions"))
+	if want := "import (\n\t\"time\"\n\n\t\"github.com/google/uuid\"\n)\n"; !strings.Contains(sessions, want) {
+		t.Errorf("no two import groups in:\n%s", sessions)
+	}
+}
+
+const patternSchema = `CREATE TABLE orders (
+  id bigint NOT NULL AUTO_INCREMENT,
+  total_cents varchar(20) NOT NULL,
+  fee_cents varchar(20),
+  total_amount varchar(32) NOT NULL,
+  tax_amount varchar(32) NOT NULL,
+  amount_note varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+const patternJSON = `{
+  "patterns": [
+    {"pattern": "_cents$", "type": "int64"},
+    {"pattern": "_amount$", "type": "decimal.Decimal", "import": "github.com/shopspring/decimal"},
+    {"pattern": "^total_", "type": "string"}
+  ],
+  "columns": {"orders.tax_amount": {"type": "string"}}
+}`
+
+const patternTest = `package model
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestPatterns(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Orders{}); err != nil {
+		t.Fatal(err)
+	}
+	o := Orders{TotalCents: 1999, FeeCents: 25, TotalAmount: decimal.RequireFromString("19.99"), TaxAmount: "1.60"}
+	if err := db.Create(&o).Error; err != nil {
+		t.Fatal(err)
+	}
+	var got Orders
+	if err := db.First(&got, o.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.TotalCents != 1999 || got.FeeCents != 25 || !got.TotalAmount.Equal(o.TotalAmount) {
+		t.Errorf("got %+v", got)
+	}
+}
+`
+
+func TestTypeMapPatterns(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", patternSchema)
+	writeFile(t, "types.json", patternJSON)
+	if err := run("-type-map", "types.json", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	orders := gofmt(t, readFile(t, modelPath("orders")))
+	for _, want := range []string{
+		// the first matching pattern wins, before the varchar mapping
+		"TotalCents  int64           `gorm:\"Column:total_cents;size:20\" json:\"total_cents\"`",
+		"FeeCents    int64           `gorm:\"Column:fee_cents;size:20\" json:\"fee_cents\"`",
+		"TotalAmount decimal.Decimal `gorm:\"Column:total_amount;size:32\" json:\"total_amount\"`",
+		// a column override wins over the patterns
+		"TaxAmount   string          `gorm:\"Column:tax_amount;size:32\" json:\"tax_amount\"`",
+		// patterns match the column name, not the type
+		"AmountNote  string          `gorm:\"Column:amount_note;size:64\" json:\"amount_note\"`",
+		"\"github.com/shopspring/decimal\"",
+	} {
+		if !strings.Contains(orders, want) {
+			t.Errorf("no %s in:\n%s", want, orders)
+		}
+	}
+	writeFile(t, "model/orders_test.go", patternTest)
+	goTest(t, "./model")
+}
+
+func TestTypeMapPatternErrors(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", patternSchema)
+	for _, tt := range []struct {
+		typeMap, want string
+	}{
+		{`{"patterns": [{"pattern": "(_cents", "type": "int64"}]}`, "type map types.json: pattern (_cents: error parsing regexp: missing closing ): `(_cents`"},
+		{`{"patterns": [{"pattern": "_cents$", "rename": "Cents"}]}`, "type map types.json: pattern _cents$: rename and skip apply to columns only"},
+		{`{"patterns": [{"pattern": "_cents$", "skip": true}]}`, "type map types.json: pattern _cents$: rename and skip apply to columns only"},
+	} {
+		writeFile(t, "types.json", tt.typeMap)
+		if err := run("-type-map", "types.json", "schema.sql"); err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.typeMap, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// typesFileName returns the -types-file name without its extension.
+func typesFileName() string {
+	return strings.TrimSuffix(strings.TrimSuffix(typesFile, outExt), ".go")
+}
+
+// checkTypesFile fails when the -types-file of a group would overwrite the
+// file of one of its tables.
+func checkTypesFile(ddls []*sqlparser.DDL) error {
+	name := typesFileName()
+	for _, ddl := range ddls {
+		if table := ddl.NewName.Name.String(); trimTablePrefix(table) == name {
+			return fmt.Errorf("-types-file %s is also the file of table %s", typesFile, table)
+		}
+	}
+	return nil
+}
+
+// packageEnums returns the enum and set types of the tables of a package.
+// A type declared twice, which only happens when two model names collide,
+// is kept once; the second declaration is dropped with a warning when its
+// members differ.
+func packageEnums(ddls []*sqlparser.DDL) []enumType {
+	var enums []enumType
+	seen := make(map[string]enumType)
+	for _, ddl := range ddls {
+		for _, e := range tableEnums(ddl) {
+			if first, ok := seen[e.Type]; ok {
+				if first.Set != e.Set || !reflect.DeepEqual(first.Members, e.Members) {
+					fmt.Fprintf(os.Stderr, "warning: type %s of %s.%s differs from that of %s.%s, keeping the first\n",
+						e.Type, e.Table, e.Column, first.Table, first.Column)
+				}
+				continue
+			}
+			seen[e.Type] = e
+			enums = append(enums, e)
+		}
+	}
+	return enums
+}
+
+// genTypesFile renders the -types-file of a package, "" if its tables have
+// no enum or set column.
+func genTypesFile(pkg string, ddls []*sqlparser.DDL) string {
+	enums := packageEnums(ddls)
+	if len(enums) == 0 {
+		return ""
+	}
+	return "package " + pkg + "\n\n" + renderImports(enumImports(enums)) + "\n" + genEnumTypes(enums)
+}
@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const typesFileSchema = "CREATE TABLE `orders` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `state` enum('new','paid') NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");\n" +
+	"CREATE TABLE `invoices` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `state` enum('draft','sent') NOT NULL,\n" +
+	"  `flags` set('urgent','copy') NOT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	");"
+
+const typesFileTest = `package model
+
+import "testing"
+
+func TestTypesFile(t *testing.T) {
+	o := Orders{State: OrdersStatePaid}
+	i := Invoices{State: InvoicesStateSent, Flags: InvoicesFlags{InvoicesFlagsUrgent}}
+	if !o.State.Valid() || !i.State.Valid() {
+		t.Errorf("%v and %v not valid", o.State, i.State)
+	}
+}
+`
+
+func TestTypesFile(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", typesFileSchema)
+	if err := run("-types-file", "types.go", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	types := gofmt(t, readFile(t, "model/types.go"))
+	for _, want := range []string{"type OrdersState string", "type InvoicesState string", "type InvoicesFlags []string"} {
+		if strings.Count(types, want) != 1 {
+			t.Errorf("types.go lacks %s:\n%s", want, types)
+		}
+	}
+	for _, table := range []string{"orders", "invoices"} {
+		if m := readFile(t, modelPath(table)); strings.Contains(m, " string\n") || strings.Contains(m, "func (e ") {
+			t.Errorf("%s.go still declares its types:\n%s", table, m)
+		}
+	}
+	writeFile(t, "model/types_file_test.go", typesFileTest)
+	goTest(t, "./model")
+}
+
+func TestTypesFileShared(t *testing.T) {
+	const schema = `CREATE TYPE public.status AS ENUM ('active', 'closed');
+CREATE TABLE public.accounts (
+    id bigint NOT NULL,
+    status public.status NOT NULL
+);
+CREATE TABLE public.cards (
+    id bigint NOT NULL,
+    status public.status
+);`
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	if err := run("-dialect", "postgres", "-types-file", "types.go", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	types := readFile(t, "model/types.go")
+	if n := strings.Count(types, "type Status string"); n != 1 {
+		t.Errorf("Status declared %d times:\n%s", n, types)
+	}
+	for _, table := range []string{"accounts", "cards"} {
+		if m := gofmt(t, readFile(t, modelPath(table))); !strings.Contains(m, "Status Status `") {
+			t.Errorf("%s.go does not use Status:\n%s", table, m)
+		}
+	}
+	goTest(t, "./model")
+}
+
+func TestTypesFileTableClash(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", typesFileSchema)
+	if err := run("-types-file", "orders.go", "schema.sql"); err == nil || !strings.Contains(err.Error(), "also the file of table orders") {
+		t.Errorf("got %v", err)
+	}
+}
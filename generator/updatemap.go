@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const updateMapTemplate = `
+package {{.Package}}
+
+// UpdateMap returns the columns of m keyed by name, for
+// db.Model(&m).Updates(m.UpdateMap()), which unlike Updates(m) writes the
+// zero values as well. The primary key is left out, and so are the pointer
+// fields that are nil.
+func (m {{.TableName}}) UpdateMap() map[string]interface{} {
+	updates := make(map[string]interface{})
+{{- range .Fields}}
+{{- if .Pointer}}
+	if m.{{.Field}} != nil {
+		updates["{{.Name}}"] = m.{{.Field}}
+	}
+{{- else}}
+	updates["{{.Name}}"] = m.{{.Field}}
+{{- end}}
+{{- end}}
+	return updates
+}
+`
+
+type updateMapField struct {
+	dalColumn
+	// Pointer marks the pointer fields, of -nullable-pointers or a
+	// -type-map override, which are only set when not nil.
+	Pointer bool
+}
+
+func genUpdateMap(pkg string, ddl *sqlparser.DDL) string {
+	pk := make(map[string]bool)
+	for _, c := range primaryKeyColumns(ddl) {
+		pk[c.Name] = true
+	}
+	var fields []updateMapField
+	for _, c := range ddl.TableSpec.Columns {
+		if pk[c.Name.String()] || generatedColumns[c] {
+			continue
+		}
+		f := updateMapField{dalColumn: newDALColumn(c)}
+		f.Pointer = strings.HasPrefix(f.Type, "*")
+		fields = append(fields, f)
+	}
+
+	params := struct {
+		Package   string
+		TableName string
+		Fields    []updateMapField
+	}{
+		Package:   pkg,
+		TableName: modelName(ddl.NewName.Name.String()),
+		Fields:    fields,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("updatemap").Parse(updateMapTemplate)).Execute(&buf, params)
+	return buf.String()
+}
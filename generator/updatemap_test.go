@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const nullableSchema = `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  nickname varchar(64) DEFAULT NULL,
+  age int,
+  avatar blob,
+  born_at datetime NULL,
+  PRIMARY KEY (id)
+);`
+
+const updateMapTest = `package model
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestUpdateMap(t *testing.T) {
+	nickname := "ann"
+	u := Users{ID: 1, Email: "a@example.com", Nickname: &nickname}
+	m := u.UpdateMap()
+	if _, ok := m["id"]; ok {
+		t.Error("the primary key is in the map")
+	}
+	if m["email"] != "a@example.com" {
+		t.Errorf("email = %v", m["email"])
+	}
+	if p, ok := m["nickname"].(*string); !ok || *p != "ann" {
+		t.Errorf("nickname = %v", m["nickname"])
+	}
+	// nil pointers are not set
+	for _, column := range []string{"age", "born_at"} {
+		if v, ok := m[column]; ok {
+			t.Errorf("%s = %v, want it left out", column, v)
+		}
+	}
+	// slices are always set
+	if _, ok := m["avatar"]; !ok {
+		t.Error("avatar left out")
+	}
+}
+
+func TestUpdateMapSQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Users{}); err != nil {
+		t.Fatal(err)
+	}
+	age, born := 30, time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+	if err := db.Create(&Users{Email: "a@example.com", Age: &age, BornAt: &born}).Error; err != nil {
+		t.Fatal(err)
+	}
+	nickname := "ann"
+	patch := Users{ID: 1, Email: "b@example.com", Nickname: &nickname}
+	if err := db.Model(&Users{ID: 1}).Updates(patch.UpdateMap()).Error; err != nil {
+		t.Fatal(err)
+	}
+	var got Users
+	if err := db.First(&got, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Email != "b@example.com" || got.Nickname == nil || *got.Nickname != "ann" {
+		t.Errorf("got %+v, want the email and nickname updated", got)
+	}
+	// the nil pointers left the columns alone
+	if got.Age == nil || *got.Age != 30 || got.BornAt == nil || !got.BornAt.Equal(born) {
+		t.Errorf("got %+v, want age and born_at untouched", got)
+	}
+}
+`
+
+func TestUpdateMapNullablePointers(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", nullableSchema)
+	if err := run("-nullable-pointers", "-gen-updatemap", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	users := gofmt(t, readFile(t, modelPath("users")))
+	for _, want := range []string{
+		"ID       int64 ",
+		"Email    string ",
+		"Nickname *string ",
+		"Age      *int ",
+		"Avatar   []byte ",
+		"BornAt   *time.Time ",
+	} {
+		if !strings.Contains(users, want) {
+			t.Errorf("no %q in:\n%s", want, users)
+		}
+	}
+	writeFile(t, "model/updatemap_test.go", updateMapTest)
+	goTest(t, "./model")
+}
+
+func TestNullablePointersOff(t *testing.T) {
+	users := generate(t, nullableSchema, "users")
+	if strings.Contains(users, "*") {
+		t.Errorf("pointer fields without -nullable-pointers:\n%s", users)
+	}
+}
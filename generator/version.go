@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const versionTemplate = `
+package {{.Package}}
+
+// SchemaVersion is the SHA-256 of the normalized definitions of the tables
+// generated into this package, for detecting drift between the schema and
+// the generated code at runtime.
+const SchemaVersion = "{{.Version}}"
+`
+
+// schemaVersion hashes the normalized definitions of ddls. Neither the
+// table order of the schema nor the column order of its tables changes the
+// hash, which only follows what the database holds.
+func schemaVersion(ddls []*sqlparser.DDL) string {
+	defs := make([]string, 0, len(ddls))
+	for _, ddl := range ddls {
+		columns := append([]*sqlparser.ColumnDefinition(nil), ddl.TableSpec.Columns...)
+		sort.Slice(columns, func(i, j int) bool { return columns[i].Name.Lowered() < columns[j].Name.Lowered() })
+		defs = append(defs, columnsDefinition(ddl, columns))
+	}
+	sort.Strings(defs)
+	sum := sha256.Sum256([]byte(strings.Join(defs, "\n\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// tableDefinition normalizes the definition of a table, including what
+// the preprocessing took out of its statement.
+func tableDefinition(ddl *sqlparser.DDL) string {
+	return columnsDefinition(ddl, ddl.TableSpec.Columns)
+}
+
+// columnsDefinition is tableDefinition with the columns of the table in
+// the order given.
+func columnsDefinition(ddl *sqlparser.DDL, columns []*sqlparser.ColumnDefinition) string {
+	spec := *ddl.TableSpec
+	spec.Columns = columns
+	var b strings.Builder
+	b.WriteString(sqlparser.String(&spec))
+	for _, c := range columns {
+		if boolColumns[c] {
+			b.WriteString("\nbool " + c.Name.String())
+		}
+		if generatedColumns[c] {
+			b.WriteString("\ngenerated " + c.Name.String())
+		}
+		if check, ok := columnChecks[c]; ok {
+			b.WriteString("\ncheck " + c.Name.String() + " " + check.Name + " (" + check.Expr + ")")
+		}
+	}
+	for _, idx := range ddl.TableSpec.Indexes {
+		if fulltextIndexes[idx] {
+			b.WriteString("\nfulltext " + indexName(ddl, idx))
+		}
+	}
+	for _, fk := range tableForeignKeys[ddl] {
+		b.WriteString("\nforeign key (" + strings.Join(fk.Columns, ", ") + ") references " +
+			fk.RefSchema + "." + fk.RefTable + " (" + strings.Join(fk.RefColumns, ", ") + ")")
+	}
+	for _, check := range tableChecks[ddl] {
+		b.WriteString("\ncheck " + check.Name + " (" + check.Expr + ")")
+	}
+	if viewTables[ddl] {
+		b.WriteString("\nview")
+	}
+	return ddl.NewName.Name.String() + " " + b.String()
+}
+
+// versionFileName returns the name of the -gen-version file of a group,
+// version unless a table of the group already generates version.go.
+func versionFileName(ddls []*sqlparser.DDL) string {
+	for _, ddl := range ddls {
+		if trimTablePrefix(ddl.NewName.Name.String()) == "version" {
+			return "dalgen_version"
+		}
+	}
+	return "version"
+}
+
+func genVersion(pkg string, ddls []*sqlparser.DDL) string {
+	params := struct {
+		Package string
+		Version string
+	}{pkg, schemaVersion(ddls)}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("version").Parse(versionTemplate)).Execute(&buf, params)
+	return buf.String()
+}
@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"regexp"
+	"testing"
+)
+
+var schemaVersionConst = regexp.MustCompile(`const SchemaVersion = "([0-9a-f]{64})"`)
+
+// generatedVersion returns the SchemaVersion -gen-version generates for
+// the schema.
+func generatedVersion(t *testing.T, schema string) string {
+	t.Helper()
+	chdir(t)
+	writeFile(t, "schema.sql", schema)
+	if err := run("-gen-version", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	version := readFile(t, "model/version.go")
+	m := schemaVersionConst.FindStringSubmatch(version)
+	if m == nil {
+		t.Fatalf("no SchemaVersion in:\n%s", version)
+	}
+	return m[1]
+}
+
+func TestSchemaVersion(t *testing.T) {
+	want := generatedVersion(t, `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  age int,
+  PRIMARY KEY (id)
+);
+CREATE TABLE orders (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_id bigint NOT NULL,
+  PRIMARY KEY (id)
+);`)
+	// the tables and the columns in another order
+	if got := generatedVersion(t, `CREATE TABLE orders (
+  user_id bigint NOT NULL,
+  id bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (id)
+);
+CREATE TABLE users (
+  age int,
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  PRIMARY KEY (id)
+);`); got != want {
+		t.Errorf("reordering the schema changed the version from %s to %s", want, got)
+	}
+	// users.age is a bigint
+	if got := generatedVersion(t, `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  age bigint,
+  PRIMARY KEY (id)
+);
+CREATE TABLE orders (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_id bigint NOT NULL,
+  PRIMARY KEY (id)
+);`); got == want {
+		t.Error("changing the type of a column left the version unchanged")
+	}
+}
@@ -0,0 +1,269 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// viewExprType is the SQL type of the view columns whose type cannot be
+// derived; GoType maps it to interface{}, a -type-map column override of
+// view.column can do better.
+const viewExprType = "dalgen_expression"
+
+// viewTables holds the -views structs, which are read-only.
+var viewTables = make(map[*sqlparser.DDL]bool)
+
+// createView matches the head of a CREATE [OR REPLACE] [ALGORITHM=...]
+// [DEFINER=...] [SQL SECURITY ...] VIEW statement.
+var createView = regexp.MustCompile(`(?is)^\s*create\s+(or\s+replace\s+)?(algorithm\s*=\s*\w+\s+)?(definer\s*=\s*\S+\s+)?(sql\s+security\s+\w+\s+)?view\s`)
+
+// splitCreateView returns the name, the declared column names and the
+// SELECT of a CREATE VIEW statement; ok is false for other statements.
+func splitCreateView(stmt string) (name string, columns []string, sel string, ok bool) {
+	m := createView.FindStringIndex(stmt)
+	if m == nil {
+		return "", nil, "", false
+	}
+	rest := stmt[m[1]:]
+	name, rest = sqlWord(rest)
+	if strings.HasPrefix(strings.TrimSpace(rest), ".") {
+		name, rest = sqlWord(strings.TrimSpace(rest)[1:])
+	}
+	if strings.HasPrefix(strings.TrimSpace(rest), "(") {
+		var err error
+		if columns, rest, err = sqlColumnList(rest); err != nil {
+			return "", nil, "", false
+		}
+	}
+	rest, ok = sqlKeyword(rest, "as")
+	return tableIdent(name), columns, strings.TrimSpace(rest), ok
+}
+
+// viewSource is a table of the FROM clause of a view.
+type viewSource struct {
+	alias string
+	ddl   *sqlparser.DDL
+}
+
+// viewSources lists the tables of a FROM clause, nil entries standing for
+// derived tables and unknown tables.
+func viewSources(ddls []*sqlparser.DDL, exprs sqlparser.TableExprs) []viewSource {
+	var sources []viewSource
+	for _, expr := range exprs {
+		switch e := expr.(type) {
+		case *sqlparser.AliasedTableExpr:
+			s := viewSource{alias: e.As.String()}
+			if t, ok := e.Expr.(sqlparser.TableName); ok {
+				if s.alias == "" {
+					s.alias = t.Name.String()
+				}
+				s.ddl = findTable(ddls, t.Name.String())
+			}
+			sources = append(sources, s)
+		case *sqlparser.JoinTableExpr:
+			sources = append(sources, viewSources(ddls, sqlparser.TableExprs{e.LeftExpr, e.RightExpr})...)
+		case *sqlparser.ParenTableExpr:
+			sources = append(sources, viewSources(ddls, e.Exprs)...)
+		}
+	}
+	return sources
+}
+
+// viewColumn copies the type of a table column into a view column, leaving
+// out the keys, defaults and auto increment of the table.
+func viewColumn(name string, src *sqlparser.ColumnDefinition) *sqlparser.ColumnDefinition {
+	t := src.Type
+	c := &sqlparser.ColumnDefinition{Name: sqlparser.NewColIdent(name), Type: sqlparser.ColumnType{
+		Type:       t.Type,
+		NotNull:    t.NotNull,
+		Comment:    t.Comment,
+		Length:     t.Length,
+		Unsigned:   t.Unsigned,
+		Zerofill:   t.Zerofill,
+		Scale:      t.Scale,
+		Charset:    t.Charset,
+		Collate:    t.Collate,
+		EnumValues: t.EnumValues,
+	}}
+	if boolColumns[src] {
+		boolColumns[c] = true
+	}
+	return c
+}
+
+// parseView derives a struct from a CREATE VIEW statement over the tables
+// parsed so far. The columns selected from them keep their types, any other
+// expression becomes an interface{} field, with a warning.
+func parseView(ddls []*sqlparser.DDL, name string, declared []string, sel string) (*sqlparser.DDL, error) {
+	stmt, err := sqlparser.Parse(sel)
+	if err != nil {
+		return nil, fmt.Errorf("view %s: %v", name, err)
+	}
+	for {
+		if u, ok := stmt.(*sqlparser.Union); ok {
+			// the columns of a union are those of its first select
+			stmt = u.Left
+			continue
+		}
+		if p, ok := stmt.(*sqlparser.ParenSelect); ok {
+			stmt = p.Select
+			continue
+		}
+		break
+	}
+	s, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return nil, fmt.Errorf("view %s: not a select", name)
+	}
+	warn := func(format string, args ...interface{}) {
+		fmt.Fprintf(os.Stderr, "warning: view %s: %s\n", name, fmt.Sprintf(format, args...))
+	}
+	sources := viewSources(ddls, s.From)
+	var columns []*sqlparser.ColumnDefinition
+	untyped := func(column, expr string) {
+		warn("cannot type %s, using interface{}", expr)
+		columns = append(columns, &sqlparser.ColumnDefinition{
+			Name: sqlparser.NewColIdent(column),
+			Type: sqlparser.ColumnType{Type: viewExprType},
+		})
+	}
+	for _, expr := range s.SelectExprs {
+		switch e := expr.(type) {
+		case *sqlparser.StarExpr:
+			qualifier := e.TableName.Name.String()
+			for _, src := range sources {
+				if qualifier != "" && src.alias != qualifier {
+					continue
+				}
+				if src.ddl == nil {
+					warn("cannot expand %s", sqlparser.String(e))
+					continue
+				}
+				for _, c := range src.ddl.TableSpec.Columns {
+					columns = append(columns, viewColumn(c.Name.String(), c))
+				}
+			}
+		case *sqlparser.AliasedExpr:
+			for {
+				// pg_dump parenthesizes the cast columns, (users.email)::text
+				p, ok := e.Expr.(*sqlparser.ParenExpr)
+				if !ok {
+					break
+				}
+				e.Expr = p.Expr
+			}
+			col, isCol := e.Expr.(*sqlparser.ColName)
+			column := e.As.String()
+			if column == "" {
+				if !isCol {
+					warn("skip %s: expressions need an alias", sqlparser.String(e.Expr))
+					continue
+				}
+				column = col.Name.String()
+			}
+			if !isCol {
+				untyped(column, sqlparser.String(e.Expr))
+				continue
+			}
+			var found []*sqlparser.ColumnDefinition
+			for _, src := range sources {
+				if q := col.Qualifier.Name.String(); q != "" && src.alias != q || src.ddl == nil {
+					continue
+				}
+				if c := findColumn(src.ddl, col.Name.String()); c != nil {
+					found = append(found, c)
+				}
+			}
+			if len(found) != 1 {
+				desc := sqlparser.String(col)
+				if len(found) > 1 {
+					desc = "ambiguous " + desc
+				}
+				untyped(column, desc)
+				continue
+			}
+			columns = append(columns, viewColumn(column, found[0]))
+		}
+	}
+	if declared != nil {
+		if len(declared) != len(columns) {
+			return nil, fmt.Errorf("view %s: %d column names for %d columns", name, len(declared), len(columns))
+		}
+		for i, c := range columns {
+			c.Name = sqlparser.NewColIdent(declared[i])
+		}
+	}
+	return &sqlparser.DDL{
+		Action:    sqlparser.CreateStr,
+		NewName:   sqlparser.TableName{Name: sqlparser.NewTableIdent(name)},
+		TableSpec: &sqlparser.TableSpec{Columns: columns},
+	}, nil
+}
+
+// parsePgView derives a struct from a Postgres or SQLite CREATE VIEW
+// statement, read up to VIEW, as parseView does for MySQL. The SELECT is
+// handed to the MySQL parser with its quoted identifiers in backticks and
+// its casts dropped, the cast columns keeping the type of their table.
+func parsePgView(ddls []*sqlparser.DDL, p *pgParser) (*sqlparser.DDL, error) {
+	p.accept("if", "not", "exists")
+	_, name, err := p.qualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	var columns []string
+	if p.peek().is("(") {
+		if columns, err = p.columnList(); err != nil {
+			return nil, fmt.Errorf("view %s: %v", name, err)
+		}
+	}
+	if p.accept("with") {
+		// the view options, e.g. WITH (security_barrier)
+		p.skipGroup()
+	}
+	if err := p.expect("as"); err != nil {
+		return nil, fmt.Errorf("view %s: %v", name, err)
+	}
+	tokens := p.tokens[p.pos:]
+	if n := len(tokens); n > 2 && tokens[n-2].is("check") && tokens[n-1].is("option") {
+		// WITH [CASCADED | LOCAL] CHECK OPTION
+		for n -= 2; n > 0 && !tokens[n].is("with"); n-- {
+		}
+		tokens = tokens[:n]
+	}
+	q := &pgParser{tokens: tokens}
+	var b strings.Builder
+	prev := pgToken{}
+	for !q.done() {
+		t := q.next()
+		if t.is("::") {
+			typ := q.next()
+			if q.accept(".") {
+				typ = q.next()
+			}
+			typName := pgTypeName(q, typ)
+			if q.peek().is("(") {
+				q.skipGroup()
+			}
+			pgTimeZone(q, typName)
+			for q.accept("[") {
+				for !q.done() && !q.next().is("]") {
+				}
+			}
+			continue
+		}
+		if b.Len() > 0 && !t.is(".") && !prev.is(".") {
+			b.WriteByte(' ')
+		}
+		if t.Kind == pgQuotedIdent {
+			b.WriteString("`" + strings.Replace(t.Text, "`", "``", -1) + "`")
+		} else {
+			b.WriteString(t.String())
+		}
+		prev = t
+	}
+	return parseView(ddls, name, columns, b.String())
+}
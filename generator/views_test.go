@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestViews(t *testing.T) {
+	for _, tt := range []struct {
+		dialect, schema string
+	}{
+		{"mysql", "CREATE TABLE users (id bigint NOT NULL, email varchar(255) NOT NULL, PRIMARY KEY (id));\n" +
+			"CREATE VIEW active_users AS SELECT u.id, u.email, count(*) AS n FROM users u GROUP BY u.id;"},
+		{"postgres", "CREATE TABLE public.users (id bigint NOT NULL, email character varying(255) NOT NULL, CONSTRAINT users_pkey PRIMARY KEY (id));\n" +
+			"CREATE VIEW public.active_users AS\n SELECT users.id,\n    (users.email)::text AS email,\n    count(*) AS n\n   FROM public.users\n  WHERE ((users.email)::text <> ''::text)\n  GROUP BY users.id;"},
+		{"sqlite", "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL);\n" +
+			"CREATE VIEW IF NOT EXISTS active_users AS SELECT id, email, count(*) AS n FROM users GROUP BY id;"},
+	} {
+		t.Run(tt.dialect, func(t *testing.T) {
+			got := generate(t, tt.schema, "active_users", "-dialect", tt.dialect, "-views")
+			for _, want := range []string{
+				"type ActiveUsers struct {",
+				"ID    int64 ",
+				"Email string ",
+				"N     interface{} ",
+			} {
+				if !strings.Contains(got, want) {
+					t.Errorf("active_users.go lacks %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestViewsOff(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", "CREATE TABLE users (id bigint NOT NULL, CONSTRAINT users_pkey PRIMARY KEY (id));\n"+
+		"CREATE VIEW v AS SELECT id FROM users;")
+	if err := run("-dialect", "postgres", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(modelPath("v")); err == nil {
+		t.Error("v.go written without -views")
+	}
+}
+
+func TestViewsSkipNotices(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", "CREATE TABLE users (id bigint NOT NULL, PRIMARY KEY (id));\n"+
+		"CREATE VIEW v AS SELECT id FROM users;")
+	out, notices, err := capture(t, func() error {
+		return run("-views", "-gen-updatemap", "-gen-fixtures", "-gen-bulk", "schema.sql")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"skip update map for v: view", "skip fixtures for v: view", "skip bulk insert for v: view"} {
+		if !strings.Contains(notices, want) {
+			t.Errorf("stderr lacks %q:\n%s", want, notices)
+		}
+	}
+	if strings.Contains(out, "skip") {
+		t.Errorf("skip notices on stdout:\n%s", out)
+	}
+}
@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+const whereTemplate = `
+package {{.Package}}
+
+import (
+{{- range std .Imports}}
+	"{{.}}"
+{{- end}}
+{{range thirdParty .Imports}}
+	"{{.}}"
+{{- end}}
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+{{range .Columns}}
+// {{$.TableName}}Where{{.Field}} filters db on {{$.TableNameStr}}.{{.Name}} = v.
+func {{$.TableName}}Where{{.Field}}(db *gorm.DB, v {{.Type}}) *gorm.DB {
+	return db.Where(clause.Eq{Column: clause.Column{Name: {{printf "%q" .Name}}}, Value: v})
+}
+{{end}}`
+
+func genWhere(pkg string, ddl *sqlparser.DDL) string {
+	tableNameStr := ddl.NewName.Name.String()
+
+	var cols []dalColumn
+	var types []string
+	for _, c := range ddl.TableSpec.Columns {
+		col := newDALColumn(c)
+		cols = append(cols, col)
+		types = append(types, col.Type)
+	}
+
+	params := struct {
+		Package      string
+		TableName    string
+		TableNameStr string
+		Imports      []string
+		Columns      []dalColumn
+	}{
+		Package:      pkg,
+		TableName:    modelName(tableNameStr),
+		TableNameStr: tableNameStr,
+		Imports:      importsOf(types...),
+		Columns:      cols,
+	}
+
+	var buf bytes.Buffer
+	_ = template.Must(template.New("where").Funcs(importFuncs).Parse(whereTemplate)).Execute(&buf, params)
+	return buf.String()
+}
@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const whereTest = `package model
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestWhere(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Users{}); err != nil {
+		t.Fatal(err)
+	}
+	born := time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+	for _, u := range []Users{{Email: "a@example.com", BornAt: born}, {Email: "b@example.com"}} {
+		if err := db.Create(&u).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got []Users
+	if err := UsersWhereID(db, 2).Find(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Email != "b@example.com" {
+		t.Errorf("got %+v, want b", got)
+	}
+	// the filters compose
+	got = nil
+	if err := UsersWhereBornAt(UsersWhereEmail(db, "a@example.com"), born).Find(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("got %+v, want a", got)
+	}
+	got = nil
+	if err := UsersWhereEmail(UsersWhereID(db, 2), "a@example.com").Find(&got).Error; err != nil || len(got) != 0 {
+		t.Errorf("got %+v, %v, want no row", got, err)
+	}
+}
+`
+
+func TestWhereHelpers(t *testing.T) {
+	chdir(t)
+	writeFile(t, "schema.sql", `CREATE TABLE users (
+  id bigint NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL,
+  born_at datetime NOT NULL,
+  PRIMARY KEY (id)
+);`)
+	if err := run("-gen-where", "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	where := gofmt(t, readFile(t, "model/users_where.go"))
+	for _, want := range []string{
+		"func UsersWhereID(db *gorm.DB, v int64) *gorm.DB {\n" +
+			"\treturn db.Where(clause.Eq{Column: clause.Column{Name: \"id\"}, Value: v})\n}",
+		"func UsersWhereEmail(db *gorm.DB, v string) *gorm.DB {",
+		"func UsersWhereBornAt(db *gorm.DB, v time.Time) *gorm.DB {",
+	} {
+		if !strings.Contains(where, want) {
+			t.Errorf("users_where.go lacks %s:\n%s", want, where)
+		}
+	}
+	writeFile(t, "model/where_test.go", whereTest)
+	goTest(t, "./model")
+}
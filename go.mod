@@ -2,4 +2,9 @@ module github.com/web3-luoxi/dalgen
 
 go 1.17
 
-require github.com/xwb1989/sqlparser v0.0.0-20180606152119-120387863bf2
+require (
+	github.com/go-sql-driver/mysql v1.7.1
+	github.com/lib/pq v1.10.9
+	github.com/xwb1989/sqlparser v0.0.0-20180606152119-120387863bf2
+	gopkg.in/yaml.v3 v3.0.1
+)